@@ -0,0 +1,246 @@
+// Package apicache caches the responses of read-only Uptrace API calls.
+//
+// The cache wraps runtime.APIClient, so it sits below every uptraceapi.Client
+// method: GET requests of known operations are cached by their normalized URL,
+// identical concurrent requests share one round trip, and successful mutations
+// drop the cached responses of the collection they change.
+package apicache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uptrace/oapi-codegen-dd/v3/pkg/runtime"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/uptrace/mcp/appconf"
+)
+
+// ingestDelay is how far time_end must be in the past before a query is treated
+// as historical; spans and metrics arriving late can still change recent data.
+const ingestDelay = 5 * time.Minute
+
+// operations names the cacheable GET operations by their path template. The
+// names are used in cache.operations.
+var operations = map[string]string{
+	"/api/v1/tracing/{project_id}/spans":                                    "public_list_spans",
+	"/api/v1/tracing/{project_id}/groups":                                   "public_list_span_groups",
+	"/internal/v1/tracing/{project_id}/spans":                               "list_spans",
+	"/internal/v1/tracing/{project_id}/groups":                              "list_span_groups",
+	"/internal/v1/tracing/{project_id}/timeseries":                          "query_timeseries",
+	"/internal/v1/tracing/{project_id}/quantiles":                           "query_quantiles",
+	"/internal/v1/tracing/{project_id}/trace-groups":                        "list_trace_groups",
+	"/internal/v1/tracing/{project_id}/traces":                              "list_traces",
+	"/internal/v1/projects/{project_id}/monitors":                           "list_monitors",
+	"/internal/v1/projects/{project_id}/monitors/{monitor_id}":              "get_monitor",
+	"/internal/v1/projects/{project_id}/dashboards":                         "list_dashboards",
+	"/internal/v1/projects/{project_id}/dashboards/tags":                    "list_dashboard_tags",
+	"/internal/v1/projects/{project_id}/dashboards/{dashboard_id}":          "get_dashboard",
+	"/internal/v1/projects/{project_id}/dashboards/{dashboard_id}/yaml":     "get_dashboard_yaml",
+	"/internal/v1/metrics/{project_id}/dashboards/templates":                "list_dashboard_templates",
+	"/internal/v1/metrics/{project_id}/dashboards/templates/{template_id}":  "get_dashboard_template",
+	"/internal/v1/metrics/{project_id}/explore":                             "explore_metrics",
+	"/internal/v1/metrics/{project_id}/attributes":                          "list_metric_attributes",
+	"/internal/v1/metrics/{project_id}/attributes/{attr_key}":               "list_metric_attribute_values",
+	"/internal/v1/projects/{project_id}/notification-channels":              "list_notification_channels",
+	"/internal/v1/projects/{project_id}/notification-channels/{channel_id}": "get_notification_channel",
+}
+
+// defaultTTLs are used for operations missing from cache.operations. Monitor
+// statuses change all the time and are polled for subscriptions, so monitors
+// are not cached unless configured.
+var defaultTTLs = map[string]time.Duration{
+	"list_monitors": 0,
+	"get_monitor":   0,
+}
+
+// Client is a runtime.APIClient that caches the responses of next.
+type Client struct {
+	next   runtime.APIClient
+	conf   *appconf.CacheConfig
+	logger *slog.Logger
+	group  singleflight.Group
+
+	mu  sync.Mutex
+	lru *lru
+	// gen is incremented on every invalidation, so that responses fetched
+	// before a mutation are neither stored nor shared with later requests.
+	gen uint64
+}
+
+var _ runtime.APIClient = (*Client)(nil)
+
+func New(next runtime.APIClient, conf *appconf.Config, logger *slog.Logger) (*Client, error) {
+	known := make(map[string]bool, len(operations))
+	for _, op := range operations {
+		known[op] = true
+	}
+	for op := range conf.Cache.Operations {
+		if !known[op] {
+			return nil, fmt.Errorf("cache.operations: unknown operation %q", op)
+		}
+	}
+
+	return &Client{
+		next:   next,
+		conf:   &conf.Cache,
+		logger: logger,
+		lru:    newLRU(conf.Cache.MaxEntries),
+	}, nil
+}
+
+func (c *Client) GetBaseURL() string {
+	return c.next.GetBaseURL()
+}
+
+func (c *Client) CreateRequest(
+	ctx context.Context, params runtime.RequestOptionsParameters, reqEditors ...runtime.RequestEditorFn,
+) (*http.Request, error) {
+	return c.next.CreateRequest(ctx, params, reqEditors...)
+}
+
+func (c *Client) ExecuteRequest(
+	ctx context.Context, req *http.Request, operationPath string,
+) (*runtime.Response, error) {
+	if req.Method != http.MethodGet {
+		resp, err := c.next.ExecuteRequest(ctx, req, operationPath)
+		if err == nil && resp != nil && resp.StatusCode < 300 {
+			c.invalidate(req.URL.Path)
+		}
+		return resp, err
+	}
+
+	op, ok := operations[operationPath]
+	if !ok {
+		return c.next.ExecuteRequest(ctx, req, operationPath)
+	}
+	now := time.Now()
+	key, ttl := c.key(op, req.URL, now)
+	if ttl <= 0 {
+		return c.next.ExecuteRequest(ctx, req, operationPath)
+	}
+
+	c.mu.Lock()
+	e, ok := c.lru.get(key, now)
+	gen := c.gen
+	c.mu.Unlock()
+	if ok {
+		c.logger.Debug("uptrace cache hit", slog.String("op", op), slog.String("key", key))
+		return copyResponse(e.resp), nil
+	}
+
+	v, err, shared := c.group.Do(strconv.FormatUint(gen, 10)+" "+key, func() (any, error) {
+		resp, err := c.next.ExecuteRequest(ctx, req, operationPath)
+		if err != nil {
+			return nil, err
+		}
+		if resp != nil && resp.StatusCode == http.StatusOK {
+			c.mu.Lock()
+			if c.gen == gen {
+				c.lru.add(&entry{
+					key:     key,
+					path:    req.URL.Path,
+					resp:    resp,
+					expires: time.Now().Add(ttl),
+				})
+			}
+			c.mu.Unlock()
+		}
+		return resp, nil
+	})
+	c.logger.Debug("uptrace cache miss",
+		slog.String("op", op),
+		slog.String("key", key),
+		slog.Bool("shared", shared))
+	if err != nil {
+		// The request that was shared with us was canceled by its own caller.
+		if shared && ctx.Err() == nil &&
+			(errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+			return c.next.ExecuteRequest(ctx, req, operationPath)
+		}
+		return nil, err
+	}
+	resp, _ := v.(*runtime.Response)
+	if resp == nil {
+		return nil, nil
+	}
+	return copyResponse(resp), nil
+}
+
+// key returns the cache key of a request and how long its response is cached.
+// The key is the path with sorted query params. For recent data time_start and
+// time_end are truncated to the TTL, so relative ranges like "last 1h" computed
+// a few seconds apart share an entry.
+func (c *Client) key(op string, u *url.URL, now time.Time) (string, time.Duration) {
+	ttl, ok := c.conf.Operations[op]
+	if !ok {
+		if ttl, ok = defaultTTLs[op]; !ok {
+			ttl = c.conf.TTL
+		}
+	}
+	if ttl <= 0 {
+		return "", 0
+	}
+
+	query := u.Query()
+	if end, ok := parseTime(query.Get("time_end")); ok && end.Before(now.Add(-ingestDelay)) {
+		ttl = max(ttl, c.conf.HistoricalTTL)
+	} else {
+		for _, name := range []string{"time_start", "time_end"} {
+			if tm, ok := parseTime(query.Get(name)); ok {
+				query.Set(name, tm.Truncate(ttl).UTC().Format(time.RFC3339))
+			}
+		}
+	}
+
+	if len(query) == 0 {
+		return u.Path, ttl
+	}
+	return u.Path + "?" + query.Encode(), ttl
+}
+
+// invalidate drops the cached responses related to a mutation of path: the
+// collection under /projects/{id}/ it belongs to, e.g. every dashboard response
+// for /projects/1/dashboards/5/grid, or the whole cache for other paths.
+func (c *Client) invalidate(path string) {
+	prefix := ""
+	if i := strings.Index(path, "/projects/"); i >= 0 {
+		// /projects/{id}/{collection}
+		parts := strings.SplitN(path[i+1:], "/", 4)
+		if len(parts) >= 3 {
+			prefix = path[:i] + "/" + strings.Join(parts[:3], "/")
+		}
+	}
+
+	c.mu.Lock()
+	c.gen++
+	n := c.lru.removePrefix(prefix)
+	c.mu.Unlock()
+
+	c.logger.Debug("uptrace cache invalidated",
+		slog.String("prefix", prefix),
+		slog.Int("entries", n))
+}
+
+func parseTime(s string) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, false
+	}
+	tm, err := time.Parse(time.RFC3339Nano, s)
+	return tm, err == nil
+}
+
+// copyResponse returns a shallow copy of resp. Callers only decode Content, so
+// the body is shared.
+func copyResponse(resp *runtime.Response) *runtime.Response {
+	cp := *resp
+	return &cp
+}
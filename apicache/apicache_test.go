@@ -0,0 +1,182 @@
+package apicache
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/uptrace/oapi-codegen-dd/v3/pkg/runtime"
+
+	"github.com/uptrace/mcp/appconf"
+)
+
+// fakeAPI counts the requests that reach it. Requests block on release when it
+// is set.
+type fakeAPI struct {
+	calls   atomic.Int64
+	release chan struct{}
+}
+
+func (f *fakeAPI) GetBaseURL() string { return "http://uptrace.test" }
+
+func (f *fakeAPI) CreateRequest(
+	ctx context.Context, params runtime.RequestOptionsParameters, reqEditors ...runtime.RequestEditorFn,
+) (*http.Request, error) {
+	panic("not used")
+}
+
+func (f *fakeAPI) ExecuteRequest(
+	ctx context.Context, req *http.Request, operationPath string,
+) (*runtime.Response, error) {
+	f.calls.Add(1)
+	if f.release != nil {
+		<-f.release
+	}
+	return &runtime.Response{StatusCode: http.StatusOK, Content: []byte("{}")}, nil
+}
+
+func newTestClient(t *testing.T, api *fakeAPI) *Client {
+	t.Helper()
+	conf := &appconf.Config{Cache: appconf.CacheConfig{
+		MaxEntries:    100,
+		TTL:           time.Minute,
+		HistoricalTTL: time.Hour,
+	}}
+	c, err := New(api, conf, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func get(t *testing.T, c *Client, path, operationPath string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, "http://uptrace.test"+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ExecuteRequest(context.Background(), req, operationPath); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyTruncatesRecentTimes(t *testing.T) {
+	c := newTestClient(t, &fakeAPI{})
+	now := time.Date(2026, 1, 2, 10, 30, 0, 0, time.UTC)
+
+	keyAt := func(end time.Time) (string, time.Duration) {
+		u, _ := url.Parse("http://uptrace.test/internal/v1/tracing/1/groups")
+		u.RawQuery = url.Values{
+			"query":      {"group by service_name"},
+			"time_start": {end.Add(-time.Hour).Format(time.RFC3339)},
+			"time_end":   {end.Format(time.RFC3339)},
+		}.Encode()
+		return c.key("list_span_groups", u, now)
+	}
+
+	k1, ttl := keyAt(now.Add(-10 * time.Second))
+	if ttl != time.Minute {
+		t.Errorf("ttl = %s, want 1m", ttl)
+	}
+	k2, _ := keyAt(now.Add(-20 * time.Second))
+	if k1 != k2 {
+		t.Errorf("ranges a few seconds apart got different keys:\n%s\n%s", k1, k2)
+	}
+	k3, _ := keyAt(now.Add(-90 * time.Second))
+	if k1 == k3 {
+		t.Errorf("ranges in different minutes got the same key %s", k1)
+	}
+
+	// Historical ranges keep their exact times and use the historical TTL.
+	h1, ttl := keyAt(now.Add(-time.Hour))
+	if ttl != time.Hour {
+		t.Errorf("historical ttl = %s, want 1h", ttl)
+	}
+	h2, _ := keyAt(now.Add(-time.Hour - 10*time.Second))
+	if h1 == h2 {
+		t.Errorf("historical ranges got the same key %s", h1)
+	}
+}
+
+func TestInvalidateCollection(t *testing.T) {
+	api := &fakeAPI{}
+	c := newTestClient(t, api)
+
+	const (
+		dashboardsPath = "/internal/v1/projects/{project_id}/dashboards"
+		dashboardPath  = "/internal/v1/projects/{project_id}/dashboards/{dashboard_id}"
+		channelsPath   = "/internal/v1/projects/{project_id}/notification-channels"
+	)
+	get(t, c, "/internal/v1/projects/1/dashboards", dashboardsPath)
+	get(t, c, "/internal/v1/projects/1/dashboards/5", dashboardPath)
+	get(t, c, "/internal/v1/projects/1/notification-channels", channelsPath)
+	if n := api.calls.Load(); n != 3 {
+		t.Fatalf("got %d calls, want 3", n)
+	}
+
+	get(t, c, "/internal/v1/projects/1/dashboards", dashboardsPath)
+	if n := api.calls.Load(); n != 3 {
+		t.Fatalf("cached GET made a call: got %d calls, want 3", n)
+	}
+
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+		req, err := http.NewRequest(method, "http://uptrace.test/internal/v1/projects/1/dashboards/5/grid", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := api.calls.Load()
+		if _, err := c.ExecuteRequest(context.Background(), req, "/internal/v1/projects/{project_id}/dashboards/{dashboard_id}/grid"); err != nil {
+			t.Fatal(err)
+		}
+
+		get(t, c, "/internal/v1/projects/1/dashboards", dashboardsPath)
+		get(t, c, "/internal/v1/projects/1/dashboards/5", dashboardPath)
+		if n := api.calls.Load() - before; n != 3 {
+			t.Errorf("%s: got %d calls after the mutation, want 3", method, n)
+		}
+
+		// Other collections stay cached.
+		before = api.calls.Load()
+		get(t, c, "/internal/v1/projects/1/notification-channels", channelsPath)
+		if n := api.calls.Load() - before; n != 0 {
+			t.Errorf("%s: notification channels were invalidated", method)
+		}
+	}
+}
+
+func TestSingleflight(t *testing.T) {
+	api := &fakeAPI{release: make(chan struct{})}
+	c := newTestClient(t, api)
+
+	const n = 10
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, "http://uptrace.test/internal/v1/metrics/1/explore", nil)
+			if _, err := c.ExecuteRequest(context.Background(), req, "/internal/v1/metrics/{project_id}/explore"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	// Let the goroutines queue up behind the first request.
+	deadline := time.Now().Add(time.Second)
+	for api.calls.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(api.release)
+	wg.Wait()
+
+	if got := api.calls.Load(); got != 1 {
+		t.Errorf("got %d calls for %d concurrent requests, want 1", got, n)
+	}
+}
@@ -0,0 +1,78 @@
+package apicache
+
+import (
+	"container/list"
+	"strings"
+	"time"
+
+	"github.com/uptrace/oapi-codegen-dd/v3/pkg/runtime"
+)
+
+type entry struct {
+	key     string
+	path    string
+	resp    *runtime.Response
+	expires time.Time
+}
+
+// lru is a size-bounded map of cached responses that evicts the least recently
+// used entry first. It is not safe for concurrent use.
+type lru struct {
+	max   int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+func newLRU(max int) *lru {
+	return &lru{
+		max:   max,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *lru) get(key string, now time.Time) (*entry, bool) {
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*entry)
+	if now.After(e.expires) {
+		c.remove(el)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return e, true
+}
+
+func (c *lru) add(e *entry) {
+	if el, ok := c.items[e.key]; ok {
+		el.Value = e
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[e.key] = c.ll.PushFront(e)
+	for c.ll.Len() > c.max {
+		c.remove(c.ll.Back())
+	}
+}
+
+// removePrefix removes the entries whose URL path starts with prefix and
+// returns how many were removed.
+func (c *lru) removePrefix(prefix string) int {
+	var n int
+	for el := c.ll.Front(); el != nil; {
+		next := el.Next()
+		if strings.HasPrefix(el.Value.(*entry).path, prefix) {
+			c.remove(el)
+			n++
+		}
+		el = next
+	}
+	return n
+}
+
+func (c *lru) remove(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*entry).key)
+}
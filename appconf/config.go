@@ -9,10 +9,15 @@ import (
 )
 
 type Config struct {
-	Service ServiceConfig `yaml:"service"`
-	Logging LoggingConfig `yaml:"logging"`
-	Default DefaultConfig `yaml:"default"`
-	Uptrace UptraceConfig `yaml:"uptrace"`
+	Service       ServiceConfig       `yaml:"service"`
+	Logging       LoggingConfig       `yaml:"logging"`
+	Default       DefaultConfig       `yaml:"default"`
+	Uptrace       UptraceConfig       `yaml:"uptrace"`
+	SLO           SLOConfig           `yaml:"slo"`
+	Prompts       PromptsConfig       `yaml:"prompts"`
+	Subscriptions SubscriptionsConfig `yaml:"subscriptions"`
+	Cache         CacheConfig         `yaml:"cache"`
+	Output        OutputConfig        `yaml:"output"`
 }
 type DefaultConfig struct {
 	Limit        int           `yaml:"limit"`
@@ -29,6 +34,44 @@ type LoggingConfig struct {
 	MaxBodySize int    `yaml:"max_body_size"`
 }
 
+type SLOConfig struct {
+	File string `yaml:"file"`
+}
+
+type PromptsConfig struct {
+	Dir string `yaml:"dir"`
+}
+
+// SubscriptionsConfig configures resources/subscribe for monitor resources.
+type SubscriptionsConfig struct {
+	// PollInterval is how often monitor statuses are fetched while there are
+	// subscribers.
+	PollInterval time.Duration `yaml:"poll_interval"`
+	// MaxSubscriptions limits the number of active subscriptions. The watched
+	// project is uptrace.project_id, so they all belong to it.
+	MaxSubscriptions int `yaml:"max_subscriptions"`
+}
+
+// CacheConfig configures the in-memory cache of read-only Uptrace API calls.
+type CacheConfig struct {
+	Disabled   bool `yaml:"disabled"`
+	MaxEntries int  `yaml:"max_entries"`
+	// TTL is used for operations that are not listed in Operations.
+	TTL time.Duration `yaml:"ttl"`
+	// HistoricalTTL is used for queries whose time_end is in the past, because
+	// their results no longer change.
+	HistoricalTTL time.Duration `yaml:"historical_ttl"`
+	// Operations overrides the TTL per operation, e.g. explore_metrics: 5m. A
+	// zero TTL disables caching of the operation.
+	Operations map[string]time.Duration `yaml:"operations"`
+}
+
+type OutputConfig struct {
+	Format      string `yaml:"format"`
+	MaxSize     int    `yaml:"max_size"`
+	MaxValueLen int    `yaml:"max_value_len"`
+}
+
 type UptraceConfig struct {
 	DSN       string `yaml:"dsn"`
 	APIURL    string `yaml:"api_url"`
@@ -70,4 +113,28 @@ func (c *Config) setDefaults() {
 	if c.Default.TimeDuration == 0 {
 		c.Default.TimeDuration = time.Hour
 	}
+	if c.Output.Format == "" {
+		c.Output.Format = "json"
+	}
+	if c.Output.MaxSize == 0 {
+		c.Output.MaxSize = 32 << 10
+	}
+	if c.Output.MaxValueLen == 0 {
+		c.Output.MaxValueLen = 200
+	}
+	if c.Subscriptions.PollInterval == 0 {
+		c.Subscriptions.PollInterval = 30 * time.Second
+	}
+	if c.Subscriptions.MaxSubscriptions == 0 {
+		c.Subscriptions.MaxSubscriptions = 100
+	}
+	if c.Cache.MaxEntries == 0 {
+		c.Cache.MaxEntries = 1000
+	}
+	if c.Cache.TTL == 0 {
+		c.Cache.TTL = time.Minute
+	}
+	if c.Cache.HistoricalTTL == 0 {
+		c.Cache.HistoricalTTL = time.Hour
+	}
 }
@@ -0,0 +1,83 @@
+package appconf
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// SLO describes a service level objective over spans.
+//
+// The SLI is the ratio of good events to total events. Total events are selected
+// by System and TotalQuery; good events additionally match GoodQuery and/or are
+// faster than LatencyThreshold. Objective is the target percentage of good
+// events, e.g. 99.9; fractions such as 0.999 are not converted.
+type SLO struct {
+	Name             string        `yaml:"name" json:"name"`
+	Description      string        `yaml:"description" json:"description,omitempty"`
+	System           []string      `yaml:"system" json:"system,omitempty"`
+	TotalQuery       string        `yaml:"total_query" json:"total_query,omitempty"`
+	GoodQuery        string        `yaml:"good_query" json:"good_query,omitempty"`
+	LatencyThreshold time.Duration `yaml:"latency_threshold" json:"latency_threshold,omitempty"`
+	Objective        float64       `yaml:"objective" json:"objective"`
+	Window           time.Duration `yaml:"window" json:"window,omitempty"`
+}
+
+type sloFile struct {
+	SLOs []SLO `yaml:"slos"`
+}
+
+// LoadSLOs reads SLO definitions from a YAML file at the given path.
+func LoadSLOs(path string) ([]SLO, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read SLO file: %w", err)
+	}
+
+	var file sloFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse SLO file: %w", err)
+	}
+
+	seen := make(map[string]bool, len(file.SLOs))
+	for i := range file.SLOs {
+		slo := &file.SLOs[i]
+		if slo.Name == "" {
+			return nil, fmt.Errorf("SLO #%d: name is required", i+1)
+		}
+		if seen[slo.Name] {
+			return nil, fmt.Errorf("SLO %q: duplicate name", slo.Name)
+		}
+		seen[slo.Name] = true
+
+		slo.SetDefaults()
+		if err := slo.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return file.SLOs, nil
+}
+
+// SetDefaults fills in the default window.
+func (s *SLO) SetDefaults() {
+	if s.Window == 0 {
+		s.Window = 30 * 24 * time.Hour
+	}
+}
+
+// Validate checks that the SLO can be evaluated.
+func (s *SLO) Validate() error {
+	if s.Objective <= 0 || s.Objective >= 100 {
+		return fmt.Errorf("SLO %q: objective is a percentage and must be between 0 and 100, got %v",
+			s.Name, s.Objective)
+	}
+	if s.GoodQuery == "" && s.LatencyThreshold == 0 {
+		return fmt.Errorf("SLO %q: either good_query or latency_threshold is required", s.Name)
+	}
+	if s.Window <= 0 {
+		return fmt.Errorf("SLO %q: window must be positive", s.Name)
+	}
+	return nil
+}
@@ -3,6 +3,9 @@ package bootstrap
 import (
 	"context"
 	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/uptrace/mcp/appconf"
 	"github.com/urfave/cli/v3"
@@ -41,13 +44,47 @@ func New(ctx context.Context, conf *appconf.Config, options ...fx.Option) *fx.Ap
 	)
 }
 
-func Run(ctx context.Context, cmd *cli.Command, options ...fx.Option) error {
+// Run runs a long-running command such as the MCP server like RunCommand, until
+// run returns or the process receives SIGINT or SIGTERM.
+func Run(
+	ctx context.Context, cmd *cli.Command, run func(ctx context.Context) error, options ...fx.Option,
+) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := RunCommand(ctx, cmd, run, options...); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// RunCommand runs a one-off CLI command. The app is started so that OnStart
+// hooks run, then run is called and the app is stopped again. Commands get their
+// dependencies with fx.Populate.
+func RunCommand(
+	ctx context.Context, cmd *cli.Command, run func(ctx context.Context) error, options ...fx.Option,
+) error {
 	conf, err := appconf.Load(cmd.String("config"))
 	if err != nil {
 		return err
 	}
 
 	app := New(ctx, conf, options...)
-	app.Run()
-	return app.Err()
+	if err := app.Err(); err != nil {
+		return err
+	}
+
+	startCtx, cancel := context.WithTimeout(ctx, app.StartTimeout())
+	defer cancel()
+	if err := app.Start(startCtx); err != nil {
+		return err
+	}
+
+	runErr := run(ctx)
+
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), app.StopTimeout())
+	defer cancel()
+	if err := app.Stop(stopCtx); err != nil && runErr == nil {
+		return err
+	}
+	return runErr
 }
@@ -3,9 +3,15 @@ package bootstrap
 import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/completion"
+	"github.com/uptrace/mcp/resources"
 )
 
-func NewServer(conf *appconf.Config) *mcp.Server {
+func NewServer(
+	conf *appconf.Config,
+	completer *completion.Completer,
+	watcher *resources.MonitorWatcher,
+) *mcp.Server {
 	return mcp.NewServer(
 		&mcp.Implementation{
 			Name:    AppName,
@@ -14,6 +20,9 @@ func NewServer(conf *appconf.Config) *mcp.Server {
 		&mcp.ServerOptions{
 			Instructions: "Uptrace is an open-source observability platform for distributed tracing, metrics, and logs. " +
 				"For comprehensive documentation optimized for LLMs, see https://uptrace.dev/llms.txt",
+			CompletionHandler:  completer.Complete,
+			SubscribeHandler:   watcher.Subscribe,
+			UnsubscribeHandler: watcher.Unsubscribe,
 		},
 	)
 }
@@ -2,8 +2,11 @@ package bootstrap
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 	"net/http"
 
+	"github.com/uptrace/mcp/apicache"
 	"github.com/uptrace/mcp/appconf"
 	"github.com/uptrace/mcp/uptraceapi"
 	"github.com/uptrace/oapi-codegen-dd/v3/pkg/runtime"
@@ -18,9 +21,10 @@ func (c *httpClient) Do(ctx context.Context, req *http.Request) (*http.Response,
 	return c.Client.Do(req.WithContext(ctx))
 }
 
-// NewUptraceClient creates a new Uptrace API client from config.
-func NewUptraceClient(conf *appconf.Config) (*uptraceapi.Client, error) {
-	return uptraceapi.NewDefaultClient(
+// NewUptraceClient creates a new Uptrace API client from config. Unless
+// cache.disabled is set, read-only calls go through apicache.
+func NewUptraceClient(conf *appconf.Config, logger *slog.Logger) (*uptraceapi.Client, error) {
+	apiClient, err := runtime.NewAPIClient(
 		conf.Uptrace.APIURL,
 		runtime.WithHTTPClient(&httpClient{http.DefaultClient}),
 		runtime.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
@@ -28,4 +32,16 @@ func NewUptraceClient(conf *appconf.Config) (*uptraceapi.Client, error) {
 			return nil
 		}),
 	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating API client: %w", err)
+	}
+	if conf.Cache.Disabled {
+		return uptraceapi.NewClient(apiClient), nil
+	}
+
+	cached, err := apicache.New(apiClient, conf, logger)
+	if err != nil {
+		return nil, err
+	}
+	return uptraceapi.NewClient(cached), nil
 }
@@ -0,0 +1,239 @@
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"sort"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// charWidth is the advance of basicfont.Face7x13, which both canvases use to lay out text.
+const charWidth = 7
+
+type point struct {
+	X, Y float64
+}
+
+type textAnchor int
+
+const (
+	anchorStart textAnchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+// canvas is implemented by the PNG and SVG backends.
+type canvas interface {
+	Rect(x, y, w, h float64, fill color.RGBA)
+	Line(points []point, stroke color.RGBA, width float64, dashed bool)
+	Polygon(points []point, fill color.RGBA)
+	// Text draws s with the baseline at y.
+	Text(x, y float64, s string, anchor textAnchor, fill color.RGBA)
+}
+
+func textWidth(s string) float64 {
+	return float64(utf8.RuneCountInString(s) * charWidth)
+}
+
+func anchorOffset(s string, anchor textAnchor) float64 {
+	switch anchor {
+	case anchorMiddle:
+		return -textWidth(s) / 2
+	case anchorEnd:
+		return -textWidth(s)
+	default:
+		return 0
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type pngCanvas struct {
+	img *image.RGBA
+}
+
+var _ canvas = (*pngCanvas)(nil)
+
+func newPNGCanvas(width, height int) *pngCanvas {
+	return &pngCanvas{img: image.NewRGBA(image.Rect(0, 0, width, height))}
+}
+
+func (c *pngCanvas) encode() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := enc.Encode(&buf, c.img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// blend composites a color over the pixel at x, y.
+func (c *pngCanvas) blend(x, y int, col color.RGBA) {
+	if !(image.Point{x, y}.In(c.img.Rect)) {
+		return
+	}
+	if col.A == 255 {
+		c.img.SetRGBA(x, y, col)
+		return
+	}
+	dst := c.img.RGBAAt(x, y)
+	a := float64(col.A) / 255
+	mix := func(s, d uint8) uint8 {
+		return uint8(float64(s)*a + float64(d)*(1-a))
+	}
+	c.img.SetRGBA(x, y, color.RGBA{mix(col.R, dst.R), mix(col.G, dst.G), mix(col.B, dst.B), 255})
+}
+
+func (c *pngCanvas) Rect(x, y, w, h float64, fill color.RGBA) {
+	x0, y0 := int(math.Round(x)), int(math.Round(y))
+	x1, y1 := int(math.Round(x+w)), int(math.Round(y+h))
+	for py := y0; py < y1; py++ {
+		for px := x0; px < x1; px++ {
+			c.blend(px, py, fill)
+		}
+	}
+}
+
+func (c *pngCanvas) Line(points []point, stroke color.RGBA, width float64, dashed bool) {
+	const dash = 4.0
+
+	half := int(math.Max(0, math.Round(width/2-0.5)))
+	var dist float64
+	for i := 1; i < len(points); i++ {
+		p0, p1 := points[i-1], points[i]
+		dx, dy := p1.X-p0.X, p1.Y-p0.Y
+		length := math.Hypot(dx, dy)
+		steps := int(math.Ceil(length * 2))
+		for s := 0; s <= steps; s++ {
+			t := 0.0
+			if steps > 0 {
+				t = float64(s) / float64(steps)
+			}
+			if dashed && int((dist+t*length)/dash)%2 == 1 {
+				continue
+			}
+			x := int(math.Round(p0.X + t*dx))
+			y := int(math.Round(p0.Y + t*dy))
+			for oy := -half; oy <= half; oy++ {
+				for ox := -half; ox <= half; ox++ {
+					c.img.SetRGBA(x+ox, y+oy, stroke)
+				}
+			}
+		}
+		dist += length
+	}
+}
+
+// Polygon fills a polygon using the even-odd rule.
+func (c *pngCanvas) Polygon(points []point, fill color.RGBA) {
+	if len(points) < 3 {
+		return
+	}
+
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		minY = math.Min(minY, p.Y)
+		maxY = math.Max(maxY, p.Y)
+	}
+
+	var xs []float64
+	for py := int(math.Floor(minY)); py <= int(math.Ceil(maxY)); py++ {
+		y := float64(py) + 0.5
+		xs = xs[:0]
+		for i := range points {
+			a, b := points[i], points[(i+1)%len(points)]
+			if (a.Y <= y) == (b.Y <= y) {
+				continue
+			}
+			xs = append(xs, a.X+(y-a.Y)/(b.Y-a.Y)*(b.X-a.X))
+		}
+		sort.Float64s(xs)
+		for i := 0; i+1 < len(xs); i += 2 {
+			for px := int(math.Round(xs[i])); px < int(math.Round(xs[i+1])); px++ {
+				c.blend(px, py, fill)
+			}
+		}
+	}
+}
+
+func (c *pngCanvas) Text(x, y float64, s string, anchor textAnchor, fill color.RGBA) {
+	d := font.Drawer{
+		Dst:  c.img,
+		Src:  image.NewUniform(fill),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(int(math.Round(x+anchorOffset(s, anchor))), int(math.Round(y))),
+	}
+	d.DrawString(s)
+}
+
+//------------------------------------------------------------------------------
+
+type svgCanvas struct {
+	buf bytes.Buffer
+}
+
+var _ canvas = (*svgCanvas)(nil)
+
+func newSVGCanvas(width, height int) *svgCanvas {
+	c := new(svgCanvas)
+	fmt.Fprintf(&c.buf,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" `+
+			`font-family="monospace" font-size="12">`+"\n",
+		width, height, width, height)
+	return c
+}
+
+func (c *svgCanvas) encode() []byte {
+	c.buf.WriteString("</svg>\n")
+	return c.buf.Bytes()
+}
+
+func (c *svgCanvas) Rect(x, y, w, h float64, fill color.RGBA) {
+	fmt.Fprintf(&c.buf, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`+"\n",
+		x, y, w, h, svgColor(fill))
+}
+
+func (c *svgCanvas) Line(points []point, stroke color.RGBA, width float64, dashed bool) {
+	var dash string
+	if dashed {
+		dash = ` stroke-dasharray="4 4"`
+	}
+	fmt.Fprintf(&c.buf, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%.1f"%s/>`+"\n",
+		svgPoints(points), svgColor(stroke), width, dash)
+}
+
+func (c *svgCanvas) Polygon(points []point, fill color.RGBA) {
+	fmt.Fprintf(&c.buf, `<polygon points="%s" fill="%s"/>`+"\n", svgPoints(points), svgColor(fill))
+}
+
+func (c *svgCanvas) Text(x, y float64, s string, anchor textAnchor, fill color.RGBA) {
+	fmt.Fprintf(&c.buf, `<text x="%.1f" y="%.1f" fill="%s">%s</text>`+"\n",
+		x+anchorOffset(s, anchor), y, svgColor(fill), html.EscapeString(s))
+}
+
+func svgColor(c color.RGBA) string {
+	if c.A == 255 {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("rgba(%d,%d,%d,%.2f)", c.R, c.G, c.B, float64(c.A)/255)
+}
+
+func svgPoints(points []point) string {
+	var b bytes.Buffer
+	for i, p := range points {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%.1f,%.1f", p.X, p.Y)
+	}
+	return b.String()
+}
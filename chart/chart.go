@@ -0,0 +1,503 @@
+// Package chart renders timeseries as PNG or SVG images without external
+// dependencies on a browser or a plotting service.
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"time"
+)
+
+type Kind string
+
+const (
+	Line        Kind = "line"
+	StackedArea Kind = "stacked_area"
+	Heatmap     Kind = "heatmap"
+)
+
+// Chart describes a chart of one or more series sharing the same time axis.
+type Chart struct {
+	Kind        Kind
+	Title       string
+	Unit        string
+	Times       []time.Time
+	Series      []Series
+	Annotations []Annotation
+	Width       int
+	Height      int
+}
+
+// Series is a named list of values aligned with Chart.Times. Missing values are NaN.
+type Series struct {
+	Name   string
+	Values []float64
+}
+
+// Annotation is a vertical marker at the given time.
+type Annotation struct {
+	Time  time.Time
+	Label string
+}
+
+// PNG renders the chart as a PNG image.
+func (c *Chart) PNG() ([]byte, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	cv := newPNGCanvas(c.Width, c.Height)
+	c.draw(cv)
+	return cv.encode()
+}
+
+// SVG renders the chart as an SVG document.
+func (c *Chart) SVG() ([]byte, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	cv := newSVGCanvas(c.Width, c.Height)
+	c.draw(cv)
+	return cv.encode(), nil
+}
+
+func (c *Chart) validate() error {
+	if c.Width == 0 {
+		c.Width = 800
+	}
+	if c.Height == 0 {
+		c.Height = 400
+	}
+	if c.Width < 200 || c.Height < 150 || c.Width > 4000 || c.Height > 4000 {
+		return fmt.Errorf("chart size must be between 200x150 and 4000x4000, got %dx%d",
+			c.Width, c.Height)
+	}
+	switch c.Kind {
+	case "":
+		c.Kind = Line
+	case Line, StackedArea, Heatmap:
+	default:
+		return fmt.Errorf("unknown chart kind %q (supported: line, stacked_area, heatmap)", c.Kind)
+	}
+	if len(c.Times) == 0 || len(c.Series) == 0 {
+		return errors.New("chart has no data")
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+const (
+	padding    = 10
+	lineHeight = 16
+)
+
+var (
+	colorBackground = color.RGBA{255, 255, 255, 255}
+	colorText       = color.RGBA{33, 33, 33, 255}
+	colorAxis       = color.RGBA{120, 120, 120, 255}
+	colorGrid       = color.RGBA{228, 228, 228, 255}
+	colorAnnotation = color.RGBA{211, 47, 47, 255}
+)
+
+// palette is the Tableau 10 palette.
+var palette = []color.RGBA{
+	{78, 121, 167, 255},
+	{242, 142, 43, 255},
+	{225, 87, 89, 255},
+	{118, 183, 178, 255},
+	{89, 161, 79, 255},
+	{237, 201, 72, 255},
+	{176, 122, 161, 255},
+	{255, 157, 167, 255},
+	{156, 117, 95, 255},
+	{186, 176, 172, 255},
+}
+
+func seriesColor(i int) color.RGBA {
+	return palette[i%len(palette)]
+}
+
+type plotArea struct {
+	left, top, right, bottom float64
+}
+
+func (p plotArea) width() float64  { return p.right - p.left }
+func (p plotArea) height() float64 { return p.bottom - p.top }
+
+func (c *Chart) draw(cv canvas) {
+	cv.Rect(0, 0, float64(c.Width), float64(c.Height), colorBackground)
+
+	area := plotArea{
+		left:   padding,
+		top:    padding,
+		right:  float64(c.Width - padding),
+		bottom: float64(c.Height - padding),
+	}
+
+	if c.Title != "" {
+		cv.Text(float64(c.Width)/2, area.top+11, c.Title, anchorMiddle, colorText)
+		area.top += lineHeight + 4
+	}
+	if len(c.Annotations) > 0 {
+		area.top += lineHeight
+	}
+
+	// Reserve space for the legend and the time axis labels.
+	area.bottom -= c.legendHeight(float64(c.Width-2*padding)) + lineHeight + 4
+
+	if c.Kind == Heatmap {
+		area.left += c.heatmapLabelWidth() + 6
+		c.drawHeatmap(cv, area)
+	} else {
+		axis := c.valueAxis()
+		area.left += axis.labelWidth + 6
+		c.drawLines(cv, area, axis)
+	}
+
+	c.drawTimeAxis(cv, area)
+	c.drawAnnotations(cv, area)
+	c.drawLegend(cv, area.bottom+lineHeight+8, float64(c.Width-2*padding))
+}
+
+// valueAxis is the y axis of line and stacked area charts.
+type valueAxis struct {
+	series     []Series // stacked for stacked area charts
+	ticks      []float64
+	labels     []string
+	labelWidth float64
+}
+
+func (c *Chart) valueAxis() *valueAxis {
+	axis := &valueAxis{series: c.Series}
+	if c.Kind == StackedArea {
+		axis.series = stack(c.Series)
+	}
+
+	lo, hi := 0.0, math.Inf(-1)
+	for _, s := range axis.series {
+		for _, v := range s.Values {
+			if math.IsNaN(v) {
+				continue
+			}
+			lo = math.Min(lo, v)
+			hi = math.Max(hi, v)
+		}
+	}
+	if math.IsInf(hi, -1) {
+		hi = 1
+	}
+
+	axis.ticks = niceTicks(lo, hi, 5)
+	axis.labels = make([]string, len(axis.ticks))
+	for i, tick := range axis.ticks {
+		axis.labels[i] = FormatValue(tick, c.Unit)
+		axis.labelWidth = math.Max(axis.labelWidth, textWidth(axis.labels[i]))
+	}
+	return axis
+}
+
+func (c *Chart) drawLines(cv canvas, area plotArea, axis *valueAxis) {
+	values := axis.series
+	lo, hi := axis.ticks[0], axis.ticks[len(axis.ticks)-1]
+
+	yPos := func(v float64) float64 {
+		return area.bottom - (v-lo)/(hi-lo)*area.height()
+	}
+	for i, tick := range axis.ticks {
+		y := yPos(tick)
+		cv.Line([]point{{area.left, y}, {area.right, y}}, colorGrid, 1, false)
+		cv.Text(area.left-6, y+4, axis.labels[i], anchorEnd, colorText)
+	}
+
+	for i := len(values) - 1; i >= 0; i-- {
+		s := values[i]
+		col := seriesColor(i)
+
+		if c.Kind == StackedArea {
+			var base []float64
+			if i > 0 {
+				base = values[i-1].Values
+			}
+			poly := make([]point, 0, 2*len(s.Values))
+			for j, v := range s.Values {
+				poly = append(poly, point{c.xPos(area, j), yPos(v)})
+			}
+			for j := len(s.Values) - 1; j >= 0; j-- {
+				b := 0.0
+				if base != nil {
+					b = base[j]
+				}
+				poly = append(poly, point{c.xPos(area, j), yPos(b)})
+			}
+			fill := col
+			fill.A = 170
+			cv.Polygon(poly, fill)
+		}
+
+		var segment []point
+		for j, v := range s.Values {
+			if math.IsNaN(v) {
+				if len(segment) > 1 {
+					cv.Line(segment, col, 2, false)
+				}
+				segment = segment[:0]
+				continue
+			}
+			segment = append(segment, point{c.xPos(area, j), yPos(v)})
+		}
+		if len(segment) > 1 {
+			cv.Line(segment, col, 2, false)
+		}
+	}
+
+	cv.Line([]point{{area.left, area.top}, {area.left, area.bottom}, {area.right, area.bottom}},
+		colorAxis, 1, false)
+}
+
+// stack returns cumulative series for stacked area charts. Missing values count as zero.
+func stack(series []Series) []Series {
+	out := make([]Series, len(series))
+	var prev []float64
+	for i, s := range series {
+		values := make([]float64, len(s.Values))
+		for j, v := range s.Values {
+			if math.IsNaN(v) {
+				v = 0
+			}
+			if prev != nil && j < len(prev) {
+				v += prev[j]
+			}
+			values[j] = v
+		}
+		out[i] = Series{Name: s.Name, Values: values}
+		prev = values
+	}
+	return out
+}
+
+const maxHeatmapLabelLen = 24
+
+func (c *Chart) heatmapLabelWidth() float64 {
+	var width float64
+	for _, s := range c.Series {
+		width = math.Max(width, textWidth(truncate(s.Name, maxHeatmapLabelLen)))
+	}
+	return width
+}
+
+func (c *Chart) drawHeatmap(cv canvas, area plotArea) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, s := range c.Series {
+		for _, v := range s.Values {
+			if !math.IsNaN(v) {
+				lo = math.Min(lo, v)
+				hi = math.Max(hi, v)
+			}
+		}
+	}
+	if math.IsInf(lo, 1) {
+		lo, hi = 0, 1
+	}
+
+	rowHeight := area.height() / float64(len(c.Series))
+	cellWidth := area.width() / float64(len(c.Times))
+	labelEvery := int(math.Ceil(lineHeight / rowHeight))
+
+	for i, s := range c.Series {
+		y := area.top + float64(i)*rowHeight
+		for j, v := range s.Values {
+			if j >= len(c.Times) || math.IsNaN(v) {
+				continue
+			}
+			frac := 0.0
+			if hi > lo {
+				frac = (v - lo) / (hi - lo)
+			}
+			cv.Rect(area.left+float64(j)*cellWidth, y, math.Ceil(cellWidth), math.Ceil(rowHeight),
+				heatColor(frac))
+		}
+		if i%labelEvery == 0 {
+			cv.Text(area.left-6, y+rowHeight/2+4, truncate(s.Name, maxHeatmapLabelLen), anchorEnd, colorText)
+		}
+	}
+
+	cv.Line([]point{{area.left, area.top}, {area.left, area.bottom}, {area.right, area.bottom}},
+		colorAxis, 1, false)
+}
+
+// heatColor interpolates between light yellow, orange and dark red.
+func heatColor(frac float64) color.RGBA {
+	stops := []color.RGBA{
+		{255, 247, 188, 255},
+		{254, 153, 41, 255},
+		{153, 52, 4, 255},
+	}
+	frac = math.Max(0, math.Min(1, frac))
+	pos := frac * float64(len(stops)-1)
+	i := int(pos)
+	if i >= len(stops)-1 {
+		return stops[len(stops)-1]
+	}
+	t := pos - float64(i)
+	a, b := stops[i], stops[i+1]
+	return color.RGBA{
+		R: uint8(float64(a.R) + t*(float64(b.R)-float64(a.R))),
+		G: uint8(float64(a.G) + t*(float64(b.G)-float64(a.G))),
+		B: uint8(float64(a.B) + t*(float64(b.B)-float64(a.B))),
+		A: 255,
+	}
+}
+
+// xPos returns the x coordinate of the i-th time. Heatmap cells span the
+// interval that starts at the time, so the axis has one extra slot.
+func (c *Chart) xPos(area plotArea, i int) float64 {
+	n := len(c.Times) - 1
+	if c.Kind == Heatmap {
+		n++
+	}
+	if n <= 0 {
+		return area.left
+	}
+	return area.left + float64(i)/float64(n)*area.width()
+}
+
+func (c *Chart) timeX(area plotArea, tm time.Time) (float64, bool) {
+	start := c.Times[0]
+	end := c.Times[len(c.Times)-1]
+	if c.Kind == Heatmap && len(c.Times) > 1 {
+		end = end.Add(c.Times[1].Sub(c.Times[0]))
+	}
+	if tm.Before(start) || tm.After(end) {
+		return 0, false
+	}
+	if !end.After(start) {
+		return area.left, true
+	}
+	frac := float64(tm.Sub(start)) / float64(end.Sub(start))
+	return area.left + frac*area.width(), true
+}
+
+func (c *Chart) drawTimeAxis(cv canvas, area plotArea) {
+	start := c.Times[0]
+	end := c.Times[len(c.Times)-1]
+	if c.Kind == Heatmap && len(c.Times) > 1 {
+		end = end.Add(c.Times[1].Sub(c.Times[0]))
+	}
+	span := end.Sub(start)
+
+	maxTicks := int(area.width() / 90)
+	step := timeStep(span, maxTicks)
+	layout := timeLayout(span)
+
+	for tm := start.UTC().Truncate(step); !tm.After(end); tm = tm.Add(step) {
+		x, ok := c.timeX(area, tm)
+		if !ok {
+			continue
+		}
+		cv.Line([]point{{x, area.bottom}, {x, area.bottom + 4}}, colorAxis, 1, false)
+
+		label := tm.Format(layout)
+		if x+textWidth(label)/2 > float64(c.Width) {
+			continue
+		}
+		cv.Text(x, area.bottom+lineHeight+2, label, anchorMiddle, colorText)
+	}
+}
+
+func (c *Chart) drawAnnotations(cv canvas, area plotArea) {
+	for _, ann := range c.Annotations {
+		x, ok := c.timeX(area, ann.Time)
+		if !ok {
+			continue
+		}
+		cv.Line([]point{{x, area.top}, {x, area.bottom}}, colorAnnotation, 1, true)
+		if ann.Label != "" {
+			maxLen := int((area.right - x) / charWidth)
+			cv.Text(x+2, area.top-4, truncate(ann.Label, max(maxLen, 8)), anchorStart, colorAnnotation)
+		}
+	}
+}
+
+func (c *Chart) legendHeight(width float64) float64 {
+	if c.Kind == Heatmap {
+		return lineHeight
+	}
+	return float64(len(c.legendRows(width))) * lineHeight
+}
+
+// legendRows wraps legend entries into rows that fit the width.
+func (c *Chart) legendRows(width float64) [][]int {
+	var rows [][]int
+	var row []int
+	var x float64
+	for i, s := range c.Series {
+		w := 16 + textWidth(truncate(s.Name, 48)) + 12
+		if len(row) > 0 && x+w > width {
+			rows = append(rows, row)
+			row, x = nil, 0
+		}
+		row = append(row, i)
+		x += w
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (c *Chart) drawLegend(cv canvas, top, width float64) {
+	if c.Kind == Heatmap {
+		c.drawColorScale(cv, top, width)
+		return
+	}
+
+	for r, row := range c.legendRows(width) {
+		x := float64(padding)
+		y := top + float64(r)*lineHeight
+		for _, i := range row {
+			name := truncate(c.Series[i].Name, 48)
+			cv.Rect(x, y+2, 10, 10, seriesColor(i))
+			cv.Text(x+14, y+11, name, anchorStart, colorText)
+			x += 16 + textWidth(name) + 12
+		}
+	}
+}
+
+func (c *Chart) drawColorScale(cv canvas, top, width float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, s := range c.Series {
+		for _, v := range s.Values {
+			if !math.IsNaN(v) {
+				lo = math.Min(lo, v)
+				hi = math.Max(hi, v)
+			}
+		}
+	}
+	if math.IsInf(lo, 1) {
+		return
+	}
+
+	loLabel := FormatValue(lo, c.Unit)
+	hiLabel := FormatValue(hi, c.Unit)
+	x := float64(padding)
+	cv.Text(x, top+11, loLabel, anchorStart, colorText)
+	x += textWidth(loLabel) + 6
+
+	const steps = 40
+	barWidth := math.Min(200, width/3)
+	for i := range steps {
+		cv.Rect(x+float64(i)*barWidth/steps, top+2, math.Ceil(barWidth/steps), 10,
+			heatColor(float64(i)/float64(steps-1)))
+	}
+	cv.Text(x+barWidth+6, top+11, hiLabel, anchorStart, colorText)
+}
+
+func truncate(s string, maxLen int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	return string(runes[:maxLen-1]) + "…"
+}
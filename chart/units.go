@@ -0,0 +1,174 @@
+package chart
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatValue formats v for display using the Uptrace column unit, e.g.
+// milliseconds, bytes or percents. Unknown units are appended verbatim.
+func FormatValue(v float64, unit string) string {
+	if math.IsNaN(v) {
+		return "-"
+	}
+
+	switch unit {
+	case "":
+		return formatSI(v)
+	case "nanoseconds":
+		return formatDuration(v)
+	case "microseconds":
+		return formatDuration(v * 1e3)
+	case "milliseconds":
+		return formatDuration(v * 1e6)
+	case "seconds":
+		return formatDuration(v * 1e9)
+	case "bytes":
+		return formatBytes(v)
+	case "percents":
+		return formatNumber(v) + "%"
+	case "utilization":
+		return formatNumber(v*100) + "%"
+	default:
+		return formatSI(v) + " " + strings.Trim(unit, "{}")
+	}
+}
+
+func formatDuration(ns float64) string {
+	abs := math.Abs(ns)
+	switch {
+	case abs == 0:
+		return "0"
+	case abs < 1e3:
+		return formatNumber(ns) + "ns"
+	case abs < 1e6:
+		return formatNumber(ns/1e3) + "µs"
+	case abs < 1e9:
+		return formatNumber(ns/1e6) + "ms"
+	case abs < 60e9:
+		return formatNumber(ns/1e9) + "s"
+	case abs < 3600e9:
+		return formatNumber(ns/60e9) + "m"
+	default:
+		return formatNumber(ns/3600e9) + "h"
+	}
+}
+
+func formatBytes(v float64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB", "PB"}
+	i := 0
+	for math.Abs(v) >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	return formatNumber(v) + units[i]
+}
+
+func formatSI(v float64) string {
+	abs := math.Abs(v)
+	switch {
+	case abs >= 1e12:
+		return formatNumber(v/1e12) + "T"
+	case abs >= 1e9:
+		return formatNumber(v/1e9) + "G"
+	case abs >= 1e6:
+		return formatNumber(v/1e6) + "M"
+	case abs >= 1e3:
+		return formatNumber(v/1e3) + "k"
+	default:
+		return formatNumber(v)
+	}
+}
+
+// formatNumber keeps about 3 significant digits.
+func formatNumber(v float64) string {
+	abs := math.Abs(v)
+	prec := 2
+	switch {
+	case abs >= 100:
+		prec = 0
+	case abs >= 10:
+		prec = 1
+	case abs > 0 && abs < 0.01:
+		return strconv.FormatFloat(v, 'g', 2, 64)
+	}
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// niceTicks returns about n evenly spaced round values covering [lo, hi].
+func niceTicks(lo, hi float64, n int) []float64 {
+	if hi <= lo {
+		hi = lo + 1
+	}
+	step := niceNumber((hi - lo) / float64(n))
+	start := math.Floor(lo/step) * step
+	end := math.Ceil(hi/step) * step
+
+	var ticks []float64
+	for v := start; v <= end+step/2; v += step {
+		// Avoid -0 and accumulated floating point error in labels.
+		ticks = append(ticks, math.Round(v/step)*step+0)
+	}
+	return ticks
+}
+
+func niceNumber(v float64) float64 {
+	exp := math.Floor(math.Log10(v))
+	frac := v / math.Pow(10, exp)
+	var nice float64
+	switch {
+	case frac <= 1:
+		nice = 1
+	case frac <= 2:
+		nice = 2
+	case frac <= 5:
+		nice = 5
+	default:
+		nice = 10
+	}
+	return nice * math.Pow(10, exp)
+}
+
+var timeSteps = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	10 * time.Minute,
+	15 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+	3 * time.Hour,
+	6 * time.Hour,
+	12 * time.Hour,
+	24 * time.Hour,
+	2 * 24 * time.Hour,
+	7 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+}
+
+// timeStep picks the smallest step that produces at most maxTicks ticks.
+func timeStep(span time.Duration, maxTicks int) time.Duration {
+	maxTicks = max(maxTicks, 2)
+	for _, step := range timeSteps {
+		if span/step <= time.Duration(maxTicks) {
+			return step
+		}
+	}
+	return timeSteps[len(timeSteps)-1]
+}
+
+func timeLayout(span time.Duration) string {
+	switch {
+	case span <= 24*time.Hour:
+		return "15:04"
+	case span <= 7*24*time.Hour:
+		return "01-02 15:04"
+	default:
+		return "2006-01-02"
+	}
+}
@@ -22,6 +22,8 @@ type OpenAPISpec struct {
 
 type operation struct {
 	ID          string
+	Method      string
+	Path        string
 	Summary     string
 	Description string
 }
@@ -32,14 +34,19 @@ package uptraceapi
 
 // OperationDescription holds metadata for an API operation.
 type OperationDescription struct {
+	Method      string
+	Path        string
 	Summary     string
 	Description string
 }
 
-// Operations maps operationId to its description from the OpenAPI spec.
+// Operations maps operationId to its method, path and description from the
+// OpenAPI spec.
 var Operations = map[string]OperationDescription{
 {{- range .}}
 	{{printf "%q" .ID}}: {
+		Method:      {{printf "%q" .Method}},
+		Path:        {{printf "%q" .Path}},
 		Summary:     {{printf "%q" .Summary}},
 		Description: {{printf "%q" .Description}},
 	},
@@ -61,13 +68,15 @@ func main() {
 	}
 
 	var ops []operation
-	for _, methods := range spec.Paths {
-		for _, op := range methods {
+	for path, methods := range spec.Paths {
+		for method, op := range methods {
 			if op.OperationID == "" {
 				continue
 			}
 			ops = append(ops, operation{
 				ID:          op.OperationID,
+				Method:      strings.ToUpper(method),
+				Path:        path,
 				Summary:     op.Summary,
 				Description: strings.TrimSpace(op.Description),
 			})
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"go.uber.org/fx"
+
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/bootstrap"
+	"github.com/uptrace/mcp/dashboard"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+func dashboardsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "dashboards",
+		Usage: "Manage dashboards as code: export them to a directory of YAML files and apply changes back",
+		Commands: []*cli.Command{
+			{
+				Name:  "export",
+				Usage: "Write every dashboard of the project to <dir>/<key>.yaml",
+				Flags: syncFlags("dashboard"),
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return runSync(ctx, cmd, func(ctx context.Context, syncer *dashboard.Syncer) error {
+						paths, err := syncer.Export(ctx, cmd.String("dir"))
+						if err != nil {
+							return err
+						}
+						for _, path := range paths {
+							fmt.Fprintln(cmd.Root().Writer, path)
+						}
+						fmt.Fprintf(cmd.Root().Writer, "Exported %d dashboard(s) to %s.\n", len(paths), cmd.String("dir"))
+						return nil
+					})
+				},
+			},
+			{
+				Name:  "diff",
+				Usage: "Show what apply would change without changing anything",
+				Flags: append(syncFlags("dashboard"), pruneFlag("dashboard")),
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return runSync(ctx, cmd, func(ctx context.Context, syncer *dashboard.Syncer) error {
+						plan, err := syncer.Plan(ctx, cmd.String("dir"), cmd.Bool("prune"))
+						if err != nil {
+							return err
+						}
+						fmt.Fprint(cmd.Root().Writer, plan)
+						return nil
+					})
+				},
+			},
+			{
+				Name:  "apply",
+				Usage: "Create and update dashboards to match the directory, and delete the rest with --prune",
+				Flags: append(syncFlags("dashboard"), pruneFlag("dashboard"), autoApproveFlag()),
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return runSync(ctx, cmd, func(ctx context.Context, syncer *dashboard.Syncer) error {
+						return applyDashboards(ctx, cmd, syncer)
+					})
+				},
+			},
+		},
+	}
+}
+
+// syncFlags are the flags shared by the export, diff/plan and apply subcommands of
+// kind, e.g. dashboard or monitor.
+func syncFlags(kind string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "dir",
+			Usage: fmt.Sprintf("Directory with %s YAML files", kind),
+			Value: kind + "s",
+		},
+		&cli.Int64Flag{
+			Name:  "project",
+			Usage: "Uptrace project ID (defaults to uptrace.project_id from the config)",
+		},
+	}
+}
+
+func pruneFlag(kind string) cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "prune",
+		Usage: fmt.Sprintf("Delete %ss that have no YAML file in the directory", kind),
+	}
+}
+
+func autoApproveFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "auto-approve",
+		Usage: "Apply the plan without asking for confirmation",
+	}
+}
+
+func runSync(
+	ctx context.Context,
+	cmd *cli.Command,
+	fn func(ctx context.Context, syncer *dashboard.Syncer) error,
+) error {
+	return runWithClient(ctx, cmd, func(ctx context.Context, client *uptraceapi.Client, projectID int64) error {
+		return fn(ctx, dashboard.NewSyncer(client, projectID))
+	})
+}
+
+// runWithClient runs fn with an Uptrace client and the project from --project or
+// the config.
+func runWithClient(
+	ctx context.Context,
+	cmd *cli.Command,
+	fn func(ctx context.Context, client *uptraceapi.Client, projectID int64) error,
+) error {
+	var client *uptraceapi.Client
+	var conf *appconf.Config
+	return bootstrap.RunCommand(
+		ctx,
+		cmd,
+		func(ctx context.Context) error {
+			projectID := cmd.Int64("project")
+			if projectID == 0 {
+				projectID = conf.Uptrace.ProjectID
+			}
+			return fn(ctx, client, projectID)
+		},
+		fx.Provide(bootstrap.NewUptraceClient),
+		fx.Populate(&client, &conf),
+	)
+}
+
+// confirm asks the user to type yes before applying a plan.
+func confirm(cmd *cli.Command) bool {
+	if cmd.Bool("auto-approve") {
+		return true
+	}
+	fmt.Fprint(cmd.Root().Writer, "\nDo you want to perform these actions? Only 'yes' will be accepted: ")
+	answer, _ := bufio.NewReader(cmd.Root().Reader).ReadString('\n')
+	if strings.TrimSpace(answer) != "yes" {
+		fmt.Fprintln(cmd.Root().Writer, "Apply cancelled.")
+		return false
+	}
+	return true
+}
+
+func applyDashboards(ctx context.Context, cmd *cli.Command, syncer *dashboard.Syncer) error {
+	w := cmd.Root().Writer
+
+	plan, err := syncer.Plan(ctx, cmd.String("dir"), cmd.Bool("prune"))
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(w, plan)
+	if len(plan.Invalid) > 0 {
+		return fmt.Errorf("fix the invalid files before applying")
+	}
+	if len(plan.Changes) == 0 {
+		return nil
+	}
+
+	if !confirm(cmd) {
+		return nil
+	}
+
+	err = syncer.Apply(ctx, plan, func(c *dashboard.Change) {
+		fmt.Fprintf(w, "%s %q: done\n", c.Action, c.Name)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "Apply complete.")
+	return nil
+}
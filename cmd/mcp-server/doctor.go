@@ -0,0 +1,487 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uptrace/oapi-codegen-dd/v3/pkg/runtime"
+	"github.com/urfave/cli/v3"
+
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+const (
+	// maxClockSkew is the skew reported as a warning; twice as much fails.
+	maxClockSkew = 30 * time.Second
+	// certExpiryWarning is how long before expiry a certificate is reported.
+	certExpiryWarning = 14 * 24 * time.Hour
+)
+
+const notUptraceHint = "check that api_url is the API of Uptrace, e.g. https://api.uptrace.dev, " +
+	"and not the address of a proxy or the UI"
+
+func doctorCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "doctor",
+		Usage: "Check the config, the connection to Uptrace, the API token and project permissions",
+		Description: "Runs each check in turn and prints PASS, WARN, FAIL or SKIP with a hint on how\n" +
+			"to fix problems. Exits with a non-zero status when a check fails.",
+		Flags: []cli.Flag{
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "Timeout of each network check",
+				Value: 10 * time.Second,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			d := &doctor{
+				w:       cmd.Root().Writer,
+				timeout: cmd.Duration("timeout"),
+			}
+			return d.run(ctx, cmd.String("config"))
+		},
+	}
+}
+
+type checkStatus string
+
+const (
+	checkPass checkStatus = "PASS"
+	checkWarn checkStatus = "WARN"
+	checkFail checkStatus = "FAIL"
+	checkSkip checkStatus = "SKIP"
+)
+
+type checkResult struct {
+	status  checkStatus
+	message string
+	hint    string
+}
+
+func pass(format string, args ...any) checkResult {
+	return checkResult{status: checkPass, message: fmt.Sprintf(format, args...)}
+}
+
+func skip(format string, args ...any) checkResult {
+	return checkResult{status: checkSkip, message: fmt.Sprintf(format, args...)}
+}
+
+func warn(hint, format string, args ...any) checkResult {
+	return checkResult{status: checkWarn, message: fmt.Sprintf(format, args...), hint: hint}
+}
+
+func fail(hint, format string, args ...any) checkResult {
+	return checkResult{status: checkFail, message: fmt.Sprintf(format, args...), hint: hint}
+}
+
+type doctor struct {
+	w       io.Writer
+	timeout time.Duration
+
+	conf     *appconf.Config
+	apiURL   *url.URL
+	projects []int64
+	client   *uptraceapi.Client
+	doer     *probeDoer
+
+	failed   int
+	warnings int
+}
+
+func (d *doctor) run(ctx context.Context, configPath string) error {
+	ok := d.report("config", d.checkConfig(configPath))
+	if d.conf != nil {
+		if d.conf.SLO.File != "" {
+			d.report("slo file", d.checkSLOFile())
+		}
+		if d.conf.Prompts.Dir != "" {
+			d.report("prompts dir", d.checkPromptsDir())
+		}
+	}
+
+	ok = d.runCheck(ctx, ok, "dns", d.checkDNS)
+	ok = d.runCheck(ctx, ok, "tls", d.checkTLS)
+	ok = d.runCheck(ctx, ok, "api token", d.checkToken)
+	// Clock skew does not affect the project checks.
+	d.runCheck(ctx, ok, "clock", d.checkClock)
+
+	for _, projectID := range d.projects {
+		name := fmt.Sprintf("project %d", projectID)
+		read := d.runCheck(ctx, ok, name+" read", func(ctx context.Context) checkResult {
+			return d.checkRead(ctx, projectID)
+		})
+		d.runCheck(ctx, read, name+" write", func(ctx context.Context) checkResult {
+			return d.checkWrite(ctx, projectID)
+		})
+	}
+
+	fmt.Fprintln(d.w)
+	switch {
+	case d.failed > 0:
+		fmt.Fprintf(d.w, "%d check(s) failed, %d warning(s).\n", d.failed, d.warnings)
+		return fmt.Errorf("%d check(s) failed", d.failed)
+	case d.warnings > 0:
+		fmt.Fprintf(d.w, "All checks passed with %d warning(s).\n", d.warnings)
+	default:
+		fmt.Fprintln(d.w, "All checks passed.")
+	}
+	return nil
+}
+
+// runCheck runs a network check with a timeout, or skips it unless the checks
+// it depends on passed.
+func (d *doctor) runCheck(
+	ctx context.Context, ok bool, name string, check func(ctx context.Context) checkResult,
+) bool {
+	if !ok {
+		return d.report(name, skip("an earlier check failed"))
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	return d.report(name, check(ctx))
+}
+
+// report prints the result of a check and reports whether later checks that
+// depend on it can run.
+func (d *doctor) report(name string, res checkResult) bool {
+	fmt.Fprintf(d.w, "%-4s  %-22s  %s\n", res.status, name, res.message)
+	if res.hint != "" {
+		fmt.Fprintf(d.w, "%-4s  %-22s  hint: %s\n", "", "", res.hint)
+	}
+	switch res.status {
+	case checkFail:
+		d.failed++
+		return false
+	case checkWarn:
+		d.warnings++
+	case checkSkip:
+		return false
+	}
+	return true
+}
+
+func (d *doctor) checkConfig(path string) checkResult {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	conf, err := appconf.Load(path)
+	if err != nil {
+		return fail("pass the path of a YAML config with --config; see config.yaml.example", "%s", err)
+	}
+	d.conf = conf
+
+	up := conf.Uptrace
+	if up.APIURL == "" {
+		return fail("set uptrace.api_url, e.g. https://api.uptrace.dev", "uptrace.api_url is empty")
+	}
+	apiURL, err := url.Parse(up.APIURL)
+	if err != nil || (apiURL.Scheme != "http" && apiURL.Scheme != "https") || apiURL.Host == "" {
+		return fail("set uptrace.api_url to the http(s) URL of Uptrace, e.g. https://api.uptrace.dev",
+			"uptrace.api_url %q is not an http(s) URL", up.APIURL)
+	}
+	d.apiURL = apiURL
+
+	if up.APIToken == "" || strings.ContainsAny(up.APIToken, "<>") {
+		return fail("create a token in the Uptrace UI under your profile and set uptrace.api_token",
+			"uptrace.api_token is not set")
+	}
+	if up.ProjectID <= 0 {
+		return fail("set uptrace.project_id to the ID in the project URL, e.g. 1 for /projects/1/...",
+			"uptrace.project_id is not set")
+	}
+	d.projects = []int64{up.ProjectID}
+
+	if up.DSN != "" {
+		dsnProject, err := dsnProjectID(up.DSN)
+		if err != nil {
+			return fail("copy the DSN from the project settings in Uptrace", "uptrace.dsn: %s", err)
+		}
+		if dsnProject != up.ProjectID {
+			d.projects = append(d.projects, dsnProject)
+		}
+	}
+
+	d.doer = &probeDoer{client: &http.Client{}}
+	apiClient, err := runtime.NewAPIClient(
+		up.APIURL,
+		runtime.WithHTTPClient(d.doer),
+		runtime.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer "+up.APIToken)
+			return nil
+		}),
+	)
+	if err != nil {
+		return fail("", "error creating API client: %s", err)
+	}
+	d.client = uptraceapi.NewClient(apiClient)
+
+	return pass("%s: api_url %s, project %d, token %s",
+		path, up.APIURL, up.ProjectID, maskToken(up.APIToken))
+}
+
+func (d *doctor) checkSLOFile() checkResult {
+	slos, err := appconf.LoadSLOs(d.conf.SLO.File)
+	if err != nil {
+		return fail("fix slo.file or remove it; see slo.yaml.example", "%s", err)
+	}
+	return pass("%d SLO(s) in %s", len(slos), d.conf.SLO.File)
+}
+
+func (d *doctor) checkPromptsDir() checkResult {
+	fi, err := os.Stat(d.conf.Prompts.Dir)
+	if err != nil {
+		return fail("fix prompts.dir or remove it", "%s", err)
+	}
+	if !fi.IsDir() {
+		return fail("prompts.dir must be a directory of *.yaml prompt templates",
+			"%s is not a directory", d.conf.Prompts.Dir)
+	}
+	return pass("%s", d.conf.Prompts.Dir)
+}
+
+func (d *doctor) checkDNS(ctx context.Context) checkResult {
+	host := d.apiURL.Hostname()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return fail("check the host of uptrace.api_url and the DNS settings of this machine",
+			"%s", err)
+	}
+	return pass("%s resolves to %s", host, strings.Join(addrs, ", "))
+}
+
+func (d *doctor) checkTLS(ctx context.Context) checkResult {
+	host := d.apiURL.Hostname()
+	if d.apiURL.Scheme != "https" {
+		if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+			return pass("plain HTTP to a local server")
+		}
+		return warn("use an https:// api_url unless Uptrace is only reachable on a trusted network",
+			"api_url uses plain HTTP, so the API token is sent unencrypted")
+	}
+
+	port := d.apiURL.Port()
+	if port == "" {
+		port = "443"
+	}
+	dialer := &tls.Dialer{Config: &tls.Config{ServerName: host}}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		var certErr *tls.CertificateVerificationError
+		if errors.As(err, &certErr) {
+			return fail("if a proxy or a private CA signs the certificate, add the CA to the system "+
+				"trust store or point SSL_CERT_FILE at it", "%s", err)
+		}
+		return fail("check that the port is open and that no firewall or proxy blocks the connection",
+			"%s", err)
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	cert := state.PeerCertificates[0]
+	msg := fmt.Sprintf("%s, certificate issued by %s, valid until %s",
+		tls.VersionName(state.Version), cert.Issuer.CommonName, cert.NotAfter.Format(time.DateOnly))
+	if time.Until(cert.NotAfter) < certExpiryWarning {
+		return warn("renew the certificate of the Uptrace server", "%s", msg)
+	}
+	return pass("%s", msg)
+}
+
+func (d *doctor) checkToken(ctx context.Context) checkResult {
+	projectID := d.projects[0]
+	_, err := d.client.ListDashboardTags(ctx, &uptraceapi.ListDashboardTagsRequestOptions{
+		PathParams: &uptraceapi.ListDashboardTagsPath{ProjectID: projectID},
+	})
+	resp := d.doer.last
+	switch {
+	case resp == nil:
+		return fail("check that Uptrace is reachable at api_url and that HTTPS_PROXY is set if "+
+			"this machine needs a proxy", "%v", d.doer.err)
+	case resp.status == http.StatusUnauthorized:
+		return fail("create a new token in the Uptrace UI under your profile and set uptrace.api_token",
+			"the token was rejected: %s", resp.message)
+	case err == nil, resp.status == http.StatusForbidden, resp.status == http.StatusNotFound:
+		// The project checks report missing access.
+		return pass("the token is accepted by %s", d.apiURL.Host)
+	default:
+		return fail(notUptraceHint, "HTTP %d: %s", resp.status, resp.message)
+	}
+}
+
+func (d *doctor) checkClock(ctx context.Context) checkResult {
+	resp := d.doer.last
+	if resp == nil || resp.date.IsZero() {
+		return skip("the server did not send a Date header")
+	}
+
+	// Date has a resolution of a second.
+	skew := resp.date.Sub(resp.sent.Add(resp.rtt / 2)).Round(time.Second)
+	if skew.Abs() <= time.Second {
+		return pass("in sync with the server")
+	}
+
+	const hint = "sync the system clock, e.g. enable NTP; time ranges such as last 15m " +
+		"are resolved against the local clock"
+	msg := fmt.Sprintf("the local clock is %s %s the server", skew.Abs(), aheadOrBehind(skew))
+	switch {
+	case skew.Abs() > 2*maxClockSkew:
+		return fail(hint, "%s", msg)
+	case skew.Abs() > maxClockSkew:
+		return warn(hint, "%s", msg)
+	default:
+		return pass("%s", msg)
+	}
+}
+
+func (d *doctor) checkRead(ctx context.Context, projectID int64) checkResult {
+	resp, err := d.client.ListMonitors(ctx, &uptraceapi.ListMonitorsRequestOptions{
+		PathParams: &uptraceapi.ListMonitorsPath{ProjectID: projectID},
+	})
+	if err == nil {
+		return pass("%d monitor(s)", len(resp.Monitors))
+	}
+	return d.projectError(projectID)
+}
+
+// checkWrite unpins a dashboard that does not exist. A token that may change
+// the project gets past the permission check and receives 404, and nothing is
+// changed either way.
+func (d *doctor) checkWrite(ctx context.Context, projectID int64) checkResult {
+	_, err := d.client.UnpinDashboard(ctx, &uptraceapi.UnpinDashboardRequestOptions{
+		PathParams: &uptraceapi.UnpinDashboardPath{ProjectID: projectID, DashboardID: 0},
+	})
+	resp := d.doer.last
+	switch {
+	case resp == nil:
+		return d.projectError(projectID)
+	case err == nil, resp.status == http.StatusNotFound, resp.status == http.StatusBadRequest:
+		return pass("the token may change dashboards and monitors")
+	case resp.status == http.StatusUnauthorized, resp.status == http.StatusForbidden:
+		return warn("tools that create or change dashboards and monitors, and the apply commands, "+
+			"need a token of a user who may edit the project",
+			"the token is read-only: %s", resp.message)
+	default:
+		return d.projectError(projectID)
+	}
+}
+
+// projectError explains the failed request of a project check.
+func (d *doctor) projectError(projectID int64) checkResult {
+	resp := d.doer.last
+	if resp == nil {
+		return fail("check the network connection to Uptrace", "%v", d.doer.err)
+	}
+	switch resp.status {
+	case http.StatusForbidden:
+		return fail("ask a project owner to add your user to the project, or use a token of a member",
+			"the token has no access to project %d", projectID)
+	case http.StatusNotFound:
+		return fail("check uptrace.project_id and uptrace.dsn; the project ID is the number in the "+
+			"project URL", "project %d does not exist", projectID)
+	default:
+		return fail(notUptraceHint, "HTTP %d: %s", resp.status, resp.message)
+	}
+}
+
+// probeDoer sends requests and remembers the last response, because the
+// generated client drops the status when it can't decode an error body, e.g.
+// the HTML page of a proxy.
+type probeDoer struct {
+	client *http.Client
+	last   *probeResponse
+	// err is the error of the last request that got no response.
+	err error
+}
+
+type probeResponse struct {
+	status int
+	// message is the message of an API error or the start of another body.
+	message string
+	date    time.Time
+	sent    time.Time
+	rtt     time.Duration
+}
+
+func (d *probeDoer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	d.last, d.err = nil, nil
+	sent := time.Now()
+	resp, err := d.client.Do(req.WithContext(ctx))
+	if err != nil {
+		d.err = err
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		d.err = err
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	d.last = &probeResponse{
+		status:  resp.StatusCode,
+		message: responseMessage(body),
+		sent:    sent,
+		rtt:     time.Since(sent),
+	}
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		d.last.date = date
+	}
+	return resp, nil
+}
+
+func responseMessage(body []byte) string {
+	var apiErr uptraceapi.Error
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+		return apiErr.Message
+	}
+	msg := strings.Join(strings.Fields(string(body)), " ")
+	if len(msg) > 100 {
+		msg = msg[:100] + "..."
+	}
+	return msg
+}
+
+// dsnProjectID returns the project ID of a DSN such as
+// https://<token>@api.uptrace.dev/<project_id>.
+func dsnProjectID(dsn string) (int64, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		// The error quotes the DSN, which contains the token.
+		return 0, errors.New("the DSN is not a URL")
+	}
+	s := strings.Trim(u.Path, "/")
+	projectID, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || projectID <= 0 {
+		return 0, errors.New("the DSN does not end with a project ID")
+	}
+	return projectID, nil
+}
+
+// maskToken shows the end of a token, which is enough to tell tokens apart.
+func maskToken(token string) string {
+	if len(token) <= 8 {
+		return "****"
+	}
+	return "****" + token[len(token)-4:]
+}
+
+func aheadOrBehind(skew time.Duration) string {
+	if skew > 0 {
+		return "behind"
+	}
+	return "ahead of"
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/uptrace/mcp/testutil"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+func TestDoctor(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      string
+		status  int
+		body    any
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			want: []string{
+				`PASS  api token`,
+				`PASS  clock +in sync`,
+				`PASS  project 1 read +2 monitor\(s\)`,
+				`PASS  project 1 write`,
+				`All checks passed\.`,
+			},
+		},
+		{
+			name:   "invalid token",
+			op:     "list_dashboard_tags",
+			status: http.StatusUnauthorized,
+			body:   uptraceapi.Error{Code: "unauthorized", Message: "invalid token"},
+			want: []string{
+				`FAIL  api token +the token was rejected: .*invalid token`,
+				`hint: create a new token`,
+				`SKIP  project 1 read`,
+			},
+			wantErr: true,
+		},
+		{
+			name:    "proxy error page",
+			op:      "list_dashboard_tags",
+			status:  http.StatusBadGateway,
+			body:    "<html>Bad Gateway</html>",
+			want:    []string{`FAIL  api token +HTTP 502`},
+			wantErr: true,
+		},
+		{
+			name:   "no project access",
+			op:     "list_monitors",
+			status: http.StatusForbidden,
+			body:   uptraceapi.Error{Code: "forbidden", Message: "forbidden"},
+			want: []string{
+				`FAIL  project 1 read +the token has no access to project 1`,
+				`SKIP  project 1 write`,
+			},
+			wantErr: true,
+		},
+		{
+			name:    "missing project",
+			op:      "list_monitors",
+			status:  http.StatusNotFound,
+			body:    uptraceapi.Error{Code: "not_found", Message: "project not found"},
+			want:    []string{`FAIL  project 1 read +project 1 does not exist`},
+			wantErr: true,
+		},
+		{
+			name:   "read-only token",
+			op:     "unpin_dashboard",
+			status: http.StatusForbidden,
+			body:   uptraceapi.Error{Code: "forbidden", Message: "read-only"},
+			want: []string{
+				`WARN  project 1 write +the token is read-only`,
+				`All checks passed with 1 warning\(s\)\.`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := testutil.NewServer(t)
+			if tt.op != "" {
+				srv.SetFixture(tt.op, tt.status, tt.body)
+			}
+
+			out, err := runCLI(t, srv, "doctor")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !regexp.MustCompile(want).MatchString(out) {
+					t.Errorf("output does not match %q:\n%s", want, out)
+				}
+			}
+			if reqs := srv.Requests("unpin_dashboard"); len(reqs) > 0 {
+				if id := reqs[0].PathParams["dashboard_id"]; id != "0" {
+					t.Errorf("write probe used dashboard %s", id)
+				}
+			}
+		})
+	}
+}
+
+func TestDoctorTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	conf := fmt.Sprintf("uptrace:\n  api_url: %s\n  api_token: %s\n  project_id: 1\n",
+		srv.URL, testutil.APIToken)
+	if err := os.WriteFile(path, []byte(conf), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &doctor{w: io.Discard, timeout: 5 * time.Second}
+	if d.report("config", d.checkConfig(path)); d.apiURL == nil {
+		t.Fatal("config failed")
+	}
+	res := d.checkTLS(t.Context())
+	if res.status != checkFail || !regexp.MustCompile(`certificate`).MatchString(res.message) {
+		t.Errorf("got %+v, want a certificate error", res)
+	}
+}
+
+func TestDoctorClock(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		skew time.Duration
+		want checkStatus
+	}{
+		{0, checkPass},
+		{10 * time.Second, checkPass},
+		{-45 * time.Second, checkWarn},
+		{2 * time.Minute, checkFail},
+	}
+	for _, tt := range tests {
+		d := &doctor{doer: &probeDoer{last: &probeResponse{
+			status: http.StatusOK,
+			date:   now.Add(tt.skew),
+			sent:   now,
+		}}}
+		if got := d.checkClock(t.Context()); got.status != tt.want {
+			t.Errorf("skew %s: got %+v, want %s", tt.skew, got, tt.want)
+		}
+	}
+}
@@ -12,12 +12,21 @@ import (
 
 	"github.com/uptrace/mcp/appconf"
 	"github.com/uptrace/mcp/bootstrap"
+	"github.com/uptrace/mcp/completion"
+	"github.com/uptrace/mcp/prompts"
+	"github.com/uptrace/mcp/resources"
 	"github.com/uptrace/mcp/tools"
 	"github.com/uptrace/mcp/uptraceapi"
 )
 
 func main() {
-	cmd := &cli.Command{
+	if err := newCommand().Run(context.Background(), os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func newCommand() *cli.Command {
+	return &cli.Command{
 		Name:  "mcp-server",
 		Usage: "Uptrace MCP server",
 		Flags: []cli.Flag{
@@ -38,27 +47,36 @@ func main() {
 				Aliases: []string{"d"},
 			},
 		},
+		Commands: []*cli.Command{
+			dashboardsCommand(),
+			monitorsCommand(),
+			doctorCommand(),
+			toolsCommand(),
+			callCommand(),
+		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			return bootstrap.Run(
-				ctx,
-				cmd,
-				fx.Provide(bootstrap.NewUptraceClient),
-				fx.Provide(bootstrap.NewServer),
-				tools.Module,
-				fx.Invoke(func(
-					ctx context.Context,
-					logger *slog.Logger,
-					client *uptraceapi.Client,
-					conf *appconf.Config,
-					server *mcp.Server,
-				) error {
-					return bootstrap.RunServer(ctx, logger, client, conf, server, cmd)
-				}),
+			var (
+				logger *slog.Logger
+				client *uptraceapi.Client
+				conf   *appconf.Config
+				server *mcp.Server
 			)
+			options := append(serverOptions(), fx.Populate(&logger, &client, &conf, &server))
+			return bootstrap.Run(ctx, cmd, func(ctx context.Context) error {
+				return bootstrap.RunServer(ctx, logger, client, conf, server, cmd)
+			}, options...)
 		},
 	}
+}
 
-	if err := cmd.Run(context.Background(), os.Args); err != nil {
-		log.Fatal(err)
+// serverOptions builds the MCP server with all tools, resources and prompts.
+func serverOptions() []fx.Option {
+	return []fx.Option{
+		fx.Provide(bootstrap.NewUptraceClient),
+		fx.Provide(completion.NewCompleter),
+		fx.Provide(bootstrap.NewServer),
+		tools.Module,
+		resources.Module,
+		prompts.Module,
 	}
 }
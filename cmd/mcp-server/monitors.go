@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/uptrace/mcp/monitor"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+func monitorsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "monitors",
+		Usage: "Manage monitors as code: export them to a directory of YAML files and apply changes back",
+		Commands: []*cli.Command{
+			{
+				Name:  "export",
+				Usage: "Write every monitor of the project to <dir>/<key>.yaml",
+				Flags: syncFlags("monitor"),
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return runMonitorSync(ctx, cmd, func(ctx context.Context, syncer *monitor.Syncer) error {
+						paths, warnings, err := syncer.Export(ctx, cmd.String("dir"))
+						if err != nil {
+							return err
+						}
+						for _, path := range paths {
+							fmt.Fprintln(cmd.Root().Writer, path)
+						}
+						for _, w := range warnings {
+							fmt.Fprintf(cmd.Root().ErrWriter, "Warning: %s\n", w)
+						}
+						fmt.Fprintf(cmd.Root().Writer, "Exported %d monitor(s) to %s.\n", len(paths), cmd.String("dir"))
+						return nil
+					})
+				},
+			},
+			{
+				Name:  "plan",
+				Usage: "Show what apply would change without changing anything",
+				Flags: append(syncFlags("monitor"), pruneFlag("monitor")),
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return runMonitorSync(ctx, cmd, func(ctx context.Context, syncer *monitor.Syncer) error {
+						plan, err := planMonitors(ctx, cmd, syncer)
+						if err != nil {
+							return err
+						}
+						fmt.Fprint(cmd.Root().Writer, plan)
+						return nil
+					})
+				},
+			},
+			{
+				Name:  "apply",
+				Usage: "Create and update monitors to match the directory, and delete the rest with --prune",
+				Flags: append(syncFlags("monitor"), pruneFlag("monitor"), autoApproveFlag()),
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return runMonitorSync(ctx, cmd, func(ctx context.Context, syncer *monitor.Syncer) error {
+						return applyMonitors(ctx, cmd, syncer)
+					})
+				},
+			},
+		},
+	}
+}
+
+func runMonitorSync(
+	ctx context.Context,
+	cmd *cli.Command,
+	fn func(ctx context.Context, syncer *monitor.Syncer) error,
+) error {
+	return runWithClient(ctx, cmd, func(ctx context.Context, client *uptraceapi.Client, projectID int64) error {
+		return fn(ctx, monitor.NewSyncer(client, projectID))
+	})
+}
+
+func planMonitors(ctx context.Context, cmd *cli.Command, syncer *monitor.Syncer) (*monitor.Plan, error) {
+	files, err := monitor.ReadDir(cmd.String("dir"))
+	if err != nil {
+		return nil, err
+	}
+	return syncer.Plan(ctx, files, cmd.Bool("prune"))
+}
+
+func applyMonitors(ctx context.Context, cmd *cli.Command, syncer *monitor.Syncer) error {
+	w := cmd.Root().Writer
+
+	plan, err := planMonitors(ctx, cmd, syncer)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(w, plan)
+	if len(plan.Invalid) > 0 {
+		return fmt.Errorf("fix the invalid monitor definitions before applying")
+	}
+	if len(plan.Changes) == 0 {
+		return nil
+	}
+
+	if !confirm(cmd) {
+		return nil
+	}
+
+	err = syncer.Apply(ctx, plan, func(c *monitor.Change) {
+		fmt.Fprintf(w, "%s %q: done\n", c.Action, c.Name)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "Apply complete.")
+	return nil
+}
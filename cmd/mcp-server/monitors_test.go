@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/uptrace/mcp/testutil"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+func TestMonitorsExportPlan(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	resp, err := srv.Client().ListMonitors(t.Context(), &uptraceapi.ListMonitorsRequestOptions{
+		PathParams: &uptraceapi.ListMonitorsPath{ProjectID: testutil.ProjectID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Channel 99 was deleted but the monitor still references it.
+	resp.Monitors[0].ChannelIds = []int{22, 21, 99}
+	srv.SetFixture("list_monitors", http.StatusOK, resp)
+
+	dir := t.TempDir()
+	out, err := runCLI(t, srv, "monitors", "export", "--dir", dir)
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+	if !strings.Contains(out, "notification channels [99] that no longer exist") {
+		t.Errorf("export does not warn about the deleted channel:\n%s", out)
+	}
+
+	// Reorder the channels of the exported monitor.
+	path := filepath.Join(dir, "checkout-p99-latency.yaml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reordered := strings.Replace(string(data),
+		"  - \"#oncall\"\n  - Incident webhook\n", "  - Incident webhook\n  - \"#oncall\"\n", 1)
+	if reordered == string(data) {
+		t.Fatalf("unexpected export:\n%s", data)
+	}
+	if err := os.WriteFile(path, []byte(reordered), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err = runCLI(t, srv, "monitors", "plan", "--dir", dir)
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+	for _, want := range []string{"Warning: monitor \"Checkout p99 latency\"", "No changes."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("plan does not contain %q:\n%s", want, out)
+		}
+	}
+}
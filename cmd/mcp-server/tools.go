@@ -0,0 +1,372 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/urfave/cli/v3"
+	"go.uber.org/fx"
+
+	"github.com/uptrace/mcp/bootstrap"
+)
+
+func toolsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "tools",
+		Usage: "Inspect the tools the server exposes",
+		Commands: []*cli.Command{
+			{
+				Name:      "list",
+				Usage:     "Print the name, annotations and parameters of every tool, or of the given tools",
+				ArgsUsage: "[tool...]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print the tools with their input schemas as JSON",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return runSession(ctx, cmd, func(ctx context.Context, session *mcp.ClientSession) error {
+						return listTools(ctx, cmd, session)
+					})
+				},
+			},
+		},
+	}
+}
+
+func callCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "call",
+		Usage:     "Call a tool and print its result",
+		ArgsUsage: "<tool>",
+		Description: "Arguments are given as a JSON object with --json and as --arg name=value, which\n" +
+			"takes precedence. Nested parameters use dots, e.g. --arg Query.limit=10. Values are\n" +
+			"parsed as JSON unless the parameter is a string.\n\n" +
+			"Example:\n\n" +
+			"   mcp-server -c config.yaml call list_span_groups --arg time_range='last 1h' \\\n" +
+			"     --json '{\"PathParams\": {\"project_id\": 0}, \"Query\": {\"query\": \"group by service_name\"}}'",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "arg",
+				Usage: "Tool argument as name=value; can be repeated",
+			},
+			&cli.StringFlag{
+				Name:  "json",
+				Usage: "Tool arguments as a JSON object",
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "Output format of tools that have a format parameter: json, compact, markdown_table or csv",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if cmd.Args().Len() != 1 {
+				return fmt.Errorf("usage: call <tool> [--arg name=value]... [--json '{...}']")
+			}
+			return runSession(ctx, cmd, func(ctx context.Context, session *mcp.ClientSession) error {
+				return callTool(ctx, cmd, session, cmd.Args().First())
+			})
+		},
+	}
+}
+
+// runSession builds the same server as the MCP server command and runs fn with
+// a client connected to it over an in-memory transport.
+func runSession(
+	ctx context.Context,
+	cmd *cli.Command,
+	fn func(ctx context.Context, session *mcp.ClientSession) error,
+) error {
+	var server *mcp.Server
+	options := append(serverOptions(), fx.Populate(&server))
+	return bootstrap.RunCommand(ctx, cmd, func(ctx context.Context) error {
+		clientTransport, serverTransport := mcp.NewInMemoryTransports()
+		serverSession, err := server.Connect(ctx, serverTransport, nil)
+		if err != nil {
+			return err
+		}
+		defer serverSession.Close()
+
+		client := mcp.NewClient(&mcp.Implementation{
+			Name:    bootstrap.AppName + "-cli",
+			Version: bootstrap.AppVersion,
+		}, nil)
+		session, err := client.Connect(ctx, clientTransport, nil)
+		if err != nil {
+			return err
+		}
+		defer session.Close()
+
+		return fn(ctx, session)
+	}, options...)
+}
+
+func listTools(ctx context.Context, cmd *cli.Command, session *mcp.ClientSession) error {
+	names := cmd.Args().Slice()
+
+	var tools []*mcp.Tool
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 || slices.Contains(names, tool.Name) {
+			tools = append(tools, tool)
+		}
+	}
+	for _, name := range names {
+		if !slices.ContainsFunc(tools, func(tool *mcp.Tool) bool { return tool.Name == name }) {
+			return fmt.Errorf("unknown tool %q", name)
+		}
+	}
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Name < tools[j].Name
+	})
+
+	w := cmd.Root().Writer
+	if cmd.Bool("json") {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tools)
+	}
+
+	for i, tool := range tools {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		printTool(w, tool)
+	}
+	return nil
+}
+
+func printTool(w io.Writer, tool *mcp.Tool) {
+	fmt.Fprint(w, tool.Name)
+	if hints := toolHints(tool.Annotations); len(hints) > 0 {
+		fmt.Fprintf(w, " [%s]", strings.Join(hints, ", "))
+	}
+	fmt.Fprintln(w)
+	if tool.Annotations != nil && tool.Annotations.Title != "" {
+		fmt.Fprintf(w, "  %s\n", tool.Annotations.Title)
+	}
+
+	params := schemaParams(tool.InputSchema)
+	if len(params) == 0 {
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, p := range params {
+		required := ""
+		if p.required {
+			required = "required"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", p.name, p.typ, required, firstLine(p.description))
+	}
+	tw.Flush()
+}
+
+// toolHints describes the annotations of a tool, applying the defaults of the
+// MCP spec for missing hints.
+func toolHints(a *mcp.ToolAnnotations) []string {
+	if a == nil {
+		return nil
+	}
+	var hints []string
+	if a.ReadOnlyHint {
+		hints = append(hints, "read-only")
+	} else if a.DestructiveHint == nil || *a.DestructiveHint {
+		hints = append(hints, "destructive")
+	}
+	if a.IdempotentHint {
+		hints = append(hints, "idempotent")
+	}
+	if a.OpenWorldHint == nil || *a.OpenWorldHint {
+		hints = append(hints, "open-world")
+	}
+	return hints
+}
+
+func firstLine(s string) string {
+	s, _, _ = strings.Cut(s, "\n")
+	return s
+}
+
+// schemaParam is a leaf parameter of an input schema. Parameters of nested
+// objects are named with dots, e.g. Query.limit.
+type schemaParam struct {
+	name        string
+	typ         string
+	required    bool
+	description string
+}
+
+func schemaParams(schema any) []schemaParam {
+	var params []schemaParam
+	walkSchema(schema, "", true, func(p schemaParam) {
+		params = append(params, p)
+	})
+	return params
+}
+
+func walkSchema(schema any, prefix string, required bool, fn func(schemaParam)) {
+	obj, _ := schema.(map[string]any)
+	props, _ := obj["properties"].(map[string]any)
+
+	var requiredNames []string
+	if list, ok := obj["required"].([]any); ok {
+		for _, name := range list {
+			if s, ok := name.(string); ok {
+				requiredNames = append(requiredNames, s)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop, _ := props[name].(map[string]any)
+		isRequired := required && slices.Contains(requiredNames, name)
+		if _, ok := prop["properties"].(map[string]any); ok {
+			walkSchema(prop, prefix+name+".", isRequired, fn)
+			continue
+		}
+		description, _ := prop["description"].(string)
+		fn(schemaParam{
+			name:        prefix + name,
+			typ:         schemaType(prop),
+			required:    isRequired,
+			description: description,
+		})
+	}
+}
+
+// schemaType returns the type of a property, ignoring null in type lists.
+func schemaType(prop map[string]any) string {
+	switch typ := prop["type"].(type) {
+	case string:
+		return typ
+	case []any:
+		for _, t := range typ {
+			if s, ok := t.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	return "any"
+}
+
+func callTool(ctx context.Context, cmd *cli.Command, session *mcp.ClientSession, name string) error {
+	var tool *mcp.Tool
+	for t, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			return err
+		}
+		if t.Name == name {
+			tool = t
+			break
+		}
+	}
+	if tool == nil {
+		return fmt.Errorf("unknown tool %q (see tools list)", name)
+	}
+
+	args, err := toolArgs(cmd, tool)
+	if err != nil {
+		return err
+	}
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      name,
+		Arguments: args,
+	})
+	if err != nil {
+		return err
+	}
+
+	w := cmd.Root().Writer
+	if res.IsError {
+		w = cmd.Root().ErrWriter
+	}
+	for _, content := range res.Content {
+		switch content := content.(type) {
+		case *mcp.TextContent:
+			fmt.Fprintln(w, strings.TrimSuffix(content.Text, "\n"))
+		case *mcp.ImageContent:
+			fmt.Fprintf(w, "[%s image, %d bytes]\n", content.MIMEType, len(content.Data))
+		default:
+			b, err := json.Marshal(content)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w, string(b))
+		}
+	}
+	if res.IsError {
+		return fmt.Errorf("%s failed", name)
+	}
+	return nil
+}
+
+// toolArgs merges --json and --arg into the arguments of tool.
+func toolArgs(cmd *cli.Command, tool *mcp.Tool) (map[string]any, error) {
+	args := make(map[string]any)
+	if s := cmd.String("json"); s != "" {
+		if err := json.Unmarshal([]byte(s), &args); err != nil {
+			return nil, fmt.Errorf("--json: %w", err)
+		}
+	}
+
+	types := make(map[string]string)
+	for _, p := range schemaParams(tool.InputSchema) {
+		types[p.name] = p.typ
+	}
+
+	if format := cmd.String("format"); format != "" {
+		if _, ok := types["format"]; !ok {
+			return nil, fmt.Errorf("%s has no format parameter", tool.Name)
+		}
+		args["format"] = format
+	}
+
+	for _, arg := range cmd.StringSlice("arg") {
+		name, raw, ok := strings.Cut(arg, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("--arg %q: want name=value", arg)
+		}
+
+		var value any = raw
+		if typ := types[name]; typ != "string" {
+			if err := json.Unmarshal([]byte(raw), &value); err != nil {
+				if typ != "any" && typ != "" {
+					return nil, fmt.Errorf("--arg %s: %s is not a valid %s", name, raw, typ)
+				}
+				value = raw
+			}
+		}
+		setArg(args, strings.Split(name, "."), value)
+	}
+	return args, nil
+}
+
+// setArg sets args[path[0]][path[1]]... to value, creating nested objects.
+func setArg(args map[string]any, path []string, value any) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := args[key].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			args[key] = next
+		}
+		args = next
+	}
+	args[path[len(path)-1]] = value
+}
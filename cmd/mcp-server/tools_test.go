@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/uptrace/mcp/testutil"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+func runCLI(t *testing.T, srv *testutil.Server, args ...string) (string, error) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	cmd := newCommand()
+	cmd.Writer = &stdout
+	cmd.ErrWriter = &stderr
+	cmd.ExitErrHandler = func(ctx context.Context, cmd *cli.Command, err error) {}
+
+	args = append([]string{"mcp-server", "-c", srv.ConfigFile()}, args...)
+	err := cmd.Run(t.Context(), args)
+	return stdout.String() + stderr.String(), err
+}
+
+func TestToolsList(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	out, err := runCLI(t, srv, "tools", "list", "list_span_groups", "delete_dashboard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"delete_dashboard [destructive, idempotent",
+		"list_span_groups [read-only, idempotent",
+		"PathParams.project_id",
+		"Query.limit",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+
+	out, err = runCLI(t, srv, "tools", "list", "--json", "validate_query")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tools []map[string]any
+	if err := json.Unmarshal([]byte(out), &tools); err != nil {
+		t.Fatal(err)
+	}
+	if len(tools) != 1 || tools[0]["inputSchema"] == nil {
+		t.Errorf("got %s", out)
+	}
+
+	if _, err := runCLI(t, srv, "tools", "list", "nope"); err == nil {
+		t.Error("listing an unknown tool succeeded")
+	}
+}
+
+func TestCall(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	out, err := runCLI(t, srv, "call", "list_span_groups",
+		"--json", `{"PathParams": {"project_id": 0}, "Query": {"query": "group by service_name"}}`,
+		"--arg", "Query.limit=5",
+		"--arg", "time_range=last 1h",
+		"--format", "csv",
+	)
+	if err != nil {
+		t.Fatal(err, out)
+	}
+	if !strings.Contains(out, "\n_group_id,_name,") {
+		t.Errorf("output is not CSV:\n%s", out)
+	}
+
+	req := srv.FirstRequest("list_span_groups")
+	if got := req.Query.Get("limit"); got != "5" {
+		t.Errorf("limit = %q, want 5", got)
+	}
+	if got := req.Query.Get("query"); got != "group by service_name" {
+		t.Errorf("query = %q", got)
+	}
+
+	srv.SetFixture("get_dashboard", http.StatusNotFound, uptraceapi.Error{
+		Code:    "not_found",
+		Message: "dashboard not found",
+	})
+	out, err = runCLI(t, srv, "call", "get_dashboard", "--json", `{"PathParams": {"project_id": 0, "dashboard_id": 99}}`)
+	if err == nil {
+		t.Errorf("a failed tool call succeeded:\n%s", out)
+	}
+
+	if _, err := runCLI(t, srv, "call", "list_span_groups", "--arg", "Query.limit=many"); err == nil {
+		t.Error("a non-integer limit was accepted")
+	}
+}
@@ -0,0 +1,60 @@
+package completion
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// cache keeps fetched completion candidates for a short time. Concurrent misses
+// for the same key share one fetch.
+type cache struct {
+	ttl   time.Duration
+	group singleflight.Group
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	items   []item
+	expires time.Time
+}
+
+func newCache(ttl time.Duration) *cache {
+	return &cache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+func (c *cache) get(key string, fetch func() ([]item, error)) ([]item, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && now.After(entry.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+	if ok {
+		return entry.items, nil
+	}
+
+	v, err, _ := c.group.Do(key, func() (any, error) {
+		items, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.entries[key] = cacheEntry{items: items, expires: time.Now().Add(c.ttl)}
+		c.mu.Unlock()
+		return items, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]item), nil
+}
@@ -0,0 +1,321 @@
+package completion
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+const (
+	// maxValues is the maximum number of values in a completion result allowed by
+	// the MCP spec.
+	maxValues = 100
+	// lookback is the time range used to discover services, endpoints, metrics and
+	// attributes.
+	lookback = 24 * time.Hour
+	// cacheTTL is how long fetched values are reused; completion requests arrive on
+	// every keystroke.
+	cacheTTL = 30 * time.Second
+)
+
+// item is a completion candidate. Label is matched as well as Value, so typing a
+// dashboard name completes its ID.
+type item struct {
+	Value string
+	Label string
+}
+
+type source func(c *Completer, ctx context.Context, args map[string]string) ([]item, error)
+
+// sources complete prompt arguments and resource template variables by name.
+var sources = map[string]source{
+	"service":         (*Completer).services,
+	"service_name":    (*Completer).services,
+	"endpoint":        (*Completer).endpoints,
+	"span_name":       (*Completer).endpoints,
+	"monitor_id":      (*Completer).monitors,
+	"dashboard_id":    (*Completer).dashboards,
+	"metric":          (*Completer).metrics,
+	"metric_name":     (*Completer).metrics,
+	"attribute":       (*Completer).metricAttributes,
+	"attr_key":        (*Completer).metricAttributes,
+	"attribute_value": (*Completer).metricAttributeValues,
+	"time_range":      (*Completer).timeRanges,
+}
+
+// resourceSources complete the {id} variable of resource templates by URI prefix.
+var resourceSources = map[string]source{
+	"uptrace://dashboards/": (*Completer).dashboards,
+	"uptrace://monitors/":   (*Completer).monitors,
+}
+
+// Completer answers completion/complete requests with values from Uptrace.
+type Completer struct {
+	client *uptraceapi.Client
+	conf   *appconf.Config
+	cache  *cache
+}
+
+func NewCompleter(client *uptraceapi.Client, conf *appconf.Config) *Completer {
+	return &Completer{
+		client: client,
+		conf:   conf,
+		cache:  newCache(cacheTTL),
+	}
+}
+
+// Complete implements mcp.ServerOptions.CompletionHandler.
+func (c *Completer) Complete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	params := req.Params
+	var args map[string]string
+	if params.Context != nil {
+		args = params.Context.Arguments
+	}
+
+	src := sources[params.Argument.Name]
+	if params.Ref != nil && params.Ref.Type == "ref/resource" && params.Argument.Name == "id" {
+		for prefix, s := range resourceSources {
+			if strings.HasPrefix(params.Ref.URI, prefix) {
+				src = s
+			}
+		}
+	}
+
+	result := &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{Values: []string{}}}
+	if src == nil {
+		return result, nil
+	}
+	items, err := src(c, ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	values := match(items, params.Argument.Value)
+	result.Completion.Total = len(values)
+	if len(values) > maxValues {
+		values = values[:maxValues]
+		result.Completion.HasMore = true
+	}
+	result.Completion.Values = values
+	return result, nil
+}
+
+// match returns the values whose value or label starts with prefix, followed by
+// the ones that only contain it, ignoring case.
+func match(items []item, prefix string) []string {
+	prefix = strings.ToLower(prefix)
+	var first, rest []string
+	seen := make(map[string]bool, len(items))
+	for _, it := range items {
+		if seen[it.Value] {
+			continue
+		}
+		value, label := strings.ToLower(it.Value), strings.ToLower(it.Label)
+		switch {
+		case strings.HasPrefix(value, prefix) || strings.HasPrefix(label, prefix):
+			first = append(first, it.Value)
+		case strings.Contains(value, prefix) || strings.Contains(label, prefix):
+			rest = append(rest, it.Value)
+		default:
+			continue
+		}
+		seen[it.Value] = true
+	}
+	return append(first, rest...)
+}
+
+func (c *Completer) services(ctx context.Context, args map[string]string) ([]item, error) {
+	return c.spanAttrValues(ctx, "service_name", "")
+}
+
+// endpoints completes span names, limited to the service from the context when
+// one is already set.
+func (c *Completer) endpoints(ctx context.Context, args map[string]string) ([]item, error) {
+	var where string
+	for _, name := range []string{"service", "service_name"} {
+		if service := args[name]; service != "" {
+			where = "where service_name = " + strconv.Quote(service)
+		}
+	}
+	return c.spanAttrValues(ctx, "_name", where)
+}
+
+// spanAttrValues returns the values of a span attribute ordered by span count.
+func (c *Completer) spanAttrValues(ctx context.Context, attr, where string) ([]item, error) {
+	return c.cache.get("span:"+attr+":"+where, func() ([]item, error) {
+		query := fmt.Sprintf("where %s exists | group by %s | count()", attr, attr)
+		if where != "" {
+			query = where + " | " + query
+		}
+		end := time.Now()
+		limit := uptraceapi.Limit(1000)
+		resp, err := c.client.ListSpanGroups(ctx, &uptraceapi.ListSpanGroupsRequestOptions{
+			PathParams: &uptraceapi.ListSpanGroupsPath{ProjectID: c.conf.Uptrace.ProjectID},
+			Query: &uptraceapi.ListSpanGroupsQuery{
+				TimeStart: end.Add(-lookback),
+				TimeEnd:   end,
+				Query:     &query,
+				Limit:     &limit,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		type counted struct {
+			value string
+			count float64
+		}
+		var rows []counted
+		for _, group := range resp.Groups {
+			var row counted
+			for key, v := range group {
+				name, _, _ := strings.Cut(key, "::")
+				switch {
+				case name == attr:
+					row.value = fmt.Sprint(v)
+				case strings.HasPrefix(name, "count"):
+					row.count, _ = v.(float64)
+				}
+			}
+			if row.value != "" {
+				rows = append(rows, row)
+			}
+		}
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].count > rows[j].count })
+
+		items := make([]item, len(rows))
+		for i, row := range rows {
+			items[i] = item{Value: row.value}
+		}
+		return items, nil
+	})
+}
+
+func (c *Completer) monitors(ctx context.Context, args map[string]string) ([]item, error) {
+	return c.cache.get("monitors", func() ([]item, error) {
+		resp, err := c.client.ListMonitors(ctx, &uptraceapi.ListMonitorsRequestOptions{
+			PathParams: &uptraceapi.ListMonitorsPath{ProjectID: c.conf.Uptrace.ProjectID},
+		})
+		if err != nil {
+			return nil, err
+		}
+		items := make([]item, len(resp.Monitors))
+		for i, m := range resp.Monitors {
+			items[i] = item{Value: strconv.FormatInt(m.ID, 10), Label: m.Name}
+		}
+		return items, nil
+	})
+}
+
+func (c *Completer) dashboards(ctx context.Context, args map[string]string) ([]item, error) {
+	return c.cache.get("dashboards", func() ([]item, error) {
+		resp, err := c.client.ListDashboards(ctx, &uptraceapi.ListDashboardsRequestOptions{
+			PathParams: &uptraceapi.ListDashboardsPath{ProjectID: c.conf.Uptrace.ProjectID},
+		})
+		if err != nil {
+			return nil, err
+		}
+		items := make([]item, len(resp.Dashboards))
+		for i, d := range resp.Dashboards {
+			items[i] = item{Value: strconv.FormatInt(d.ID, 10), Label: d.Name}
+		}
+		return items, nil
+	})
+}
+
+func (c *Completer) metrics(ctx context.Context, args map[string]string) ([]item, error) {
+	return c.cache.get("metrics", func() ([]item, error) {
+		end := time.Now()
+		resp, err := c.client.ExploreMetrics(ctx, &uptraceapi.ExploreMetricsRequestOptions{
+			PathParams: &uptraceapi.ExploreMetricsPath{ProjectID: c.conf.Uptrace.ProjectID},
+			Query: &uptraceapi.ExploreMetricsQuery{
+				TimeStart: end.Add(-lookback),
+				TimeEnd:   end,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		items := make([]item, len(resp.Metrics))
+		for i, m := range resp.Metrics {
+			items[i] = item{Value: m.Name}
+		}
+		return items, nil
+	})
+}
+
+func (c *Completer) metricAttributes(ctx context.Context, args map[string]string) ([]item, error) {
+	return c.cache.get("metric-attributes", func() ([]item, error) {
+		end := time.Now()
+		resp, err := c.client.ListMetricAttributes(ctx, &uptraceapi.ListMetricAttributesRequestOptions{
+			PathParams: &uptraceapi.ListMetricAttributesPath{ProjectID: c.conf.Uptrace.ProjectID},
+			Query: &uptraceapi.ListMetricAttributesQuery{
+				TimeStart: end.Add(-lookback),
+				TimeEnd:   end,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		items := make([]item, len(resp.Items))
+		for i, key := range resp.Items {
+			items[i] = item{Value: key.Value}
+		}
+		return items, nil
+	})
+}
+
+// metricAttributeValues completes the values of the attribute already chosen in
+// the attribute (or attr_key) argument.
+func (c *Completer) metricAttributeValues(ctx context.Context, args map[string]string) ([]item, error) {
+	key := args["attribute"]
+	if key == "" {
+		key = args["attr_key"]
+	}
+	if key == "" {
+		return nil, nil
+	}
+	if !strings.Contains(key, "::") {
+		key += "::str"
+	}
+	return c.cache.get("metric-attribute-values:"+key, func() ([]item, error) {
+		end := time.Now()
+		resp, err := c.client.ListMetricAttributeValues(ctx, &uptraceapi.ListMetricAttributeValuesRequestOptions{
+			PathParams: &uptraceapi.ListMetricAttributeValuesPath{
+				ProjectID: c.conf.Uptrace.ProjectID,
+				AttrKey:   key,
+			},
+			Query: &uptraceapi.ListMetricAttributeValuesQuery{
+				TimeStart: end.Add(-lookback),
+				TimeEnd:   end,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		items := make([]item, len(resp.Items))
+		for i, v := range resp.Items {
+			items[i] = item{Value: v.Value}
+		}
+		return items, nil
+	})
+}
+
+func (c *Completer) timeRanges(ctx context.Context, args map[string]string) ([]item, error) {
+	return []item{
+		{Value: "last 15m"},
+		{Value: "last 1h"},
+		{Value: "last 6h"},
+		{Value: "last 24h"},
+		{Value: "last 7d"},
+		{Value: "today"},
+		{Value: "yesterday"},
+	}, nil
+}
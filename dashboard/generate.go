@@ -0,0 +1,214 @@
+package dashboard
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Metric describes a metric reported by a service.
+type Metric struct {
+	Name        string
+	Instrument  string // counter, prom-counter, additive, gauge, histogram or summary
+	Description string
+}
+
+// ServiceInput is what GenerateService builds a dashboard from.
+type ServiceInput struct {
+	Name    string
+	Service string
+	// ServiceAttr is the attribute holding the service name, e.g. service_name.
+	ServiceAttr string
+	Metrics     []Metric
+	// Systems are the span systems of the service, e.g. httpserver:checkout.
+	Systems []string
+}
+
+const (
+	spanWidth   = GridColumns / 3
+	chartWidth  = GridColumns / 2
+	chartHeight = 28
+	gaugeWidth  = GridColumns / 4
+	gaugeHeight = 14
+)
+
+// GenerateService builds a dashboard for one service with a row per span system
+// followed by a row per metric subsystem (http, db, runtime, ...). Counters are shown
+// as rates, histograms as percentiles and gauges as their current value.
+func GenerateService(in *ServiceInput) *Dashboard {
+	if in.ServiceAttr == "" {
+		in.ServiceAttr = "service_name"
+	}
+	where := fmt.Sprintf("where %s = %q", in.ServiceAttr, in.Service)
+
+	d := &Dashboard{Schema: SchemaV2, Name: in.Name}
+	if d.Name == "" {
+		d.Name = "Service: " + in.Service
+	}
+
+	for _, system := range in.Systems {
+		d.GridRows = append(d.GridRows, spanRow(system, where))
+	}
+
+	bySubsystem := make(map[string][]Metric)
+	for _, m := range in.Metrics {
+		key := subsystem(m.Name)
+		bySubsystem[key] = append(bySubsystem[key], m)
+	}
+	keys := make([]string, 0, len(bySubsystem))
+	for key := range bySubsystem {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		metrics := bySubsystem[key]
+		sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+
+		row := GridRow{Title: subsystemTitle(key)}
+		for _, m := range metrics {
+			row.Items = append(row.Items, metricItems(m, where)...)
+		}
+		layoutItems(row.Items)
+		d.GridRows = append(d.GridRows, row)
+	}
+	return d
+}
+
+// layoutItems places items left to right, wrapping to a new line below the
+// tallest item when the next one doesn't fit within GridColumns.
+func layoutItems(items []GridItem) {
+	var x, y, lineHeight int
+	for i := range items {
+		item := &items[i]
+		if x > 0 && x+item.Width > GridColumns {
+			x, y, lineHeight = 0, y+lineHeight, 0
+		}
+		item.XAxis, item.YAxis = x, y
+		x += item.Width
+		lineHeight = max(lineHeight, item.Height)
+	}
+}
+
+func spanRow(system, where string) GridRow {
+	metrics := []string{"uptrace_tracing_spans as $spans"}
+	systemWhere := fmt.Sprintf("where _system = %q", system)
+	return GridRow{
+		Title: "Spans: " + system,
+		Items: []GridItem{
+			{
+				Title:   "Number of spans",
+				Width:   spanWidth,
+				Height:  chartHeight,
+				Type:    ItemChart,
+				Metrics: metrics,
+				Query:   []string{"per_min(count($spans)) as spans", where, systemWhere},
+			},
+			{
+				Title:   "Span duration",
+				Width:   spanWidth,
+				Height:  chartHeight,
+				Type:    ItemChart,
+				Metrics: metrics,
+				Query:   []string{"p50($spans)", "p90($spans)", "p99($spans)", where, systemWhere},
+			},
+			{
+				Title:   "Number of errors",
+				Width:   spanWidth,
+				Height:  chartHeight,
+				Type:    ItemChart,
+				Metrics: metrics,
+				Query: []string{
+					"per_min(count($spans)) as errors", where, systemWhere, `where _status_code = "error"`,
+				},
+			},
+		},
+	}
+}
+
+// metricItems picks charts by instrument: rates for counters, percentiles and a
+// rate for histograms, the current value for gauges.
+func metricItems(m Metric, where string) []GridItem {
+	alias := metricAlias(m.Name)
+	metrics := []string{m.Name + " as " + alias}
+	item := func(title string, typ ItemType, query ...string) GridItem {
+		width, height := chartWidth, chartHeight
+		if typ == ItemGauge {
+			width, height = gaugeWidth, gaugeHeight
+		}
+		return GridItem{
+			Title:       title,
+			Description: m.Description,
+			Width:       width,
+			Height:      height,
+			Type:        typ,
+			Metrics:     metrics,
+			Query:       append(query, where),
+		}
+	}
+
+	switch m.Instrument {
+	case "counter", "prom-counter":
+		return []GridItem{item(m.Name, ItemChart, fmt.Sprintf("per_min(sum(%s))", alias))}
+	case "histogram":
+		return []GridItem{
+			item(m.Name+" percentiles", ItemChart,
+				fmt.Sprintf("p50(%s)", alias), fmt.Sprintf("p90(%s)", alias), fmt.Sprintf("p99(%s)", alias)),
+			item(m.Name+" rate", ItemChart, fmt.Sprintf("per_min(count(%s))", alias)),
+		}
+	case "gauge":
+		return []GridItem{item(m.Name, ItemGauge, fmt.Sprintf("last(%s)", alias))}
+	default: // additive, summary
+		return []GridItem{item(m.Name, ItemChart, fmt.Sprintf("sum(%s)", alias))}
+	}
+}
+
+var nonAliasChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// metricAlias derives a short alias from the last part of the metric name, e.g.
+// $duration for http.server.duration.
+func metricAlias(name string) string {
+	parts := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool { return r == '.' })
+	var alias string
+	if len(parts) > 0 {
+		alias = nonAliasChars.ReplaceAllString(parts[len(parts)-1], "_")
+	}
+	if alias == "" || (alias[0] >= '0' && alias[0] <= '9') {
+		alias = "m_" + alias
+	}
+	return "$" + alias
+}
+
+// subsystem is the first segment of a metric name, e.g. http for http.server.duration
+// or process for process_cpu_seconds.
+func subsystem(name string) string {
+	i := strings.IndexAny(name, "._")
+	if i <= 0 {
+		return name
+	}
+	return strings.ToLower(name[:i])
+}
+
+var subsystemTitles = map[string]string{
+	"db":      "Database",
+	"http":    "HTTP",
+	"rpc":     "RPC",
+	"jvm":     "JVM",
+	"go":      "Go runtime",
+	"dotnet":  ".NET",
+	"k8s":     "Kubernetes",
+	"system":  "System",
+	"process": "Process",
+	"runtime": "Runtime",
+}
+
+func subsystemTitle(key string) string {
+	if title, ok := subsystemTitles[key]; ok {
+		return title
+	}
+	if key == "" {
+		return "Other"
+	}
+	return strings.ToUpper(key[:1]) + key[1:]
+}
@@ -0,0 +1,505 @@
+package dashboard
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/uptrace/mcp/uptraceapi"
+	"github.com/uptrace/mcp/uql"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is an error or warning found by Lint. Line and Column are 1-based and
+// zero when the problem is not tied to a position.
+type Problem struct {
+	Severity Severity `json:"severity"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Path     string   `json:"path,omitempty" jsonschema:"Location in the document, e.g. grid_rows[0].items[1].width."`
+	Message  string   `json:"message"`
+}
+
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", p.Line)
+	}
+	if p.Path != "" {
+		b.WriteString(p.Path)
+		b.WriteString(": ")
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// HasErrors reports whether any of the problems is an error rather than a warning.
+func HasErrors(problems []Problem) bool {
+	for _, p := range problems {
+		if p.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint checks a YAML dashboard against schema v2: required fields, item types, widths
+// within GridColumns on each line of a row and that every $alias used in a query is declared in metrics.
+// Errors are problems the API would reject; warnings are unknown fields, unused
+// metrics, item fields left to Uptrace defaults and queries the local UQL parser
+// doesn't understand.
+func Lint(data []byte) []Problem {
+	l := new(linter)
+
+	file, err := parser.ParseBytes(data, 0)
+	if err != nil {
+		l.syntaxError(err)
+		return l.problems
+	}
+	if len(file.Docs) == 0 || file.Docs[0].Body == nil {
+		l.errorf(nil, "", "dashboard is empty")
+		return l.problems
+	}
+	if len(file.Docs) > 1 {
+		l.errorf(file.Docs[1].Body, "", "expected a single YAML document, got %d", len(file.Docs))
+	}
+
+	l.dashboard(file.Docs[0].Body)
+	sort.SliceStable(l.problems, func(i, j int) bool {
+		return l.problems[i].Line < l.problems[j].Line
+	})
+	return l.problems
+}
+
+var (
+	dashboardKeys = yamlKeys(reflect.TypeFor[Dashboard]())
+	tableItemKeys = yamlKeys(reflect.TypeFor[TableItem]())
+	gridRowKeys   = yamlKeys(reflect.TypeFor[GridRow]())
+	gridItemKeys  = yamlKeys(reflect.TypeFor[GridItem]())
+)
+
+// yamlKeys returns the field names declared by the yaml tags of a struct type.
+func yamlKeys(typ reflect.Type) map[string]bool {
+	keys := make(map[string]bool, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+type linter struct {
+	problems []Problem
+}
+
+func (l *linter) add(severity Severity, node ast.Node, path, format string, args ...any) {
+	p := Problem{
+		Severity: severity,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	if node != nil {
+		if tok := node.GetToken(); tok != nil && tok.Position != nil {
+			p.Line = tok.Position.Line
+			p.Column = tok.Position.Column
+		}
+	}
+	l.problems = append(l.problems, p)
+}
+
+func (l *linter) errorf(node ast.Node, path, format string, args ...any) {
+	l.add(SeverityError, node, path, format, args...)
+}
+
+func (l *linter) warnf(node ast.Node, path, format string, args ...any) {
+	l.add(SeverityWarning, node, path, format, args...)
+}
+
+func (l *linter) syntaxError(err error) {
+	p := Problem{Severity: SeverityError, Message: err.Error()}
+	var syntaxErr *yaml.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		p.Message = syntaxErr.Message
+		if tok := syntaxErr.Token; tok != nil && tok.Position != nil {
+			p.Line = tok.Position.Line
+			p.Column = tok.Position.Column
+		}
+	}
+	l.problems = append(l.problems, p)
+}
+
+// fields returns the values of a mapping by key and warns about keys that are not
+// in known. A node that is not a mapping is reported and yields nil.
+func (l *linter) fields(node ast.Node, path string, known map[string]bool) map[string]ast.Node {
+	node = unwrap(node)
+	mapping, ok := node.(ast.MapNode)
+	if !ok {
+		l.errorf(node, path, "expected a mapping, got %s", describeNode(node))
+		return nil
+	}
+
+	fields := make(map[string]ast.Node)
+	iter := mapping.MapRange()
+	for iter.Next() {
+		key, ok := scalarString(iter.Key())
+		if !ok {
+			key = iter.Key().String()
+		}
+		if _, ok := fields[key]; ok {
+			l.errorf(iter.Key(), joinPath(path, key), "duplicate field %q", key)
+			continue
+		}
+		if !known[key] {
+			l.warnf(iter.Key(), joinPath(path, key), "unknown field %q", key)
+		}
+		fields[key] = unwrap(iter.Value())
+	}
+	return fields
+}
+
+// sequence returns the elements of a sequence. Missing and null values yield nil
+// without a problem; anything else that is not a sequence is reported.
+func (l *linter) sequence(node ast.Node, path string) ([]ast.Node, bool) {
+	switch node := node.(type) {
+	case nil, *ast.NullNode:
+		return nil, true
+	case *ast.SequenceNode:
+		values := make([]ast.Node, len(node.Values))
+		for i, v := range node.Values {
+			values[i] = unwrap(v)
+		}
+		return values, true
+	default:
+		l.errorf(node, path, "expected a list, got %s", describeNode(node))
+		return nil, false
+	}
+}
+
+// requiredString reports a missing, empty or non-string field.
+func (l *linter) requiredString(fields map[string]ast.Node, parent ast.Node, path, key string) (string, bool) {
+	node, ok := fields[key]
+	if !ok || isNull(node) {
+		l.errorf(parent, path, "%s is required", key)
+		return "", false
+	}
+	s, ok := scalarString(node)
+	if !ok {
+		l.errorf(node, joinPath(path, key), "expected a string, got %s", describeNode(node))
+		return "", false
+	}
+	if strings.TrimSpace(s) == "" {
+		l.errorf(node, joinPath(path, key), "%s must not be empty", key)
+		return "", false
+	}
+	return s, true
+}
+
+func (l *linter) dashboard(node ast.Node) {
+	fields := l.fields(node, "", dashboardKeys)
+	if fields == nil {
+		return
+	}
+
+	if schema, ok := l.requiredString(fields, node, "", "schema"); ok && schema != SchemaV2 {
+		l.errorf(fields["schema"], "schema", "unsupported schema %q (supported: %s)", schema, SchemaV2)
+		return
+	}
+	l.requiredString(fields, node, "", "name")
+
+	if tableNode, ok := fields["table"]; ok {
+		items, _ := l.sequence(tableNode, "table")
+		for i, item := range items {
+			path := fmt.Sprintf("table[%d]", i)
+			if itemFields := l.fields(item, path, tableItemKeys); itemFields != nil {
+				l.metricsAndQuery(itemFields, item, path)
+			}
+		}
+	}
+
+	if itemsNode, ok := fields["table_grid_items"]; ok {
+		items, _ := l.sequence(itemsNode, "table_grid_items")
+		for i, item := range items {
+			l.gridItem(item, fmt.Sprintf("table_grid_items[%d]", i))
+		}
+	}
+
+	rowsNode, ok := fields["grid_rows"]
+	if !ok {
+		if _, ok := fields["table"]; !ok {
+			l.errorf(node, "", "grid_rows is required unless the dashboard has a table")
+		}
+		return
+	}
+	rows, _ := l.sequence(rowsNode, "grid_rows")
+	for i, row := range rows {
+		l.gridRow(row, fmt.Sprintf("grid_rows[%d]", i))
+	}
+}
+
+func (l *linter) gridRow(node ast.Node, path string) {
+	fields := l.fields(node, path, gridRowKeys)
+	if fields == nil {
+		return
+	}
+	l.requiredString(fields, node, path, "title")
+
+	itemsPath := joinPath(path, "items")
+	items, ok := l.sequence(fields["items"], itemsPath)
+	if ok && len(items) == 0 {
+		l.warnf(node, path, "row has no items")
+	}
+	// Items wrap by y_axis; the widths of the items on one line must fit the grid.
+	var lines []int
+	widths := make(map[int]int)
+	for i, item := range items {
+		width, y := l.gridItem(item, fmt.Sprintf("%s[%d]", itemsPath, i))
+		if _, ok := widths[y]; !ok {
+			lines = append(lines, y)
+		}
+		widths[y] += width
+	}
+	for _, y := range lines {
+		if widths[y] > GridColumns {
+			l.errorf(node, itemsPath, "items at y_axis %d are %d columns wide, more than %d",
+				y, widths[y], GridColumns)
+		}
+	}
+}
+
+// gridItem checks a grid item and returns its width and y_axis, or zero for
+// fields that are missing or invalid.
+func (l *linter) gridItem(node ast.Node, path string) (width, y int) {
+	fields := l.fields(node, path, gridItemKeys)
+	if fields == nil {
+		return 0, 0
+	}
+	l.requiredString(fields, node, path, "title")
+
+	if l.defaulted(fields, node, path, "type") {
+		if typ, ok := l.requiredString(fields, node, path, "type"); ok {
+			if err := uptraceapi.GridItemType(typ).Validate(); err != nil {
+				l.errorf(fields["type"], joinPath(path, "type"),
+					"unknown item type %q (supported: chart, table, heatmap, gauge)", typ)
+			}
+		}
+	}
+
+	if l.defaulted(fields, node, path, "width") {
+		if v, ok := l.intField(fields, node, path, "width"); ok {
+			if v < 1 || v > GridColumns {
+				l.errorf(fields["width"], joinPath(path, "width"),
+					"width must be between 1 and %d, got %d", GridColumns, v)
+			} else {
+				width = v
+			}
+		}
+	}
+	if l.defaulted(fields, node, path, "height") {
+		if height, ok := l.intField(fields, node, path, "height"); ok && height < 1 {
+			l.errorf(fields["height"], joinPath(path, "height"), "height must be positive, got %d", height)
+		}
+	}
+	for _, key := range []string{"x_axis", "y_axis"} {
+		if _, ok := fields[key]; !ok {
+			continue
+		}
+		v, ok := l.intField(fields, node, path, key)
+		if !ok {
+			continue
+		}
+		if v < 0 {
+			l.errorf(fields[key], joinPath(path, key), "%s must not be negative, got %d", key, v)
+		} else if key == "y_axis" {
+			y = v
+		}
+	}
+
+	l.metricsAndQuery(fields, node, path)
+	return width, y
+}
+
+// defaulted reports whether a field that Uptrace defaults is set and warns when
+// it is not. Dashboards exported by Uptrace leave out such fields.
+func (l *linter) defaulted(fields map[string]ast.Node, parent ast.Node, path, key string) bool {
+	if node, ok := fields[key]; ok && !isNull(node) {
+		return true
+	}
+	l.warnf(parent, path, "%s is not set, Uptrace uses its default", key)
+	return false
+}
+
+func (l *linter) intField(fields map[string]ast.Node, parent ast.Node, path, key string) (int, bool) {
+	node, ok := fields[key]
+	if !ok || isNull(node) {
+		l.errorf(parent, path, "%s is required", key)
+		return 0, false
+	}
+	n, ok := node.(*ast.IntegerNode)
+	if !ok {
+		l.errorf(node, joinPath(path, key), "expected an integer, got %s", describeNode(node))
+		return 0, false
+	}
+	switch v := n.Value.(type) {
+	case int64:
+		return int(v), true
+	case uint64:
+		return int(min(v, 1<<31)), true
+	default:
+		return 0, false
+	}
+}
+
+var (
+	metricLineRE = regexp.MustCompile(`^\s*(\S+)\s+as\s+(\$[a-zA-Z_][a-zA-Z0-9_]*)\s*$`)
+	metricRefRE  = regexp.MustCompile(`\$[a-zA-Z_][a-zA-Z0-9_]*`)
+)
+
+// metricsAndQuery checks that metrics declares "name as $alias" lines, that query is
+// present and that the query only uses declared aliases.
+func (l *linter) metricsAndQuery(fields map[string]ast.Node, parent ast.Node, path string) {
+	metricsPath := joinPath(path, "metrics")
+	metricNodes, ok := l.sequence(fields["metrics"], metricsPath)
+	if ok && len(metricNodes) == 0 {
+		l.errorf(parent, path, "metrics is required, e.g. - http.server.duration as $duration")
+	}
+
+	type declared struct {
+		node ast.Node
+		used bool
+	}
+	aliases := make(map[string]*declared)
+	var aliasOrder []string
+	for i, node := range metricNodes {
+		linePath := fmt.Sprintf("%s[%d]", metricsPath, i)
+		line, ok := scalarString(node)
+		if !ok {
+			l.errorf(node, linePath, "expected a string, got %s", describeNode(node))
+			continue
+		}
+		m := metricLineRE.FindStringSubmatch(line)
+		if m == nil {
+			l.errorf(node, linePath, "expected \"metric_name as $alias\", got %q", line)
+			continue
+		}
+		alias := m[2]
+		if _, ok := aliases[alias]; ok {
+			l.errorf(node, linePath, "alias %s is declared more than once", alias)
+			continue
+		}
+		aliases[alias] = &declared{node: node}
+		aliasOrder = append(aliasOrder, alias)
+	}
+
+	queryPath := joinPath(path, "query")
+	queryNodes, ok := l.sequence(fields["query"], queryPath)
+	if ok && len(queryNodes) == 0 {
+		l.errorf(parent, path, "query is required, e.g. - per_min(sum($alias))")
+	}
+	for i, node := range queryNodes {
+		linePath := fmt.Sprintf("%s[%d]", queryPath, i)
+		line, ok := scalarString(node)
+		if !ok {
+			l.errorf(node, linePath, "expected a string, got %s", describeNode(node))
+			continue
+		}
+
+		for _, ref := range metricRefRE.FindAllString(line, -1) {
+			if d, ok := aliases[ref]; ok {
+				d.used = true
+				continue
+			}
+			if len(metricNodes) > 0 {
+				l.errorf(node, linePath, "%s is not declared in metrics (declared: %s)",
+					ref, strings.Join(aliasOrder, ", "))
+			}
+		}
+
+		q, errs := uql.Parse(line)
+		for _, err := range errs {
+			l.warnf(node, linePath, "query may be invalid: %s", err.Msg)
+		}
+		if q != nil {
+			for _, w := range q.Warnings {
+				l.warnf(node, linePath, "%s", w.Msg)
+			}
+		}
+	}
+
+	if len(queryNodes) == 0 {
+		return
+	}
+	for _, alias := range aliasOrder {
+		if d := aliases[alias]; !d.used {
+			l.warnf(d.node, metricsPath, "%s is declared but not used in the query", alias)
+		}
+	}
+}
+
+func unwrap(node ast.Node) ast.Node {
+	for {
+		switch n := node.(type) {
+		case *ast.AnchorNode:
+			node = n.Value
+		case *ast.TagNode:
+			node = n.Value
+		default:
+			return node
+		}
+	}
+}
+
+func isNull(node ast.Node) bool {
+	_, ok := node.(*ast.NullNode)
+	return node == nil || ok
+}
+
+func scalarString(node ast.Node) (string, bool) {
+	switch n := node.(type) {
+	case *ast.StringNode:
+		return n.Value, true
+	case *ast.LiteralNode:
+		return n.Value.Value, true
+	default:
+		return "", false
+	}
+}
+
+func describeNode(node ast.Node) string {
+	switch node.(type) {
+	case nil, *ast.NullNode:
+		return "null"
+	case *ast.MappingNode, *ast.MappingValueNode:
+		return "a mapping"
+	case *ast.SequenceNode:
+		return "a list"
+	case *ast.StringNode, *ast.LiteralNode:
+		return "a string"
+	case *ast.IntegerNode, *ast.FloatNode:
+		return "a number"
+	case *ast.BoolNode:
+		return "a boolean"
+	default:
+		return strings.ToLower(node.Type().String())
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
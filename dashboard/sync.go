@@ -0,0 +1,464 @@
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/uptrace/mcp/textdiff"
+	"github.com/uptrace/mcp/uptraceapi"
+	"golang.org/x/sync/errgroup"
+)
+
+// Key returns the key that matches a YAML file to a dashboard in Uptrace: the
+// dashboard name in lower case with runs of other characters replaced by dashes.
+// Export writes each dashboard to <key>.yaml, so renaming a dashboard changes its
+// key and is planned as a delete and a create.
+func Key(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// Normalize re-encodes dashboard YAML with fields in the order of the schema,
+// unknown fields sorted by name and sequences indented, so that files exported
+// from Uptrace and edited by hand diff cleanly.
+func Normalize(data []byte) ([]byte, error) {
+	var v any
+	if err := yaml.UnmarshalWithOptions(data, &v, yaml.UseOrderedMap()); err != nil {
+		return nil, err
+	}
+	return yaml.MarshalWithOptions(sortKeys(v),
+		yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+}
+
+// keyRank orders known fields as they are declared in the model.
+var keyRank = func() map[string]int {
+	rank := make(map[string]int)
+	for _, typ := range []reflect.Type{
+		reflect.TypeFor[Dashboard](),
+		reflect.TypeFor[GridRow](),
+		reflect.TypeFor[GridItem](),
+		reflect.TypeFor[TableItem](),
+	} {
+		for i := 0; i < typ.NumField(); i++ {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("yaml"), ",")
+			if _, ok := rank[name]; !ok {
+				rank[name] = len(rank)
+			}
+		}
+	}
+	return rank
+}()
+
+func sortKeys(v any) any {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		for i := range v {
+			v[i].Value = sortKeys(v[i].Value)
+		}
+		sort.SliceStable(v, func(i, j int) bool {
+			ki, kj := fmt.Sprint(v[i].Key), fmt.Sprint(v[j].Key)
+			ri, iKnown := keyRank[ki]
+			rj, jKnown := keyRank[kj]
+			switch {
+			case iKnown && jKnown:
+				return ri < rj
+			case iKnown != jKnown:
+				return iKnown
+			default:
+				return ki < kj
+			}
+		})
+		return v
+	case []any:
+		for i := range v {
+			v[i] = sortKeys(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// LocalFile is a dashboard YAML file read from a directory.
+type LocalFile struct {
+	Path     string
+	Key      string
+	Name     string
+	YAML     []byte
+	Problems []Problem
+}
+
+// ReadDir reads the *.yaml and *.yml files in dir and lints them. Files are keyed
+// by the dashboard name inside them, not by the file name.
+func ReadDir(dir string) ([]*LocalFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*LocalFile
+	byKey := make(map[string]*LocalFile)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		file := &LocalFile{
+			Path:     path,
+			YAML:     data,
+			Problems: Lint(data),
+		}
+		var head struct {
+			Name string `yaml:"name"`
+		}
+		if err := yaml.Unmarshal(data, &head); err != nil || head.Name == "" {
+			file.Key = strings.TrimSuffix(entry.Name(), ext)
+		} else {
+			file.Name = head.Name
+			file.Key = Key(head.Name)
+		}
+
+		if other, ok := byKey[file.Key]; ok {
+			return nil, fmt.Errorf("%s and %s both define dashboard %q", other.Path, path, file.Key)
+		}
+		byKey[file.Key] = file
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// RemoteDashboard is a dashboard in Uptrace with its YAML definition.
+type RemoteDashboard struct {
+	ID   int64
+	Key  string
+	Name string
+	YAML []byte
+}
+
+// Syncer exports dashboards of a project to a directory and applies a directory
+// of YAML files back to the project.
+type Syncer struct {
+	client    *uptraceapi.Client
+	projectID int64
+}
+
+func NewSyncer(client *uptraceapi.Client, projectID int64) *Syncer {
+	return &Syncer{
+		client:    client,
+		projectID: projectID,
+	}
+}
+
+// Remote fetches all dashboards of the project with their YAML.
+func (s *Syncer) Remote(ctx context.Context) ([]*RemoteDashboard, error) {
+	resp, err := s.client.ListDashboards(ctx, &uptraceapi.ListDashboardsRequestOptions{
+		PathParams: &uptraceapi.ListDashboardsPath{ProjectID: s.projectID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dashboards := make([]*RemoteDashboard, len(resp.Dashboards))
+	byKey := make(map[string]*RemoteDashboard)
+	for i := range resp.Dashboards {
+		d := &resp.Dashboards[i]
+		dashboards[i] = &RemoteDashboard{
+			ID:   d.ID,
+			Key:  Key(d.Name),
+			Name: d.Name,
+		}
+		if other, ok := byKey[dashboards[i].Key]; ok {
+			return nil, fmt.Errorf("dashboards %d and %d both have the key %q; rename one of them in Uptrace",
+				other.ID, d.ID, other.Key)
+		}
+		byKey[dashboards[i].Key] = dashboards[i]
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(8)
+	for _, d := range dashboards {
+		g.Go(func() error {
+			body, err := s.client.GetDashboardYaml(gctx, &uptraceapi.GetDashboardYamlRequestOptions{
+				PathParams: &uptraceapi.GetDashboardYamlPath{ProjectID: s.projectID, DashboardID: d.ID},
+			})
+			if err != nil {
+				return fmt.Errorf("dashboard %d: %w", d.ID, err)
+			}
+			d.YAML = *body
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(dashboards, func(i, j int) bool { return dashboards[i].Key < dashboards[j].Key })
+	return dashboards, nil
+}
+
+// Export writes every dashboard of the project to dir as <key>.yaml and returns
+// the written paths. Other files in dir are left alone.
+func (s *Syncer) Export(ctx context.Context, dir string) ([]string, error) {
+	dashboards, err := s.Remote(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(dashboards))
+	for _, d := range dashboards {
+		data, err := Normalize(d.YAML)
+		if err != nil {
+			return nil, fmt.Errorf("dashboard %d: %w", d.ID, err)
+		}
+		path := filepath.Join(dir, d.Key+".yaml")
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Change is one planned create, update or delete.
+type Change struct {
+	Action Action
+	Key    string
+	Name   string
+	ID     int64  // zero for creates
+	Path   string // empty for deletes
+	Diff   string // unified diff from the remote to the local YAML
+
+	body []byte
+}
+
+// Plan is the set of changes that makes Uptrace match a directory.
+type Plan struct {
+	Changes   []*Change
+	Unchanged int
+	// Unmanaged are dashboards that only exist in Uptrace and are kept because
+	// pruning is disabled.
+	Unmanaged []*RemoteDashboard
+	// Invalid are local files with lint errors. A plan with invalid files can't be applied.
+	Invalid []*LocalFile
+}
+
+func (p *Plan) count(action Action) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary returns a one-line Terraform-style summary of the plan.
+func (p *Plan) Summary() string {
+	if len(p.Changes) == 0 {
+		return "No changes. Dashboards in Uptrace match the directory."
+	}
+	return fmt.Sprintf("Plan: %d to add, %d to change, %d to destroy.",
+		p.count(ActionCreate), p.count(ActionUpdate), p.count(ActionDelete))
+}
+
+// String renders the plan with a diff for every change.
+func (p *Plan) String() string {
+	var b strings.Builder
+	for _, file := range p.Invalid {
+		fmt.Fprintf(&b, "! %s is invalid:\n", file.Path)
+		for _, problem := range file.Problems {
+			if problem.Severity == SeverityError {
+				fmt.Fprintf(&b, "    %s\n", problem)
+			}
+		}
+		b.WriteByte('\n')
+	}
+	for _, c := range p.Changes {
+		switch c.Action {
+		case ActionCreate:
+			fmt.Fprintf(&b, "+ create %q from %s\n", c.Name, c.Path)
+		case ActionUpdate:
+			fmt.Fprintf(&b, "~ update %q (id %d) from %s\n", c.Name, c.ID, c.Path)
+		case ActionDelete:
+			fmt.Fprintf(&b, "- delete %q (id %d)\n", c.Name, c.ID)
+		}
+		if c.Diff != "" {
+			for _, line := range strings.SplitAfter(strings.TrimSuffix(c.Diff, "\n"), "\n") {
+				b.WriteString("    ")
+				b.WriteString(line)
+			}
+			b.WriteByte('\n')
+		}
+		b.WriteByte('\n')
+	}
+	if n := len(p.Unmanaged); n > 0 {
+		fmt.Fprintf(&b, "%d dashboard(s) exist only in Uptrace and are kept; use --prune to delete them.\n", n)
+	}
+	b.WriteString(p.Summary())
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// Plan compares the YAML files in dir with the dashboards in Uptrace. Dashboards
+// that have no file are deleted only when prune is set.
+func (s *Syncer) Plan(ctx context.Context, dir string, prune bool) (*Plan, error) {
+	files, err := ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := s.Remote(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildPlan(files, remote, prune)
+}
+
+func buildPlan(files []*LocalFile, remote []*RemoteDashboard, prune bool) (*Plan, error) {
+	plan := new(Plan)
+	remoteByKey := make(map[string]*RemoteDashboard, len(remote))
+	for _, d := range remote {
+		remoteByKey[d.Key] = d
+	}
+
+	seen := make(map[string]bool, len(files))
+	for _, file := range files {
+		seen[file.Key] = true
+		if HasErrors(file.Problems) {
+			plan.Invalid = append(plan.Invalid, file)
+			continue
+		}
+
+		local, err := Normalize(file.YAML)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Path, err)
+		}
+
+		d, ok := remoteByKey[file.Key]
+		if !ok {
+			plan.Changes = append(plan.Changes, &Change{
+				Action: ActionCreate,
+				Key:    file.Key,
+				Name:   file.Name,
+				Path:   file.Path,
+				Diff:   textdiff.Unified("/dev/null", file.Path, "", string(local)),
+				body:   file.YAML,
+			})
+			continue
+		}
+
+		current, err := Normalize(d.YAML)
+		if err != nil {
+			return nil, fmt.Errorf("dashboard %d: %w", d.ID, err)
+		}
+		if bytes.Equal(current, local) {
+			plan.Unchanged++
+			continue
+		}
+		plan.Changes = append(plan.Changes, &Change{
+			Action: ActionUpdate,
+			Key:    file.Key,
+			Name:   file.Name,
+			ID:     d.ID,
+			Path:   file.Path,
+			Diff:   textdiff.Unified(fmt.Sprintf("uptrace/%d", d.ID), file.Path, string(current), string(local)),
+			body:   file.YAML,
+		})
+	}
+
+	for _, d := range remote {
+		if seen[d.Key] {
+			continue
+		}
+		if !prune {
+			plan.Unmanaged = append(plan.Unmanaged, d)
+			continue
+		}
+		plan.Changes = append(plan.Changes, &Change{
+			Action: ActionDelete,
+			Key:    d.Key,
+			Name:   d.Name,
+			ID:     d.ID,
+		})
+	}
+
+	actionOrder := []Action{ActionCreate, ActionUpdate, ActionDelete}
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		a, b := plan.Changes[i], plan.Changes[j]
+		if a.Action != b.Action {
+			return slices.Index(actionOrder, a.Action) < slices.Index(actionOrder, b.Action)
+		}
+		return a.Key < b.Key
+	})
+	return plan, nil
+}
+
+// Apply makes the changes of the plan in order, calling done after each one. It
+// stops at the first error.
+func (s *Syncer) Apply(ctx context.Context, plan *Plan, done func(*Change)) error {
+	if len(plan.Invalid) > 0 {
+		return fmt.Errorf("%d file(s) have lint errors", len(plan.Invalid))
+	}
+
+	for _, c := range plan.Changes {
+		var err error
+		switch c.Action {
+		case ActionCreate:
+			_, err = s.client.CreateDashboardFromYaml(ctx, &uptraceapi.CreateDashboardFromYamlRequestOptions{
+				PathParams: &uptraceapi.CreateDashboardFromYamlPath{ProjectID: s.projectID},
+			}, RequestBody(c.body))
+		case ActionUpdate:
+			_, err = s.client.UpdateDashboardFromYaml(ctx, &uptraceapi.UpdateDashboardFromYamlRequestOptions{
+				PathParams: &uptraceapi.UpdateDashboardFromYamlPath{ProjectID: s.projectID, DashboardID: c.ID},
+			}, RequestBody(c.body))
+		case ActionDelete:
+			_, err = s.client.DeleteDashboard(ctx, &uptraceapi.DeleteDashboardRequestOptions{
+				PathParams: &uptraceapi.DeleteDashboardPath{ProjectID: s.projectID, DashboardID: c.ID},
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("%s %q: %w", c.Action, c.Name, err)
+		}
+		if done != nil {
+			done(c)
+		}
+	}
+	return nil
+}
@@ -7,12 +7,15 @@ require (
 	github.com/go-playground/validator/v10 v10.30.1
 	github.com/go-slog/otelslog v0.3.0
 	github.com/goccy/go-yaml v1.19.2
+	github.com/google/jsonschema-go v0.3.0
 	github.com/modelcontextprotocol/go-sdk v1.2.0
 	github.com/samber/slog-multi v1.7.0
 	github.com/uptrace/oapi-codegen-dd/v3 v3.71.5
 	github.com/urfave/cli/v3 v3.6.2
 	go.opentelemetry.io/contrib/bridges/otelslog v0.14.0
 	go.uber.org/fx v1.24.0
+	golang.org/x/image v0.30.0
+	golang.org/x/sync v0.19.0
 )
 
 require (
@@ -24,7 +27,6 @@ require (
 	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
-	github.com/google/jsonschema-go v0.3.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/iancoleman/strcase v0.3.0 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
@@ -50,7 +52,6 @@ require (
 	golang.org/x/crypto v0.48.0 // indirect
 	golang.org/x/mod v0.33.0 // indirect
 	golang.org/x/oauth2 v0.30.0 // indirect
-	golang.org/x/sync v0.19.0 // indirect
 	golang.org/x/sys v0.41.0 // indirect
 	golang.org/x/text v0.34.0 // indirect
 	golang.org/x/tools v0.42.0 // indirect
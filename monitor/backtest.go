@@ -0,0 +1,279 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+// EvalParams are the parts of a metric monitor that decide when it fires and
+// resolves.
+type EvalParams struct {
+	MinAllowedValue *float64
+	MaxAllowedValue *float64
+	// NumEvalPoints is the number of consecutive points outside the allowed range
+	// that fire an alert.
+	NumEvalPoints int
+	// FlappingMin and FlappingMax narrow the range a value must return to before a
+	// firing alert is resolved.
+	FlappingMin *float64
+	FlappingMax *float64
+	// NullsMode is allow (missing points are ignored), forbid (missing points count
+	// as outside the range) or convert (missing points are zero).
+	NullsMode string
+}
+
+// DefaultNumEvalPoints is used when EvalParams.NumEvalPoints is zero.
+const DefaultNumEvalPoints = 5
+
+// SimulatedAlert is an alert produced by Simulate. Indexes refer to the evaluated
+// values; Resolved is -1 when the alert is still firing at the end.
+type SimulatedAlert struct {
+	Fired    int
+	Resolved int
+	Peak     float64
+}
+
+// Simulate evaluates the monitor point by point over values the way the alerting
+// engine does: an alert fires after NumEvalPoints consecutive bad points and
+// resolves at the first point back within the (flapping) range.
+func Simulate(values []float64, p *EvalParams) []SimulatedAlert {
+	numEval := p.NumEvalPoints
+	if numEval <= 0 {
+		numEval = DefaultNumEvalPoints
+	}
+
+	var alerts []SimulatedAlert
+	var firing *SimulatedAlert
+	bad := 0
+	for i, v := range values {
+		if math.IsNaN(v) {
+			switch p.NullsMode {
+			case string(uptraceapi.Forbid):
+				// A missing point is a failed check; keep the previous peak.
+				if firing == nil {
+					bad++
+					if bad >= numEval {
+						alerts = append(alerts, SimulatedAlert{Fired: i, Resolved: -1, Peak: v})
+						firing = &alerts[len(alerts)-1]
+					}
+				}
+				continue
+			case string(uptraceapi.Convert):
+				v = 0
+			default:
+				continue
+			}
+		}
+
+		if firing != nil {
+			if p.resolves(v) {
+				firing.Resolved = i
+				firing = nil
+				bad = 0
+				continue
+			}
+			if worse(firing.Peak, v, p) {
+				firing.Peak = v
+			}
+			continue
+		}
+
+		if !p.outside(v) {
+			bad = 0
+			continue
+		}
+		bad++
+		if bad >= numEval {
+			alerts = append(alerts, SimulatedAlert{Fired: i, Resolved: -1, Peak: v})
+			firing = &alerts[len(alerts)-1]
+		}
+	}
+	return alerts
+}
+
+func (p *EvalParams) outside(v float64) bool {
+	return (p.MinAllowedValue != nil && v < *p.MinAllowedValue) ||
+		(p.MaxAllowedValue != nil && v > *p.MaxAllowedValue)
+}
+
+func (p *EvalParams) resolves(v float64) bool {
+	if p.outside(v) {
+		return false
+	}
+	return (p.FlappingMin == nil || v >= *p.FlappingMin) &&
+		(p.FlappingMax == nil || v <= *p.FlappingMax)
+}
+
+// worse reports whether v is further outside the allowed range than peak.
+func worse(peak, v float64, p *EvalParams) bool {
+	if math.IsNaN(peak) {
+		return true
+	}
+	if p.MaxAllowedValue != nil && v > *p.MaxAllowedValue {
+		return v > peak
+	}
+	if p.MinAllowedValue != nil && v < *p.MinAllowedValue {
+		return v < peak
+	}
+	return false
+}
+
+// Bound selects the threshold SuggestThreshold tunes.
+type Bound int
+
+const (
+	BoundMax Bound = iota
+	BoundMin
+)
+
+// SuggestThreshold finds the tightest value of one bound for which the monitor
+// would have fired at most maxAlerts times over the series. The flapping bound on
+// the same side keeps its distance from the threshold. It returns false when no
+// observed value achieves the target.
+func SuggestThreshold(series [][]float64, p *EvalParams, bound Bound, maxAlerts int) (float64, int, bool) {
+	var candidates []float64
+	for _, values := range series {
+		for _, v := range values {
+			if !math.IsNaN(v) {
+				candidates = append(candidates, v)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return 0, 0, false
+	}
+	sort.Float64s(candidates)
+	candidates = slices.Compact(candidates)
+	if bound == BoundMin {
+		slices.Reverse(candidates)
+	}
+
+	count := func(threshold float64) int {
+		q := *p
+		switch bound {
+		case BoundMax:
+			q.MaxAllowedValue = &threshold
+			if p.MaxAllowedValue != nil && p.FlappingMax != nil {
+				flapping := threshold - (*p.MaxAllowedValue - *p.FlappingMax)
+				q.FlappingMax = &flapping
+			}
+		case BoundMin:
+			q.MinAllowedValue = &threshold
+			if p.MinAllowedValue != nil && p.FlappingMin != nil {
+				flapping := threshold + (*p.FlappingMin - *p.MinAllowedValue)
+				q.FlappingMin = &flapping
+			}
+		}
+		n := 0
+		for _, values := range series {
+			n += len(Simulate(values, &q))
+		}
+		return n
+	}
+
+	// Alert counts shrink as the threshold moves towards the extreme values, so
+	// binary search for the first candidate that meets the target.
+	i := sort.Search(len(candidates), func(i int) bool {
+		return count(candidates[i]) <= maxAlerts
+	})
+	if i == len(candidates) {
+		return 0, 0, false
+	}
+	return candidates[i], count(candidates[i]), true
+}
+
+// spanMetrics are the metrics Uptrace derives from spans and logs, mapped to the
+// system QueryTimeseries needs to read the same data.
+var spanMetrics = map[string][]string{
+	"uptrace_tracing_spans": nil,
+	"uptrace_tracing_logs":  {"log:all"},
+}
+
+var (
+	aliasCallRE = regexp.MustCompile(`(\w+)\(\s*(\$\w+)(?:\{([^}]*)\})?\s*\)`)
+	filterRE    = regexp.MustCompile(`^\s*([\w.]+)\s*(!=|=)\s*("[^"]*"|[^\s"]+)\s*$`)
+)
+
+// SpanQuery rewrites a metric monitor query over uptrace_tracing_spans or
+// uptrace_tracing_logs into the equivalent span query, e.g.
+// per_min(count($spans{_status_code="error"})) becomes
+// per_min(count()) | where _status_code = "error". Other metrics are only stored
+// as metrics and can't be replayed with QueryTimeseries.
+func SpanQuery(metrics []string, query string) (string, []string, error) {
+	if len(metrics) != 1 {
+		return "", nil, fmt.Errorf("backtesting needs exactly one metric, got %d", len(metrics))
+	}
+	metric, err := parseMetric(metrics[0])
+	if err != nil {
+		return "", nil, err
+	}
+	system, ok := spanMetrics[metric.Name]
+	if !ok {
+		return "", nil, fmt.Errorf(
+			"can't backtest %s: only uptrace_tracing_spans and uptrace_tracing_logs can be replayed from span data",
+			metric.Name)
+	}
+	alias := deref(metric.Alias)
+	if alias == "" {
+		return "", nil, fmt.Errorf("metric %s needs an alias, e.g. %s as $spans", metric.Name, metric.Name)
+	}
+
+	var filter string
+	var errs []error
+	query = aliasCallRE.ReplaceAllStringFunc(query, func(s string) string {
+		m := aliasCallRE.FindStringSubmatch(s)
+		fn, ref, cond := m[1], m[2], m[3]
+		if ref != alias {
+			errs = append(errs, fmt.Errorf("%s is not declared in metrics", ref))
+			return s
+		}
+		if cond != "" {
+			where, err := filterWhere(cond)
+			if err != nil {
+				errs = append(errs, err)
+			} else if filter != "" && where != filter {
+				errs = append(errs, fmt.Errorf("aggregations with different filters can't be backtested"))
+			}
+			filter = where
+		}
+		if fn == "count" {
+			return "count()"
+		}
+		return fn + "(_duration)"
+	})
+	if err := errors.Join(errs...); err != nil {
+		return "", nil, err
+	}
+	if strings.Contains(query, "$") {
+		return "", nil, fmt.Errorf("unsupported use of %s in query %q", alias, query)
+	}
+	if filter != "" {
+		query += " | " + filter
+	}
+	return query, system, nil
+}
+
+// filterWhere converts a metric filter such as _status_code="error", a!="b" to a
+// where clause.
+func filterWhere(cond string) (string, error) {
+	var b strings.Builder
+	b.WriteString("where ")
+	for i, part := range strings.Split(cond, ",") {
+		m := filterRE.FindStringSubmatch(part)
+		if m == nil {
+			return "", fmt.Errorf("unsupported filter %q (supported: attr = value, attr != value)", part)
+		}
+		if i > 0 {
+			b.WriteString(" and ")
+		}
+		fmt.Fprintf(&b, "%s %s %s", m[1], m[2], m[3])
+	}
+	return b.String(), nil
+}
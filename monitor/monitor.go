@@ -0,0 +1,500 @@
+// Package monitor models Uptrace monitors as YAML files and converts them to and
+// from the monitors API. Durations are written as Go durations instead of
+// milliseconds and notification channels are referenced by name.
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/uptrace/mcp/uptraceapi"
+	"github.com/uptrace/oapi-codegen-dd/v3/pkg/runtime"
+)
+
+const (
+	TypeMetric = "metric"
+	TypeError  = "error"
+)
+
+// Monitor is a metric or error monitor. Fields below Query only apply to metric
+// monitors.
+type Monitor struct {
+	Name                  string          `yaml:"name" json:"name"`
+	Type                  string          `yaml:"type" json:"type"`
+	NotifyEveryoneByEmail bool            `yaml:"notify_everyone_by_email,omitempty" json:"notify_everyone_by_email,omitempty"`
+	TeamIDs               []int           `yaml:"team_ids,omitempty" json:"team_ids,omitempty"`
+	Channels              []string        `yaml:"channels,omitempty" json:"channels,omitempty"`
+	RepeatInterval        *RepeatInterval `yaml:"repeat_interval,omitempty" json:"repeat_interval,omitempty"`
+
+	// Metrics are "metric_name as $alias" lines, as in dashboards.
+	Metrics []string `yaml:"metrics" json:"metrics"`
+	Query   string   `yaml:"query" json:"query"`
+
+	ColumnName      string        `yaml:"column_name,omitempty" json:"column_name,omitempty"`
+	ColumnUnit      string        `yaml:"column_unit,omitempty" json:"column_unit,omitempty"`
+	Resolution      time.Duration `yaml:"resolution,omitempty" json:"resolution,omitempty"`
+	NumEvalPoints   int           `yaml:"num_eval_points,omitempty" json:"num_eval_points,omitempty"`
+	MinAllowedValue *float32      `yaml:"min_allowed_value,omitempty" json:"min_allowed_value,omitempty"`
+	MaxAllowedValue *float32      `yaml:"max_allowed_value,omitempty" json:"max_allowed_value,omitempty"`
+	Flapping        *Flapping     `yaml:"flapping,omitempty" json:"flapping,omitempty"`
+	NullsMode       string        `yaml:"nulls_mode,omitempty" json:"nulls_mode,omitempty"`
+	TimeOffset      time.Duration `yaml:"time_offset,omitempty" json:"time_offset,omitempty"`
+	BoundsSource    string        `yaml:"bounds_source,omitempty" json:"bounds_source,omitempty"`
+	Tolerance       string        `yaml:"tolerance,omitempty" json:"tolerance,omitempty"`
+	TrainingPeriod  time.Duration `yaml:"training_period,omitempty" json:"training_period,omitempty"`
+	MinDevFraction  *float32      `yaml:"min_dev_fraction,omitempty" json:"min_dev_fraction,omitempty"`
+	MinDevValue     *float32      `yaml:"min_dev_value,omitempty" json:"min_dev_value,omitempty"`
+}
+
+// Flapping widens the allowed range for resolving an alert so that a value hovering
+// around the threshold doesn't fire and resolve repeatedly.
+type Flapping struct {
+	MinAllowedValue *float32 `yaml:"min_allowed_value,omitempty" json:"min_allowed_value,omitempty"`
+	MaxAllowedValue *float32 `yaml:"max_allowed_value,omitempty" json:"max_allowed_value,omitempty"`
+}
+
+// RepeatInterval controls how often notifications repeat while an alert is firing.
+// Fixed uses Interval; linear and exponential back off from Min to Max.
+type RepeatInterval struct {
+	Strategy string        `yaml:"strategy" json:"strategy"`
+	Interval time.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Min      time.Duration `yaml:"min,omitempty" json:"min,omitempty"`
+	Max      time.Duration `yaml:"max,omitempty" json:"max,omitempty"`
+}
+
+const (
+	StrategyDefault     = "default"
+	StrategyFixed       = "fixed"
+	StrategyLinear      = "linear"
+	StrategyExponential = "exponential"
+)
+
+// Parse decodes one monitor from YAML, rejecting unknown fields.
+func Parse(data []byte) (*Monitor, error) {
+	file, err := parser.ParseBytes(data, 0)
+	if err != nil {
+		return nil, errors.New(yaml.FormatError(err, false, true))
+	}
+	if len(file.Docs) != 1 || file.Docs[0].Body == nil {
+		return nil, fmt.Errorf("expected one YAML document, got %d", len(file.Docs))
+	}
+	return parseNode(file.Docs[0].Body)
+}
+
+func parseNode(node ast.Node) (*Monitor, error) {
+	m := new(Monitor)
+	if err := yaml.NodeToValue(node, m, yaml.DisallowUnknownField()); err != nil {
+		return nil, errors.New(yaml.FormatError(err, false, true))
+	}
+	return m, nil
+}
+
+// YAML encodes the monitor the way export writes it.
+func (m *Monitor) YAML() ([]byte, error) {
+	return yaml.MarshalWithOptions(m, yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+}
+
+// Validate checks the monitor without calling the API.
+func (m *Monitor) Validate() error {
+	var errs []error
+	if strings.TrimSpace(m.Name) == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+	switch m.Type {
+	case TypeMetric:
+	case TypeError:
+		if m.hasMetricParams() {
+			errs = append(errs, errors.New("thresholds, flapping and other metric params only apply to metric monitors"))
+		}
+	case "":
+		errs = append(errs, errors.New("type is required (metric or error)"))
+	default:
+		errs = append(errs, fmt.Errorf("unknown type %q (supported: metric, error)", m.Type))
+	}
+
+	if len(m.Metrics) == 0 {
+		errs = append(errs, errors.New("metrics is required, e.g. - uptrace_tracing_spans as $spans"))
+	}
+	for _, line := range m.Metrics {
+		if _, err := parseMetric(line); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if strings.TrimSpace(m.Query) == "" {
+		errs = append(errs, errors.New("query is required"))
+	}
+
+	if m.Type == TypeMetric {
+		if m.MinAllowedValue == nil && m.MaxAllowedValue == nil && m.BoundsSource != string(uptraceapi.Auto) {
+			errs = append(errs, errors.New("min_allowed_value or max_allowed_value is required unless bounds_source is auto"))
+		}
+		if m.MinAllowedValue != nil && m.MaxAllowedValue != nil && *m.MinAllowedValue > *m.MaxAllowedValue {
+			errs = append(errs, errors.New("min_allowed_value is greater than max_allowed_value"))
+		}
+		if m.NumEvalPoints < 0 {
+			errs = append(errs, errors.New("num_eval_points must not be negative"))
+		}
+		if m.NullsMode != "" {
+			if err := uptraceapi.MetricMonitorParamsNullsMode(m.NullsMode).Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("unknown nulls_mode %q (supported: allow, forbid, convert)", m.NullsMode))
+			}
+		}
+		if m.BoundsSource != "" {
+			if err := uptraceapi.MetricMonitorParamsBoundsSource(m.BoundsSource).Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("unknown bounds_source %q (supported: auto, manual)", m.BoundsSource))
+			}
+		}
+		if m.Tolerance != "" {
+			if err := uptraceapi.MetricMonitorParamsTolerance(m.Tolerance).Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("unknown tolerance %q (supported: low, medium, high)", m.Tolerance))
+			}
+		}
+	}
+
+	if ri := m.RepeatInterval; ri != nil {
+		switch ri.Strategy {
+		case StrategyDefault:
+		case StrategyFixed:
+			if ri.Interval <= 0 {
+				errs = append(errs, errors.New("repeat_interval.interval is required for the fixed strategy"))
+			}
+		case StrategyLinear, StrategyExponential:
+			if ri.Min <= 0 || ri.Max <= 0 {
+				errs = append(errs, fmt.Errorf("repeat_interval.min and max are required for the %s strategy", ri.Strategy))
+			} else if ri.Min > ri.Max {
+				errs = append(errs, errors.New("repeat_interval.min is greater than max"))
+			}
+		default:
+			errs = append(errs, fmt.Errorf(
+				"unknown repeat_interval.strategy %q (supported: default, fixed, linear, exponential)", ri.Strategy))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Monitor) hasMetricParams() bool {
+	return m.ColumnName != "" || m.ColumnUnit != "" || m.Resolution != 0 || m.NumEvalPoints != 0 ||
+		m.MinAllowedValue != nil || m.MaxAllowedValue != nil || m.Flapping != nil || m.NullsMode != "" ||
+		m.TimeOffset != 0 || m.BoundsSource != "" || m.Tolerance != "" || m.TrainingPeriod != 0 ||
+		m.MinDevFraction != nil || m.MinDevValue != nil
+}
+
+var metricLineRE = regexp.MustCompile(`^\s*(\S+)(?:\s+as\s+(\S+))?\s*$`)
+
+func parseMetric(line string) (uptraceapi.MonitorMetric, error) {
+	m := metricLineRE.FindStringSubmatch(line)
+	if m == nil {
+		return uptraceapi.MonitorMetric{}, fmt.Errorf("expected \"metric_name as $alias\", got %q", line)
+	}
+	metric := uptraceapi.MonitorMetric{Name: m[1]}
+	if m[2] != "" {
+		metric.Alias = &m[2]
+	}
+	return metric, nil
+}
+
+func formatMetric(m uptraceapi.MonitorMetric) string {
+	if m.Alias == nil || *m.Alias == "" {
+		return m.Name
+	}
+	return m.Name + " as " + *m.Alias
+}
+
+// Channels maps notification channel names to IDs and back.
+type Channels struct {
+	byID   map[int64]string
+	byName map[string]int64
+}
+
+func NewChannels(channels []uptraceapi.NotificationChannel) *Channels {
+	c := &Channels{
+		byID:   make(map[int64]string, len(channels)),
+		byName: make(map[string]int64, len(channels)),
+	}
+	for _, ch := range channels {
+		c.byID[ch.ID] = ch.Name
+		c.byName[ch.Name] = ch.ID
+	}
+	return c
+}
+
+func (c *Channels) ids(names []string) ([]int, error) {
+	var ids []int
+	for _, name := range names {
+		id, ok := c.byName[name]
+		if !ok {
+			known := make([]string, 0, len(c.byName))
+			for name := range c.byName {
+				known = append(known, name)
+			}
+			slices.Sort(known)
+			return nil, fmt.Errorf("unknown notification channel %q (known: %s)", name, strings.Join(known, ", "))
+		}
+		ids = append(ids, int(id))
+	}
+	return ids, nil
+}
+
+// names resolves channel IDs to sorted names, leaving out IDs of channels that
+// no longer exist.
+func (c *Channels) names(ids []int) []string {
+	var names []string
+	for _, id := range ids {
+		if name, ok := c.byID[int64(id)]; ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Missing returns the IDs of channels that no longer exist.
+func (c *Channels) Missing(ids []int) []int {
+	var missing []int
+	for _, id := range ids {
+		if _, ok := c.byID[int64(id)]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// FromAPI converts a monitor returned by the API, resolving channel IDs to names.
+// IDs of channels that no longer exist are left out; see Channels.Missing.
+func FromAPI(src *uptraceapi.Monitor, channels *Channels) (*Monitor, error) {
+	m := &Monitor{
+		Name:    src.Name,
+		Type:    string(src.Type),
+		TeamIDs: slices.Clone(src.TeamIds),
+	}
+	if src.NotifyEveryoneByEmail != nil {
+		m.NotifyEveryoneByEmail = *src.NotifyEveryoneByEmail
+	}
+	m.Channels = channels.names(src.ChannelIds)
+	var err error
+	if src.RepeatInterval != nil && src.RepeatInterval.RepeatInterval_OneOf != nil {
+		if m.RepeatInterval, err = repeatIntervalFromAPI(src.RepeatInterval.RepeatInterval_OneOf.Raw()); err != nil {
+			return nil, err
+		}
+	}
+
+	params, err := json.Marshal(src.Params)
+	if err != nil {
+		return nil, err
+	}
+	if src.Type == uptraceapi.MonitorTypeError {
+		var p uptraceapi.ErrorMonitorParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, fmt.Errorf("monitor %d params: %w", src.ID, err)
+		}
+		m.setMetrics(p.Metrics)
+		m.Query = p.Query
+		return m, nil
+	}
+
+	var p uptraceapi.MetricMonitorParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("monitor %d params: %w", src.ID, err)
+	}
+	m.setMetrics(p.Metrics)
+	m.Query = p.Query
+	m.ColumnName = deref(p.ColumnName)
+	m.ColumnUnit = deref(p.ColumnUnit)
+	m.Resolution = millis(p.Resolution)
+	m.NumEvalPoints = deref(p.NumEvalPoints)
+	m.MinAllowedValue = p.MinAllowedValue
+	m.MaxAllowedValue = p.MaxAllowedValue
+	if p.Flapping != nil && (p.Flapping.MinAllowedValue != nil || p.Flapping.MaxAllowedValue != nil) {
+		m.Flapping = &Flapping{
+			MinAllowedValue: p.Flapping.MinAllowedValue,
+			MaxAllowedValue: p.Flapping.MaxAllowedValue,
+		}
+	}
+	m.NullsMode = string(deref(p.NullsMode))
+	m.TimeOffset = millis(p.TimeOffset)
+	m.BoundsSource = string(deref(p.BoundsSource))
+	m.Tolerance = string(deref(p.Tolerance))
+	m.TrainingPeriod = millis(p.TrainingPeriod)
+	m.MinDevFraction = p.MinDevFraction
+	m.MinDevValue = p.MinDevValue
+	return m, nil
+}
+
+func (m *Monitor) setMetrics(metrics []uptraceapi.MonitorMetric) {
+	m.Metrics = make([]string, len(metrics))
+	for i, metric := range metrics {
+		m.Metrics[i] = formatMetric(metric)
+	}
+}
+
+func repeatIntervalFromAPI(raw json.RawMessage) (*RepeatInterval, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var v struct {
+		Strategy string   `json:"strategy"`
+		Interval *float32 `json:"interval"`
+		Min      *float32 `json:"min"`
+		Max      *float32 `json:"max"`
+	}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, fmt.Errorf("repeat interval: %w", err)
+	}
+	if v.Strategy == "" {
+		v.Strategy = StrategyDefault
+	}
+	return &RepeatInterval{
+		Strategy: v.Strategy,
+		Interval: millis(v.Interval),
+		Min:      millis(v.Min),
+		Max:      millis(v.Max),
+	}, nil
+}
+
+// request converts the monitor to the union accepted by the create and update
+// endpoints, resolving channel names to IDs.
+func (m *Monitor) request(
+	channels *Channels,
+) (runtime.Either[uptraceapi.MetricMonitorRequest, uptraceapi.ErrorMonitorRequest], error) {
+	var zero runtime.Either[uptraceapi.MetricMonitorRequest, uptraceapi.ErrorMonitorRequest]
+
+	channelIDs, err := channels.ids(m.Channels)
+	if err != nil {
+		return zero, err
+	}
+	repeatInterval, err := m.repeatIntervalRequest()
+	if err != nil {
+		return zero, err
+	}
+	metrics := make([]uptraceapi.MonitorMetric, len(m.Metrics))
+	for i, line := range m.Metrics {
+		if metrics[i], err = parseMetric(line); err != nil {
+			return zero, err
+		}
+	}
+	var notifyEveryone *bool
+	if m.NotifyEveryoneByEmail {
+		notifyEveryone = &m.NotifyEveryoneByEmail
+	}
+
+	if m.Type == TypeError {
+		return runtime.NewEitherFromB[uptraceapi.MetricMonitorRequest](uptraceapi.ErrorMonitorRequest{
+			Name:                  m.Name,
+			NotifyEveryoneByEmail: notifyEveryone,
+			TeamIds:               m.TeamIDs,
+			ChannelIds:            channelIDs,
+			RepeatInterval:        repeatInterval,
+			Type:                  uptraceapi.ErrorMonitorRequestTypeError,
+			Params:                uptraceapi.ErrorMonitorParams{Metrics: metrics, Query: m.Query},
+		}), nil
+	}
+
+	params := uptraceapi.MetricMonitorParams{
+		Metrics:         metrics,
+		Query:           m.Query,
+		ColumnName:      ptrOrNil(m.ColumnName),
+		ColumnUnit:      ptrOrNil(m.ColumnUnit),
+		Resolution:      toMillis(m.Resolution),
+		NumEvalPoints:   ptrOrNil(m.NumEvalPoints),
+		MinAllowedValue: m.MinAllowedValue,
+		MaxAllowedValue: m.MaxAllowedValue,
+		NullsMode:       ptrOrNil(uptraceapi.MetricMonitorParamsNullsMode(m.NullsMode)),
+		TimeOffset:      toMillis(m.TimeOffset),
+		BoundsSource:    ptrOrNil(uptraceapi.MetricMonitorParamsBoundsSource(m.BoundsSource)),
+		Tolerance:       ptrOrNil(uptraceapi.MetricMonitorParamsTolerance(m.Tolerance)),
+		TrainingPeriod:  toMillis(m.TrainingPeriod),
+		MinDevFraction:  m.MinDevFraction,
+		MinDevValue:     m.MinDevValue,
+	}
+	if m.Flapping != nil {
+		params.Flapping = &uptraceapi.FlappingParams{
+			MinAllowedValue: m.Flapping.MinAllowedValue,
+			MaxAllowedValue: m.Flapping.MaxAllowedValue,
+		}
+	}
+	return runtime.NewEitherFromA[uptraceapi.MetricMonitorRequest, uptraceapi.ErrorMonitorRequest](
+		uptraceapi.MetricMonitorRequest{
+			Name:                  m.Name,
+			NotifyEveryoneByEmail: notifyEveryone,
+			TeamIds:               m.TeamIDs,
+			ChannelIds:            channelIDs,
+			RepeatInterval:        repeatInterval,
+			Type:                  uptraceapi.Metric,
+			Params:                params,
+		}), nil
+}
+
+func (m *Monitor) repeatIntervalRequest() (*uptraceapi.RepeatInterval, error) {
+	ri := m.RepeatInterval
+	if ri == nil {
+		return nil, nil
+	}
+
+	union := new(uptraceapi.RepeatInterval_OneOf)
+	var err error
+	switch ri.Strategy {
+	case StrategyFixed:
+		err = union.FromFixedRepeatInterval(uptraceapi.FixedRepeatInterval{
+			Strategy: uptraceapi.Fixed,
+			Interval: *toMillis(ri.Interval),
+		})
+	case StrategyLinear:
+		err = union.FromLinearRepeatInterval(uptraceapi.LinearRepeatInterval{
+			Strategy: uptraceapi.Linear,
+			Min:      *toMillis(ri.Min),
+			Max:      *toMillis(ri.Max),
+		})
+	case StrategyExponential:
+		err = union.FromExponentialRepeatInterval(uptraceapi.ExponentialRepeatInterval{
+			Strategy: uptraceapi.Exponential,
+			Min:      *toMillis(ri.Min),
+			Max:      *toMillis(ri.Max),
+		})
+	default:
+		strategy := uptraceapi.Default
+		err = union.FromDefaultRepeatInterval(uptraceapi.DefaultRepeatInterval{Strategy: &strategy})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repeat_interval: %w", err)
+	}
+	return &uptraceapi.RepeatInterval{RepeatInterval_OneOf: union}, nil
+}
+
+func millis(ms *float32) time.Duration {
+	if ms == nil {
+		return 0
+	}
+	return time.Duration(float64(*ms) * float64(time.Millisecond))
+}
+
+func toMillis(d time.Duration) *float32 {
+	if d == 0 {
+		return nil
+	}
+	ms := float32(d.Milliseconds())
+	return &ms
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
+
+func ptrOrNil[T comparable](v T) *T {
+	var zero T
+	if v == zero {
+		return nil
+	}
+	return &v
+}
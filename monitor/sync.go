@@ -0,0 +1,466 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/uptrace/mcp/dashboard"
+	"github.com/uptrace/mcp/textdiff"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+// Key returns the key that matches a YAML file to a monitor in Uptrace. It is
+// derived from the name the same way as dashboard keys.
+func Key(name string) string {
+	return dashboard.Key(name)
+}
+
+// LocalFile is a monitor definition read from a file. Err is set when the file
+// can't be parsed or fails validation.
+type LocalFile struct {
+	Path    string
+	Key     string
+	Monitor *Monitor
+	Err     error
+}
+
+// ParseFile parses the YAML documents in data, one monitor per document.
+func ParseFile(path string, data []byte) []*LocalFile {
+	file, err := parser.ParseBytes(data, 0)
+	if err != nil {
+		return []*LocalFile{{
+			Path: path,
+			Key:  strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+			Err:  errors.New(yaml.FormatError(err, false, true)),
+		}}
+	}
+
+	var files []*LocalFile
+	for _, doc := range file.Docs {
+		if doc.Body == nil {
+			continue
+		}
+		local := &LocalFile{Path: path}
+		if local.Monitor, local.Err = parseNode(doc.Body); local.Err == nil {
+			local.Err = local.Monitor.Validate()
+		}
+		if local.Monitor != nil && local.Monitor.Name != "" {
+			local.Key = Key(local.Monitor.Name)
+		} else {
+			local.Key = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		files = append(files, local)
+	}
+	if len(files) > 1 {
+		for i, f := range files {
+			f.Path = fmt.Sprintf("%s#%d", path, i+1)
+		}
+	}
+	return files
+}
+
+// ReadDir reads the monitors defined in the *.yaml and *.yml files in dir.
+func ReadDir(dir string) ([]*LocalFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*LocalFile
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, ParseFile(path, data)...)
+	}
+	return files, nil
+}
+
+// RemoteMonitor is a monitor in Uptrace converted to the YAML model.
+type RemoteMonitor struct {
+	ID      int64
+	Key     string
+	Monitor *Monitor
+	// MissingChannels are IDs of deleted notification channels that the
+	// monitor still references. They are left out of Monitor.
+	MissingChannels []int
+}
+
+// warning describes what is wrong with the monitor in Uptrace, if anything.
+func (m *RemoteMonitor) warning() string {
+	if len(m.MissingChannels) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("monitor %q (id %d) references notification channels %v that no longer exist; they are left out",
+		m.Monitor.Name, m.ID, m.MissingChannels)
+}
+
+// Syncer exports monitors of a project to a directory and applies monitor
+// definitions back to the project.
+type Syncer struct {
+	client    *uptraceapi.Client
+	projectID int64
+}
+
+func NewSyncer(client *uptraceapi.Client, projectID int64) *Syncer {
+	return &Syncer{
+		client:    client,
+		projectID: projectID,
+	}
+}
+
+// Channels fetches the notification channels of the project.
+func (s *Syncer) Channels(ctx context.Context) (*Channels, error) {
+	resp, err := s.client.ListNotificationChannels(ctx, &uptraceapi.ListNotificationChannelsRequestOptions{
+		PathParams: &uptraceapi.ListNotificationChannelsPath{ProjectID: s.projectID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewChannels(resp.Channels), nil
+}
+
+// Remote fetches all monitors of the project.
+func (s *Syncer) Remote(ctx context.Context, channels *Channels) ([]*RemoteMonitor, error) {
+	resp, err := s.client.ListMonitors(ctx, &uptraceapi.ListMonitorsRequestOptions{
+		PathParams: &uptraceapi.ListMonitorsPath{ProjectID: s.projectID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	monitors := make([]*RemoteMonitor, 0, len(resp.Monitors))
+	byKey := make(map[string]*RemoteMonitor)
+	for i := range resp.Monitors {
+		src := &resp.Monitors[i]
+		m, err := FromAPI(src, channels)
+		if err != nil {
+			return nil, fmt.Errorf("monitor %d: %w", src.ID, err)
+		}
+		remote := &RemoteMonitor{
+			ID:              src.ID,
+			Key:             Key(src.Name),
+			Monitor:         m,
+			MissingChannels: channels.Missing(src.ChannelIds),
+		}
+		if other, ok := byKey[remote.Key]; ok {
+			return nil, fmt.Errorf("monitors %d and %d both have the key %q; rename one of them in Uptrace",
+				other.ID, remote.ID, remote.Key)
+		}
+		byKey[remote.Key] = remote
+		monitors = append(monitors, remote)
+	}
+	sort.Slice(monitors, func(i, j int) bool { return monitors[i].Key < monitors[j].Key })
+	return monitors, nil
+}
+
+// Export writes every monitor of the project to dir as <key>.yaml and returns the
+// written paths and warnings about monitors that reference deleted channels.
+// Other files in dir are left alone.
+func (s *Syncer) Export(ctx context.Context, dir string) ([]string, []string, error) {
+	channels, err := s.Channels(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	monitors, err := s.Remote(ctx, channels)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, nil, err
+	}
+
+	paths := make([]string, 0, len(monitors))
+	var warnings []string
+	for _, m := range monitors {
+		data, err := m.Monitor.YAML()
+		if err != nil {
+			return nil, nil, err
+		}
+		path := filepath.Join(dir, m.Key+".yaml")
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return nil, nil, err
+		}
+		paths = append(paths, path)
+		if w := m.warning(); w != "" {
+			warnings = append(warnings, w)
+		}
+	}
+	return paths, warnings, nil
+}
+
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Change is one planned create, update or delete.
+type Change struct {
+	Action Action `json:"action"`
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	ID     int64  `json:"id,omitempty" jsonschema:"Monitor ID, zero for creates."`
+	Path   string `json:"path,omitempty" jsonschema:"File the monitor is defined in, empty for deletes."`
+	Diff   string `json:"diff,omitempty" jsonschema:"Unified diff from the monitor in Uptrace to the definition."`
+
+	monitor *Monitor
+}
+
+// Invalid is a monitor definition that can't be applied.
+type Invalid struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// Plan is the set of changes that makes Uptrace match the monitor definitions.
+type Plan struct {
+	Changes   []*Change `json:"changes"`
+	Unchanged int       `json:"unchanged"`
+	// Unmanaged are names of monitors that only exist in Uptrace and are kept
+	// because pruning is disabled.
+	Unmanaged []string  `json:"unmanaged,omitempty" jsonschema:"Monitors that only exist in Uptrace and are kept because prune is off."`
+	Invalid   []Invalid `json:"invalid,omitempty" jsonschema:"Definitions with errors. A plan with invalid definitions can't be applied."`
+	Warnings  []string  `json:"warnings,omitempty" jsonschema:"Problems with monitors in Uptrace, e.g. references to deleted notification channels."`
+
+	channels *Channels
+}
+
+func (p *Plan) count(action Action) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary returns a one-line Terraform-style summary of the plan.
+func (p *Plan) Summary() string {
+	if len(p.Changes) == 0 {
+		return "No changes. Monitors in Uptrace match the definitions."
+	}
+	return fmt.Sprintf("Plan: %d to add, %d to change, %d to destroy.",
+		p.count(ActionCreate), p.count(ActionUpdate), p.count(ActionDelete))
+}
+
+// String renders the plan with a diff for every change.
+func (p *Plan) String() string {
+	var b strings.Builder
+	for _, w := range p.Warnings {
+		fmt.Fprintf(&b, "Warning: %s\n", w)
+	}
+	if len(p.Warnings) > 0 {
+		b.WriteByte('\n')
+	}
+	for _, inv := range p.Invalid {
+		fmt.Fprintf(&b, "! %s is invalid:\n", inv.Path)
+		for _, line := range strings.Split(strings.TrimSpace(inv.Error), "\n") {
+			fmt.Fprintf(&b, "    %s\n", line)
+		}
+		b.WriteByte('\n')
+	}
+	for _, c := range p.Changes {
+		switch c.Action {
+		case ActionCreate:
+			fmt.Fprintf(&b, "+ create %q from %s\n", c.Name, c.Path)
+		case ActionUpdate:
+			fmt.Fprintf(&b, "~ update %q (id %d) from %s\n", c.Name, c.ID, c.Path)
+		case ActionDelete:
+			fmt.Fprintf(&b, "- delete %q (id %d)\n", c.Name, c.ID)
+		}
+		if c.Diff != "" {
+			for _, line := range strings.SplitAfter(strings.TrimSuffix(c.Diff, "\n"), "\n") {
+				b.WriteString("    ")
+				b.WriteString(line)
+			}
+			b.WriteByte('\n')
+		}
+		b.WriteByte('\n')
+	}
+	if n := len(p.Unmanaged); n > 0 {
+		fmt.Fprintf(&b, "%d monitor(s) exist only in Uptrace and are kept; use --prune to delete them.\n", n)
+	}
+	b.WriteString(p.Summary())
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// Plan compares monitor definitions with the monitors in Uptrace. Monitors that
+// have no definition are deleted only when prune is set.
+func (s *Syncer) Plan(ctx context.Context, files []*LocalFile, prune bool) (*Plan, error) {
+	channels, err := s.Channels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := s.Remote(ctx, channels)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{channels: channels}
+	remoteByKey := make(map[string]*RemoteMonitor, len(remote))
+	for _, m := range remote {
+		remoteByKey[m.Key] = m
+		if w := m.warning(); w != "" {
+			plan.Warnings = append(plan.Warnings, w)
+		}
+	}
+
+	seen := make(map[string]string, len(files))
+	for _, file := range files {
+		if other, ok := seen[file.Key]; ok {
+			plan.Invalid = append(plan.Invalid, Invalid{
+				Path:  file.Path,
+				Error: fmt.Sprintf("monitor %q is also defined in %s", file.Key, other),
+			})
+			continue
+		}
+		seen[file.Key] = file.Path
+
+		if file.Err == nil {
+			// Resolve channels now so that a typo fails the plan, not the apply.
+			_, file.Err = file.Monitor.request(channels)
+		}
+		if file.Err != nil {
+			plan.Invalid = append(plan.Invalid, Invalid{Path: file.Path, Error: file.Err.Error()})
+			continue
+		}
+
+		// Remote channel names are sorted, so sort the local ones too to not
+		// report a change in order as an update.
+		slices.Sort(file.Monitor.Channels)
+		local, err := file.Monitor.YAML()
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := remoteByKey[file.Key]
+		if !ok {
+			plan.Changes = append(plan.Changes, &Change{
+				Action:  ActionCreate,
+				Key:     file.Key,
+				Name:    file.Monitor.Name,
+				Path:    file.Path,
+				Diff:    textdiff.Unified("/dev/null", file.Path, "", string(local)),
+				monitor: file.Monitor,
+			})
+			continue
+		}
+
+		current, err := m.Monitor.YAML()
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(current, local) {
+			plan.Unchanged++
+			continue
+		}
+		plan.Changes = append(plan.Changes, &Change{
+			Action:  ActionUpdate,
+			Key:     file.Key,
+			Name:    file.Monitor.Name,
+			ID:      m.ID,
+			Path:    file.Path,
+			Diff:    textdiff.Unified(fmt.Sprintf("uptrace/%d", m.ID), file.Path, string(current), string(local)),
+			monitor: file.Monitor,
+		})
+	}
+
+	for _, m := range remote {
+		if _, ok := seen[m.Key]; ok {
+			continue
+		}
+		if !prune {
+			plan.Unmanaged = append(plan.Unmanaged, m.Monitor.Name)
+			continue
+		}
+		plan.Changes = append(plan.Changes, &Change{
+			Action: ActionDelete,
+			Key:    m.Key,
+			Name:   m.Monitor.Name,
+			ID:     m.ID,
+		})
+	}
+
+	actionOrder := []Action{ActionCreate, ActionUpdate, ActionDelete}
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		a, b := plan.Changes[i], plan.Changes[j]
+		if a.Action != b.Action {
+			return slices.Index(actionOrder, a.Action) < slices.Index(actionOrder, b.Action)
+		}
+		return a.Key < b.Key
+	})
+	return plan, nil
+}
+
+// Apply makes the changes of the plan in order, calling done after each one. It
+// stops at the first error.
+func (s *Syncer) Apply(ctx context.Context, plan *Plan, done func(*Change)) error {
+	if len(plan.Invalid) > 0 {
+		return fmt.Errorf("%d monitor definition(s) are invalid", len(plan.Invalid))
+	}
+
+	for _, c := range plan.Changes {
+		var err error
+		switch c.Action {
+		case ActionCreate:
+			err = s.create(ctx, plan.channels, c.monitor)
+		case ActionUpdate:
+			err = s.update(ctx, plan.channels, c.ID, c.monitor)
+		case ActionDelete:
+			_, err = s.client.DeleteMonitor(ctx, &uptraceapi.DeleteMonitorRequestOptions{
+				PathParams: &uptraceapi.DeleteMonitorPath{ProjectID: s.projectID, MonitorID: c.ID},
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("%s %q: %w", c.Action, c.Name, err)
+		}
+		if done != nil {
+			done(c)
+		}
+	}
+	return nil
+}
+
+func (s *Syncer) create(ctx context.Context, channels *Channels, m *Monitor) error {
+	req, err := m.request(channels)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.CreateMonitor(ctx, &uptraceapi.CreateMonitorRequestOptions{
+		PathParams: &uptraceapi.CreateMonitorPath{ProjectID: s.projectID},
+		Body:       &uptraceapi.CreateMonitorBody{CreateMonitorBody_OneOf: &uptraceapi.CreateMonitorBody_OneOf{Either: req}},
+	})
+	return err
+}
+
+func (s *Syncer) update(ctx context.Context, channels *Channels, id int64, m *Monitor) error {
+	req, err := m.request(channels)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.UpdateMonitor(ctx, &uptraceapi.UpdateMonitorRequestOptions{
+		PathParams: &uptraceapi.UpdateMonitorPath{ProjectID: s.projectID, MonitorID: id},
+		Body:       &uptraceapi.UpdateMonitorBody{UpdateMonitorBody_OneOf: &uptraceapi.UpdateMonitorBody_OneOf{Either: req}},
+	})
+	return err
+}
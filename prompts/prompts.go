@@ -0,0 +1,206 @@
+package prompts
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/fx"
+
+	"github.com/uptrace/mcp/appconf"
+)
+
+//go:embed builtin/*.yaml
+var builtinFS embed.FS
+
+var Module = fx.Module("prompts",
+	fx.Invoke(Register),
+)
+
+// Prompt is a prompt template loaded from a YAML file. Template is a Go
+// text/template that receives the arguments as a map, e.g. {{.service}}.
+type Prompt struct {
+	Name        string     `yaml:"name"`
+	Title       string     `yaml:"title"`
+	Description string     `yaml:"description"`
+	Arguments   []Argument `yaml:"arguments"`
+	Template    string     `yaml:"template"`
+
+	tmpl *template.Template
+}
+
+type Argument struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+	// Default is used when the argument is optional and not set.
+	Default string `yaml:"default"`
+}
+
+// Register adds the built-in prompts and the prompts from prompts.dir to the
+// server.
+func Register(server *mcp.Server, conf *appconf.Config) error {
+	prompts, err := Load(conf.Prompts.Dir)
+	if err != nil {
+		return err
+	}
+	for _, p := range prompts {
+		server.AddPrompt(p.mcpPrompt(), p.handler)
+	}
+	return nil
+}
+
+// Load returns the built-in prompts merged with the *.yaml prompts in dir. A
+// prompt in dir replaces the built-in prompt with the same name.
+func Load(dir string) ([]*Prompt, error) {
+	byName := make(map[string]*Prompt)
+
+	builtin, err := loadFS(builtinFS, "builtin")
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range builtin {
+		byName[p.Name] = p
+	}
+
+	if dir != "" {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("load prompts: %w", err)
+		}
+		custom, err := loadFS(os.DirFS(dir), ".")
+		if err != nil {
+			return nil, fmt.Errorf("load prompts from %s: %w", dir, err)
+		}
+		for _, p := range custom {
+			byName[p.Name] = p
+		}
+	}
+
+	prompts := make([]*Prompt, 0, len(byName))
+	for _, p := range byName {
+		prompts = append(prompts, p)
+	}
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].Name < prompts[j].Name })
+	return prompts, nil
+}
+
+func loadFS(fsys fs.FS, dir string) ([]*Prompt, error) {
+	var paths []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := fs.Glob(fsys, path.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	seen := make(map[string]string, len(paths))
+	prompts := make([]*Prompt, 0, len(paths))
+	for _, name := range paths {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		p, err := Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if other, ok := seen[p.Name]; ok {
+			return nil, fmt.Errorf("%s: prompt %q is already defined in %s", name, p.Name, other)
+		}
+		seen[p.Name] = name
+		prompts = append(prompts, p)
+	}
+	return prompts, nil
+}
+
+// Parse parses and validates a prompt definition.
+func Parse(data []byte) (*Prompt, error) {
+	p := new(Prompt)
+	if err := yaml.UnmarshalWithOptions(data, p, yaml.DisallowUnknownField()); err != nil {
+		return nil, fmt.Errorf("parse prompt: %s", yaml.FormatError(err, false, true))
+	}
+	if p.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if strings.TrimSpace(p.Template) == "" {
+		return nil, fmt.Errorf("prompt %q: template is required", p.Name)
+	}
+	for _, arg := range p.Arguments {
+		if arg.Name == "" {
+			return nil, fmt.Errorf("prompt %q: argument name is required", p.Name)
+		}
+	}
+
+	tmpl, err := template.New(p.Name).Option("missingkey=zero").Parse(p.Template)
+	if err != nil {
+		return nil, fmt.Errorf("prompt %q: %w", p.Name, err)
+	}
+	p.tmpl = tmpl
+	return p, nil
+}
+
+// Render executes the template with args, filling in defaults and checking that
+// required arguments are set.
+func (p *Prompt) Render(args map[string]string) (string, error) {
+	data := make(map[string]string, len(p.Arguments))
+	for _, arg := range p.Arguments {
+		value := strings.TrimSpace(args[arg.Name])
+		if value == "" {
+			if arg.Required {
+				return "", fmt.Errorf("argument %q is required", arg.Name)
+			}
+			value = arg.Default
+		}
+		data[arg.Name] = value
+	}
+
+	var buf bytes.Buffer
+	if err := p.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render prompt %q: %w", p.Name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func (p *Prompt) mcpPrompt() *mcp.Prompt {
+	out := &mcp.Prompt{
+		Name:        p.Name,
+		Title:       p.Title,
+		Description: p.Description,
+	}
+	for _, arg := range p.Arguments {
+		desc := arg.Description
+		if arg.Default != "" {
+			desc = strings.TrimSpace(fmt.Sprintf("%s Defaults to %s.", desc, arg.Default))
+		}
+		out.Arguments = append(out.Arguments, &mcp.PromptArgument{
+			Name:        arg.Name,
+			Description: desc,
+			Required:    arg.Required,
+		})
+	}
+	return out
+}
+
+func (p *Prompt) handler(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	text, err := p.Render(req.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.GetPromptResult{
+		Description: p.Description,
+		Messages: []*mcp.PromptMessage{
+			{Role: "user", Content: &mcp.TextContent{Text: text}},
+		},
+	}, nil
+}
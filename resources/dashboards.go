@@ -0,0 +1,76 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+const dashboardPrefix = "uptrace://dashboards/"
+
+type DashboardResource struct {
+	client *uptraceapi.Client
+	conf   *appconf.Config
+}
+
+func NewDashboardResource(client *uptraceapi.Client, conf *appconf.Config) *DashboardResource {
+	return &DashboardResource{
+		client: client,
+		conf:   conf,
+	}
+}
+
+func (r *DashboardResource) Register(server *mcp.Server) {
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "dashboard",
+		Title:       "Dashboard",
+		URITemplate: dashboardPrefix + "{id}",
+		Description: "Dashboard definition as schema v2 YAML.",
+		MIMEType:    mimeYAML,
+	}, r.read)
+}
+
+func (r *DashboardResource) List(ctx context.Context) ([]*mcp.Resource, error) {
+	resp, err := r.client.ListDashboards(ctx, &uptraceapi.ListDashboardsRequestOptions{
+		PathParams: &uptraceapi.ListDashboardsPath{ProjectID: r.conf.Uptrace.ProjectID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*mcp.Resource, 0, len(resp.Dashboards))
+	for _, d := range resp.Dashboards {
+		out = append(out, &mcp.Resource{
+			URI:         fmt.Sprintf("%s%d", dashboardPrefix, d.ID),
+			Name:        fmt.Sprintf("dashboard-%d", d.ID),
+			Title:       d.Name,
+			Description: "Dashboard YAML",
+			MIMEType:    mimeYAML,
+		})
+	}
+	return out, nil
+}
+
+func (r *DashboardResource) read(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	id, err := uriID(uri, dashboardPrefix)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.client.GetDashboardYaml(ctx, &uptraceapi.GetDashboardYamlRequestOptions{
+		PathParams: &uptraceapi.GetDashboardYamlPath{
+			ProjectID:   r.conf.Uptrace.ProjectID,
+			DashboardID: id,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{URI: uri, MIMEType: mimeYAML, Text: string(*resp)},
+		},
+	}, nil
+}
@@ -0,0 +1,49 @@
+package resources
+
+import (
+	"context"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+const metricsURI = "uptrace://metrics"
+
+type MetricsResource struct {
+	client *uptraceapi.Client
+	conf   *appconf.Config
+}
+
+func NewMetricsResource(client *uptraceapi.Client, conf *appconf.Config) *MetricsResource {
+	return &MetricsResource{
+		client: client,
+		conf:   conf,
+	}
+}
+
+func (r *MetricsResource) Register(server *mcp.Server) {
+	server.AddResource(&mcp.Resource{
+		Name:        "metrics",
+		Title:       "Metrics",
+		URI:         metricsURI,
+		Description: "Metrics reported to the project recently, with their instruments, units and descriptions.",
+		MIMEType:    mimeJSON,
+	}, r.read)
+}
+
+func (r *MetricsResource) read(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	end := time.Now()
+	resp, err := r.client.ExploreMetrics(ctx, &uptraceapi.ExploreMetricsRequestOptions{
+		PathParams: &uptraceapi.ExploreMetricsPath{ProjectID: r.conf.Uptrace.ProjectID},
+		Query: &uptraceapi.ExploreMetricsQuery{
+			TimeStart: end.Add(-r.conf.Default.TimeDuration),
+			TimeEnd:   end,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jsonContents(req.Params.URI, resp)
+}
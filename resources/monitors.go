@@ -0,0 +1,111 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+const (
+	monitorsURI   = "uptrace://monitors"
+	monitorPrefix = monitorsURI + "/"
+)
+
+type MonitorResource struct {
+	client *uptraceapi.Client
+	conf   *appconf.Config
+}
+
+func NewMonitorResource(client *uptraceapi.Client, conf *appconf.Config) *MonitorResource {
+	return &MonitorResource{
+		client: client,
+		conf:   conf,
+	}
+}
+
+func (r *MonitorResource) Register(server *mcp.Server) {
+	server.AddResource(&mcp.Resource{
+		Name:        "monitors",
+		Title:       "Monitors",
+		URI:         monitorsURI,
+		Description: "Monitors of the project with their current status. Subscribe to get notified when a monitor starts or stops firing.",
+		MIMEType:    mimeJSON,
+	}, r.readAll)
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "monitor",
+		Title:       "Monitor",
+		URITemplate: monitorPrefix + "{id}",
+		Description: "Monitor with its type, status, notification settings and params. Subscribe to get notified when its status changes.",
+		MIMEType:    mimeJSON,
+	}, r.read)
+}
+
+func (r *MonitorResource) List(ctx context.Context) ([]*mcp.Resource, error) {
+	resp, err := r.client.ListMonitors(ctx, &uptraceapi.ListMonitorsRequestOptions{
+		PathParams: &uptraceapi.ListMonitorsPath{ProjectID: r.conf.Uptrace.ProjectID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*mcp.Resource, 0, len(resp.Monitors))
+	for _, m := range resp.Monitors {
+		out = append(out, &mcp.Resource{
+			URI:         fmt.Sprintf("%s%d", monitorPrefix, m.ID),
+			Name:        fmt.Sprintf("monitor-%d", m.ID),
+			Title:       m.Name,
+			Description: fmt.Sprintf("%s monitor, %s", m.Type, m.Status),
+			MIMEType:    mimeJSON,
+		})
+	}
+	return out, nil
+}
+
+func (r *MonitorResource) read(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	id, err := uriID(uri, monitorPrefix)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.client.GetMonitor(ctx, &uptraceapi.GetMonitorRequestOptions{
+		PathParams: &uptraceapi.GetMonitorPath{
+			ProjectID: r.conf.Uptrace.ProjectID,
+			MonitorID: id,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jsonContents(uri, resp.Monitor)
+}
+
+// monitorStatus is an entry of the uptrace://monitors resource.
+type monitorStatus struct {
+	ID     int64                    `json:"id"`
+	Name   string                   `json:"name"`
+	Type   uptraceapi.MonitorType   `json:"type"`
+	Status uptraceapi.MonitorStatus `json:"status"`
+	URI    string                   `json:"uri"`
+}
+
+func (r *MonitorResource) readAll(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	resp, err := r.client.ListMonitors(ctx, &uptraceapi.ListMonitorsRequestOptions{
+		PathParams: &uptraceapi.ListMonitorsPath{ProjectID: r.conf.Uptrace.ProjectID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]monitorStatus, 0, len(resp.Monitors))
+	for _, m := range resp.Monitors {
+		out = append(out, monitorStatus{
+			ID:     m.ID,
+			Name:   m.Name,
+			Type:   m.Type,
+			Status: m.Status,
+			URI:    fmt.Sprintf("%s%d", monitorPrefix, m.ID),
+		})
+	}
+	return jsonContents(req.Params.URI, out)
+}
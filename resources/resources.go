@@ -0,0 +1,190 @@
+package resources
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/fx"
+)
+
+const (
+	mimeJSON = "application/json"
+	mimeYAML = "application/yaml"
+
+	// pageSize is the number of resources returned by one resources/list call.
+	pageSize = 100
+)
+
+type RegisterParams struct {
+	fx.In
+	Ctx       context.Context
+	Lifecycle fx.Lifecycle
+	Server    *mcp.Server
+	Watcher   *MonitorWatcher
+	Resources []Resource `group:"resources"`
+}
+
+var Module = fx.Module("resources",
+	fx.Provide(
+		NewMonitorWatcher,
+		fx.Annotate(NewDashboardResource, fx.As(new(Resource)), fx.ResultTags(`group:"resources"`)),
+		fx.Annotate(NewMonitorResource, fx.As(new(Resource)), fx.ResultTags(`group:"resources"`)),
+		fx.Annotate(NewMetricsResource, fx.As(new(Resource)), fx.ResultTags(`group:"resources"`)),
+		fx.Annotate(NewTraceResource, fx.As(new(Resource)), fx.ResultTags(`group:"resources"`)),
+	),
+	fx.Invoke(Register),
+)
+
+// Resource registers resources and resource templates on the server.
+type Resource interface {
+	Register(server *mcp.Server)
+}
+
+// Lister is implemented by resources backed by a template whose concrete
+// resources should show up in resources/list, e.g. every dashboard.
+type Lister interface {
+	List(ctx context.Context) ([]*mcp.Resource, error)
+}
+
+func Register(p RegisterParams) {
+	var listers []Lister
+	for _, r := range p.Resources {
+		r.Register(p.Server)
+		if l, ok := r.(Lister); ok {
+			listers = append(listers, l)
+		}
+	}
+	p.Server.AddReceivingMiddleware(listMiddleware(listers))
+
+	// The watcher polls for as long as the app runs.
+	ctx, cancel := context.WithCancel(p.Ctx)
+	done := make(chan struct{})
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				p.Watcher.Run(ctx, p.Server)
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
+	})
+}
+
+// listMiddleware answers resources/list with the static resources followed by the
+// resources of every lister. The Uptrace list endpoints are not paginated, so the
+// combined list is paginated here with an opaque offset cursor.
+func listMiddleware(listers []Lister) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			listReq, ok := req.(*mcp.ListResourcesRequest)
+			if !ok || method != "resources/list" {
+				return next(ctx, method, req)
+			}
+
+			offset := 0
+			if listReq.Params != nil && listReq.Params.Cursor != "" {
+				var err error
+				if offset, err = decodeCursor(listReq.Params.Cursor); err != nil {
+					return nil, err
+				}
+			}
+
+			all, err := listStatic(ctx, next, listReq)
+			if err != nil {
+				return nil, err
+			}
+			for _, l := range listers {
+				resources, err := l.List(ctx)
+				if err != nil {
+					return nil, err
+				}
+				all = append(all, resources...)
+			}
+
+			res := &mcp.ListResourcesResult{Resources: []*mcp.Resource{}}
+			if offset < len(all) {
+				end := min(offset+pageSize, len(all))
+				res.Resources = all[offset:end]
+				if end < len(all) {
+					res.NextCursor = encodeCursor(end)
+				}
+			}
+			return res, nil
+		}
+	}
+}
+
+// listStatic collects the resources registered with AddResource.
+func listStatic(ctx context.Context, next mcp.MethodHandler, req *mcp.ListResourcesRequest) ([]*mcp.Resource, error) {
+	var all []*mcp.Resource
+	params := &mcp.ListResourcesParams{}
+	for {
+		res, err := next(ctx, "resources/list", &mcp.ListResourcesRequest{Session: req.Session, Params: params})
+		if err != nil {
+			return nil, err
+		}
+		page := res.(*mcp.ListResourcesResult)
+		all = append(all, page.Resources...)
+		if page.NextCursor == "" {
+			return all, nil
+		}
+		params = &mcp.ListResourcesParams{Cursor: page.NextCursor}
+	}
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	offset, err := strconv.Atoi(string(b))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return offset, nil
+}
+
+// uriID parses the numeric ID at the end of a resource URI such as
+// uptrace://dashboards/123.
+func uriID(uri, prefix string) (int64, error) {
+	s, ok := strings.CutPrefix(uri, prefix)
+	if !ok {
+		return 0, mcp.ResourceNotFoundError(uri)
+	}
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, mcp.ResourceNotFoundError(uri)
+	}
+	return id, nil
+}
+
+// jsonContents encodes v as the contents of a JSON resource.
+func jsonContents(uri string, v any) (*mcp.ReadResourceResult, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{URI: uri, MIMEType: mimeJSON, Text: string(b)},
+		},
+	}, nil
+}
@@ -0,0 +1,71 @@
+package resources
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+const (
+	tracePrefix = "uptrace://traces/"
+
+	// traceLookback is how far back a trace is searched, since a trace ID
+	// carries no timestamp.
+	traceLookback = 7 * 24 * time.Hour
+	traceMaxSpans = 1000
+)
+
+type TraceResource struct {
+	client *uptraceapi.Client
+	conf   *appconf.Config
+}
+
+func NewTraceResource(client *uptraceapi.Client, conf *appconf.Config) *TraceResource {
+	return &TraceResource{
+		client: client,
+		conf:   conf,
+	}
+}
+
+func (r *TraceResource) Register(server *mcp.Server) {
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "trace",
+		Title:       "Trace",
+		URITemplate: tracePrefix + "{trace_id}",
+		Description: "Spans of a trace from the last 7 days, up to 1000 spans.",
+		MIMEType:    mimeJSON,
+	}, r.read)
+}
+
+func (r *TraceResource) read(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	traceID, ok := strings.CutPrefix(uri, tracePrefix)
+	if !ok || traceID == "" || strings.Contains(traceID, "/") {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	end := time.Now()
+	query := "where _trace_id = " + strconv.Quote(traceID)
+	limit := uptraceapi.Limit(traceMaxSpans)
+	resp, err := r.client.ListSpans(ctx, &uptraceapi.ListSpansRequestOptions{
+		PathParams: &uptraceapi.ListSpansPath{ProjectID: r.conf.Uptrace.ProjectID},
+		Query: &uptraceapi.ListSpansQuery{
+			TimeStart: end.Add(-traceLookback),
+			TimeEnd:   end,
+			Query:     &query,
+			Limit:     &limit,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Spans) == 0 {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	return jsonContents(uri, resp)
+}
@@ -0,0 +1,263 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/uptraceapi"
+	"github.com/uptrace/oapi-codegen-dd/v3/pkg/runtime"
+)
+
+// maxGetMonitors is the number of individually subscribed monitors that are
+// fetched one by one with GetMonitor; above it one ListMonitors call is cheaper.
+const maxGetMonitors = 5
+
+// MonitorWatcher implements resource subscriptions for uptrace://monitors and
+// uptrace://monitors/{id}. While there are subscribers, it polls monitor
+// statuses and sends resources/updated when a monitor changes status (e.g.
+// active → firing → active), appears or is deleted.
+type MonitorWatcher struct {
+	client *uptraceapi.Client
+	conf   *appconf.Config
+	logger *slog.Logger
+
+	mu   sync.Mutex
+	subs map[string]map[*mcp.ServerSession]struct{}
+
+	// statuses are the statuses seen by the last poll; full reports whether
+	// they cover every monitor of the project or only the subscribed ones.
+	statuses map[int64]uptraceapi.MonitorStatus
+	full     bool
+}
+
+func NewMonitorWatcher(
+	client *uptraceapi.Client,
+	conf *appconf.Config,
+	logger *slog.Logger,
+) *MonitorWatcher {
+	return &MonitorWatcher{
+		client: client,
+		conf:   conf,
+		logger: logger,
+		subs:   make(map[string]map[*mcp.ServerSession]struct{}),
+	}
+}
+
+// Subscribe implements mcp.ServerOptions.SubscribeHandler.
+func (w *MonitorWatcher) Subscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	uri := req.Params.URI
+	if uri != monitorsURI {
+		if _, err := uriID(uri, monitorPrefix); err != nil {
+			return fmt.Errorf("resource %s does not support subscriptions", uri)
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	sessions := w.subs[uri]
+	if _, ok := sessions[req.Session]; ok {
+		return nil
+	}
+	if n := w.numSubs(); n >= w.conf.Subscriptions.MaxSubscriptions {
+		return fmt.Errorf("the server already has %d resource subscriptions (subscriptions.max_subscriptions)", n)
+	}
+	if sessions == nil {
+		sessions = make(map[*mcp.ServerSession]struct{})
+		w.subs[uri] = sessions
+	}
+	sessions[req.Session] = struct{}{}
+	return nil
+}
+
+// Unsubscribe implements mcp.ServerOptions.UnsubscribeHandler.
+func (w *MonitorWatcher) Unsubscribe(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.unsubscribe(req.Params.URI, req.Session)
+	return nil
+}
+
+func (w *MonitorWatcher) unsubscribe(uri string, session *mcp.ServerSession) {
+	sessions := w.subs[uri]
+	delete(sessions, session)
+	if len(sessions) == 0 {
+		delete(w.subs, uri)
+	}
+}
+
+func (w *MonitorWatcher) numSubs() int {
+	var n int
+	for _, sessions := range w.subs {
+		n += len(sessions)
+	}
+	return n
+}
+
+// Run polls the subscribed monitors every subscriptions.poll_interval until ctx
+// is done.
+func (w *MonitorWatcher) Run(ctx context.Context, server *mcp.Server) {
+	ticker := time.NewTicker(w.conf.Subscriptions.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := w.poll(ctx, server); err != nil && ctx.Err() == nil {
+			w.logger.Warn("polling monitor statuses failed", slog.Any("error", err))
+		}
+	}
+}
+
+func (w *MonitorWatcher) poll(ctx context.Context, server *mcp.Server) error {
+	ids, all := w.subscribed(server)
+	if len(ids) == 0 && !all {
+		// Forget the statuses so that a new subscriber is not notified about
+		// changes that happened while nobody was watching.
+		w.mu.Lock()
+		w.statuses = nil
+		w.mu.Unlock()
+		return nil
+	}
+
+	full := all || len(ids) > maxGetMonitors
+	statuses, names, err := w.fetch(ctx, ids, full)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	prev, prevFull := w.statuses, w.full
+	w.statuses, w.full = statuses, full
+	w.mu.Unlock()
+
+	if prev == nil {
+		return nil
+	}
+
+	var listChanged bool
+	for _, id := range ids {
+		status, ok := statuses[id]
+		prevStatus, prevOK := prev[id]
+		if !prevOK || (ok && status == prevStatus) {
+			continue
+		}
+		to := string(status)
+		if !ok {
+			to = "deleted"
+		}
+		w.logger.Debug("monitor status changed",
+			slog.Int64("monitor_id", id),
+			slog.String("name", names[id]),
+			slog.String("from", string(prevStatus)),
+			slog.String("to", to))
+		w.notify(ctx, server, fmt.Sprintf("%s%d", monitorPrefix, id))
+	}
+	if all && prevFull {
+		listChanged = len(statuses) != len(prev)
+		for id, status := range statuses {
+			if prevStatus, ok := prev[id]; !ok || status != prevStatus {
+				listChanged = true
+			}
+		}
+	}
+	if listChanged {
+		w.notify(ctx, server, monitorsURI)
+	}
+	return nil
+}
+
+// subscribed returns the IDs of the individually subscribed monitors and whether
+// the monitor list is subscribed. Sessions that were closed without
+// unsubscribing are dropped.
+func (w *MonitorWatcher) subscribed(server *mcp.Server) ([]int64, bool) {
+	open := make(map[*mcp.ServerSession]bool)
+	for session := range server.Sessions() {
+		open[session] = true
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var ids []int64
+	var all bool
+	for uri, sessions := range w.subs {
+		for session := range sessions {
+			if !open[session] {
+				w.unsubscribe(uri, session)
+			}
+		}
+		if _, ok := w.subs[uri]; !ok {
+			continue
+		}
+		if uri == monitorsURI {
+			all = true
+			continue
+		}
+		if id, err := uriID(uri, monitorPrefix); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, all
+}
+
+// fetch returns the statuses and names of all monitors when full is set, and of
+// the monitors with the given IDs otherwise. Deleted monitors are left out.
+func (w *MonitorWatcher) fetch(
+	ctx context.Context, ids []int64, full bool,
+) (map[int64]uptraceapi.MonitorStatus, map[int64]string, error) {
+	statuses := make(map[int64]uptraceapi.MonitorStatus)
+	names := make(map[int64]string)
+
+	if full {
+		resp, err := w.client.ListMonitors(ctx, &uptraceapi.ListMonitorsRequestOptions{
+			PathParams: &uptraceapi.ListMonitorsPath{ProjectID: w.conf.Uptrace.ProjectID},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, m := range resp.Monitors {
+			statuses[m.ID] = m.Status
+			names[m.ID] = m.Name
+		}
+		return statuses, names, nil
+	}
+
+	for _, id := range ids {
+		resp, err := w.client.GetMonitor(ctx, &uptraceapi.GetMonitorRequestOptions{
+			PathParams: &uptraceapi.GetMonitorPath{
+				ProjectID: w.conf.Uptrace.ProjectID,
+				MonitorID: id,
+			},
+		})
+		if err != nil {
+			var apiErr *runtime.ClientAPIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode() == http.StatusNotFound {
+				continue
+			}
+			return nil, nil, err
+		}
+		statuses[id] = resp.Monitor.Status
+		names[id] = resp.Monitor.Name
+	}
+	return statuses, names, nil
+}
+
+func (w *MonitorWatcher) notify(ctx context.Context, server *mcp.Server, uri string) {
+	if err := server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri}); err != nil {
+		w.logger.Warn("sending resources/updated failed",
+			slog.String("uri", uri),
+			slog.Any("error", err))
+	}
+}
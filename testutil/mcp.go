@@ -0,0 +1,57 @@
+package testutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Connect runs server over in-memory transports and returns a connected client
+// session, which is closed when the test finishes.
+func Connect(tb testing.TB, server *mcp.Server) *mcp.ClientSession {
+	tb.Helper()
+
+	ctx := tb.Context()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "testutil"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		_ = session.Close()
+		_ = serverSession.Wait()
+	})
+	return session
+}
+
+// CallTool calls a tool and fails the test on protocol errors. Tool errors are
+// returned in the result, as the client sees them.
+func CallTool(tb testing.TB, session *mcp.ClientSession, name string, args any) *mcp.CallToolResult {
+	tb.Helper()
+
+	res, err := session.CallTool(tb.Context(), &mcp.CallToolParams{
+		Name:      name,
+		Arguments: args,
+	})
+	if err != nil {
+		tb.Fatalf("%s: %s", name, err)
+	}
+	return res
+}
+
+// Text joins the text content of a tool result.
+func Text(res *mcp.CallToolResult) string {
+	var parts []string
+	for _, content := range res.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
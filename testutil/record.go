@@ -0,0 +1,198 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+// Record mode is enabled with UPTRACE_RECORD=1 and reads the Uptrace to record
+// from UPTRACE_API_URL, UPTRACE_API_TOKEN and UPTRACE_PROJECT_ID, e.g.
+//
+//	UPTRACE_RECORD=1 UPTRACE_API_URL=https://api.uptrace.dev \
+//	UPTRACE_API_TOKEN=... UPTRACE_PROJECT_ID=123 go test ./tools/
+//
+// Only GET requests are forwarded, so recording never changes the project;
+// mutations are still answered with their fixtures. Requests keep using
+// ProjectID and the recorder swaps in the real project on the way out and back.
+const (
+	envRecord    = "UPTRACE_RECORD"
+	envAPIURL    = "UPTRACE_API_URL"
+	envAPIToken  = "UPTRACE_API_TOKEN"
+	envProjectID = "UPTRACE_PROJECT_ID"
+)
+
+const redacted = "REDACTED"
+
+// secretKeys are substrings of JSON keys, compared in lower case, whose string
+// values are replaced before a fixture is saved.
+var secretKeys = []string{
+	"token", "secret", "password", "apikey", "api_key", "routingkey", "routing_key",
+	"webhookurl", "webhook_url", "authorization", "dsn",
+}
+
+// urlUserinfo matches credentials in URLs such as DSNs.
+var urlUserinfo = regexp.MustCompile(`://[^/@\s"]+@`)
+
+type recorder struct {
+	apiURL    string
+	token     string
+	projectID int64
+	dir       string
+	client    *http.Client
+
+	// mu serializes writes of the same fixture by parallel tests.
+	mu sync.Mutex
+}
+
+// newRecorder returns nil unless record mode is enabled.
+func newRecorder() (*recorder, error) {
+	if os.Getenv(envRecord) == "" {
+		return nil, nil
+	}
+
+	r := &recorder{
+		apiURL: strings.TrimSuffix(os.Getenv(envAPIURL), "/"),
+		token:  os.Getenv(envAPIToken),
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+	if r.apiURL == "" || r.token == "" {
+		return nil, fmt.Errorf("testutil: %s requires %s and %s", envRecord, envAPIURL, envAPIToken)
+	}
+	projectID, err := strconv.ParseInt(os.Getenv(envProjectID), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("testutil: %s: %w", envProjectID, err)
+	}
+	r.projectID = projectID
+
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return nil, fmt.Errorf("testutil: can't locate testdata")
+	}
+	r.dir = filepath.Join(filepath.Dir(file), "testdata")
+	return r, nil
+}
+
+// record forwards a request to Uptrace and returns its response as a fixture.
+// Successful responses are saved to testdata/ with secrets scrubbed.
+func (r *recorder) record(op string, params map[string]string, query url.Values) (*Fixture, error) {
+	path := uptraceapi.Operations[op].Path
+	for name, value := range params {
+		if name == "project_id" {
+			value = strconv.FormatInt(r.projectID, 10)
+		}
+		path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(value))
+	}
+	u := r.apiURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+r.token)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Fixture{Status: resp.StatusCode}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		var v any
+		if err := json.Unmarshal(body, &v); err != nil {
+			return nil, fmt.Errorf("decoding response: %w", err)
+		}
+		f.Body, err = json.Marshal(r.scrub(v, ""))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		f.Text = r.scrubString(string(body))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return f, nil
+	}
+	return f, r.save(op, f)
+}
+
+func (r *recorder) save(op string, f *Fixture) error {
+	f = &Fixture{Body: f.Body, Text: f.Text}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(f); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return os.WriteFile(filepath.Join(r.dir, op+".json"), buf.Bytes(), 0o644)
+}
+
+// scrub replaces secrets in a decoded JSON value and maps the recorded project
+// back to ProjectID. key is the key of v in its parent object.
+func (r *recorder) scrub(v any, key string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, value := range v {
+			v[k] = r.scrub(value, k)
+		}
+		return v
+	case []any:
+		for i, value := range v {
+			v[i] = r.scrub(value, key)
+		}
+		return v
+	case string:
+		if v != "" && isSecretKey(key) {
+			return redacted
+		}
+		return r.scrubString(v)
+	case float64:
+		if (key == "projectId" || key == "project_id") && int64(v) == r.projectID {
+			return float64(ProjectID)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func (r *recorder) scrubString(s string) string {
+	s = strings.ReplaceAll(s, r.token, redacted)
+	return urlUserinfo.ReplaceAllString(s, "://"+redacted+"@")
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
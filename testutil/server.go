@@ -0,0 +1,319 @@
+// Package testutil runs tools and resources against a fake Uptrace API.
+//
+// Server serves the fixture in testdata/ of every operation of uptraceapi and
+// records the requests it receives, so tests can check the parameters a tool
+// filled in. With UPTRACE_RECORD=1 the server instead forwards GET requests to
+// a real Uptrace and saves the responses as fixtures; see record.go.
+package testutil
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/uptrace/oapi-codegen-dd/v3/pkg/runtime"
+
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+// ProjectID is the project of the config returned by Server.Config.
+const ProjectID = 1
+
+// APIToken is the token the client returned by Server.Client sends.
+const APIToken = "test-token"
+
+//go:embed testdata/*.json
+var testdata embed.FS
+
+// Fixture is a canned response of an operation, stored as
+// testdata/<operation>.json.
+type Fixture struct {
+	// Status defaults to 200.
+	Status int `json:"status,omitempty"`
+	// Body is a JSON response body.
+	Body json.RawMessage `json:"body,omitempty"`
+	// Text is a response body that is not JSON, e.g. dashboard YAML.
+	Text string `json:"text,omitempty"`
+}
+
+// Request is a request received by Server.
+type Request struct {
+	Operation  string
+	Method     string
+	PathParams map[string]string
+	Query      url.Values
+	Body       []byte
+}
+
+// Server is a fake Uptrace API.
+type Server struct {
+	*httptest.Server
+
+	tb       testing.TB
+	recorder *recorder
+
+	mu       sync.Mutex
+	fixtures map[string]*Fixture
+	requests []*Request
+}
+
+// NewServer starts a fake Uptrace API that is closed when the test finishes.
+func NewServer(tb testing.TB) *Server {
+	tb.Helper()
+
+	rec, err := newRecorder()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	s := &Server{
+		tb:       tb,
+		recorder: rec,
+		fixtures: make(map[string]*Fixture),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// Config returns a config pointing at the server. Tests can change it before
+// building the tools under test.
+func (s *Server) Config() *appconf.Config {
+	s.tb.Helper()
+
+	conf, err := appconf.Parse(s.configYAML())
+	if err != nil {
+		s.tb.Fatal(err)
+	}
+	return conf
+}
+
+// ConfigFile writes the config of Config to a temporary file and returns its
+// path, for commands that load the config themselves.
+func (s *Server) ConfigFile() string {
+	s.tb.Helper()
+
+	path := filepath.Join(s.tb.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, s.configYAML(), 0o600); err != nil {
+		s.tb.Fatal(err)
+	}
+	return path
+}
+
+func (s *Server) configYAML() []byte {
+	return fmt.Appendf(nil, `
+uptrace:
+  api_url: %s
+  api_token: %s
+  project_id: %d
+cache:
+  disabled: true
+`, s.URL, APIToken, ProjectID)
+}
+
+// Client returns an Uptrace client for the server.
+func (s *Server) Client() *uptraceapi.Client {
+	s.tb.Helper()
+
+	client, err := uptraceapi.NewDefaultClient(
+		s.URL,
+		runtime.WithHTTPClient(httpDoer{s.Server.Client()}),
+		runtime.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer "+APIToken)
+			return nil
+		}),
+	)
+	if err != nil {
+		s.tb.Fatal(err)
+	}
+	return client
+}
+
+// SetFixture replaces the fixture of an operation for the rest of the test.
+// body is encoded as JSON unless it is a string.
+func (s *Server) SetFixture(operation string, status int, body any) {
+	s.tb.Helper()
+
+	f := &Fixture{Status: status}
+	if text, ok := body.(string); ok {
+		f.Text = text
+	} else {
+		b, err := json.Marshal(body)
+		if err != nil {
+			s.tb.Fatal(err)
+		}
+		f.Body = b
+	}
+
+	s.mu.Lock()
+	s.fixtures[operation] = f
+	s.mu.Unlock()
+}
+
+// Requests returns the requests received for an operation, or every request
+// when operation is empty.
+func (s *Server) Requests(operation string) []*Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []*Request
+	for _, req := range s.requests {
+		if operation == "" || req.Operation == operation {
+			out = append(out, req)
+		}
+	}
+	return out
+}
+
+// FirstRequest returns the first request received for an operation and fails
+// the test when there is none.
+func (s *Server) FirstRequest(operation string) *Request {
+	s.tb.Helper()
+
+	reqs := s.Requests(operation)
+	if len(reqs) == 0 {
+		s.tb.Fatalf("no %s request", operation)
+	}
+	return reqs[0]
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	op, params, ok := matchOperation(req.Method, req.URL.Path)
+	if !ok {
+		s.tb.Errorf("testutil: unknown request %s %s", req.Method, req.URL.Path)
+		writeError(w, http.StatusNotFound, "no operation for "+req.Method+" "+req.URL.Path)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, &Request{
+		Operation:  op,
+		Method:     req.Method,
+		PathParams: params,
+		Query:      req.URL.Query(),
+		Body:       body,
+	})
+	f := s.fixtures[op]
+	s.mu.Unlock()
+
+	if f == nil && s.recorder != nil && req.Method == http.MethodGet {
+		f, err = s.recorder.record(op, params, req.URL.Query())
+		if err != nil {
+			s.tb.Errorf("testutil: recording %s: %s", op, err)
+			writeError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+	}
+	if f == nil {
+		f, err = loadFixture(op)
+		if err != nil {
+			s.tb.Errorf("testutil: %s", err)
+			writeError(w, http.StatusNotImplemented, err.Error())
+			return
+		}
+	}
+	f.write(w)
+}
+
+// loadFixture reads testdata/<operation>.json.
+func loadFixture(operation string) (*Fixture, error) {
+	b, err := testdata.ReadFile("testdata/" + operation + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("no fixture for %s", operation)
+	}
+	f := new(Fixture)
+	if err := json.Unmarshal(b, f); err != nil {
+		return nil, fmt.Errorf("fixture %s: %w", operation, err)
+	}
+	return f, nil
+}
+
+func (f *Fixture) write(w http.ResponseWriter) {
+	status := f.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if f.Body == nil {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, f.Text)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(f.Body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	body, _ := json.Marshal(uptraceapi.Error{Code: http.StatusText(status), Message: message})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// matchOperation finds the operation of a request and its path parameters.
+// Literal segments win over parameters, so /dashboards/tags is not taken for
+// the dashboard "tags".
+func matchOperation(method, path string) (string, map[string]string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	var found string
+	var foundParams map[string]string
+	for id, op := range uptraceapi.Operations {
+		if op.Method != method {
+			continue
+		}
+		params, ok := matchPath(op.Path, segments)
+		if !ok {
+			continue
+		}
+		if found == "" || len(params) < len(foundParams) {
+			found, foundParams = id, params
+		}
+	}
+	return found, foundParams, found != ""
+}
+
+func matchPath(template string, segments []string) (map[string]string, bool) {
+	parts := strings.Split(strings.Trim(template, "/"), "/")
+	if len(parts) != len(segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, part := range parts {
+		if name, ok := strings.CutPrefix(part, "{"); ok {
+			params[strings.TrimSuffix(name, "}")] = segments[i]
+			continue
+		}
+		if part != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// httpDoer adapts http.Client to runtime.HttpRequestDoer.
+type httpDoer struct {
+	*http.Client
+}
+
+func (c httpDoer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return c.Client.Do(req.WithContext(ctx))
+}
@@ -0,0 +1,167 @@
+package testutil
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/uptrace/oapi-codegen-dd/v3/pkg/runtime"
+
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+func TestOperations(t *testing.T) {
+	for id, op := range uptraceapi.Operations {
+		t.Run(id, func(t *testing.T) {
+			path := strings.NewReplacer(
+				"{project_id}", "1",
+				"{dashboard_id}", "2",
+				"{attr_key}", "service_name",
+			).Replace(op.Path)
+			for strings.Contains(path, "{") {
+				i := strings.Index(path, "{")
+				j := strings.Index(path, "}")
+				path = path[:i] + "3" + path[j+1:]
+			}
+
+			got, params, ok := matchOperation(op.Method, path)
+			if !ok || got != id {
+				t.Fatalf("%s %s matched %q, want %q", op.Method, path, got, id)
+			}
+			if strings.Contains(op.Path, "{project_id}") && params["project_id"] != "1" {
+				t.Errorf("project_id = %q, want 1", params["project_id"])
+			}
+
+			f, err := loadFixture(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.Body != nil && !json.Valid(f.Body) {
+				t.Errorf("fixture body is not valid JSON")
+			}
+		})
+	}
+}
+
+func TestServer(t *testing.T) {
+	srv := NewServer(t)
+	client := srv.Client()
+	ctx := t.Context()
+
+	resp, err := client.ListDashboards(ctx, &uptraceapi.ListDashboardsRequestOptions{
+		PathParams: &uptraceapi.ListDashboardsPath{ProjectID: ProjectID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Dashboards) == 0 {
+		t.Error("no dashboards in the list_dashboards fixture")
+	}
+
+	yaml, err := client.GetDashboardYaml(ctx, &uptraceapi.GetDashboardYamlRequestOptions{
+		PathParams: &uptraceapi.GetDashboardYamlPath{ProjectID: ProjectID, DashboardID: 31},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(*yaml), "schema: v2") {
+		t.Errorf("get_dashboard_yaml = %q", *yaml)
+	}
+
+	srv.SetFixture("get_dashboard", http.StatusNotFound, uptraceapi.Error{
+		Code:    "not_found",
+		Message: "dashboard not found",
+	})
+	_, err = client.GetDashboard(ctx, &uptraceapi.GetDashboardRequestOptions{
+		PathParams: &uptraceapi.GetDashboardPath{ProjectID: ProjectID, DashboardID: 99},
+	})
+	var apiErr *runtime.ClientAPIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode() != http.StatusNotFound {
+		t.Errorf("got %v, want a 404 error", err)
+	}
+
+	req := srv.FirstRequest("get_dashboard")
+	if req.PathParams["dashboard_id"] != "99" {
+		t.Errorf("dashboard_id = %q, want 99", req.PathParams["dashboard_id"])
+	}
+	if n := len(srv.Requests("")); n != 3 {
+		t.Errorf("got %d requests, want 3", n)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	const token = "secret-api-token"
+
+	var gotPath, gotAuth string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gotPath = req.URL.Path
+		gotAuth = req.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"channels":[{
+			"id": 1,
+			"projectId": 123,
+			"name": "oncall",
+			"type": "slack",
+			"status": "delivering",
+			"params": {"webhookUrl": "https://hooks.slack.com/services/T0/B0/xyz", "token": "` + token + `"},
+			"condition": "dsn https://` + token + `@api.uptrace.dev/123"
+		}]}`))
+	}))
+	defer upstream.Close()
+
+	t.Setenv(envRecord, "1")
+	t.Setenv(envAPIURL, upstream.URL)
+	t.Setenv(envAPIToken, token)
+	t.Setenv(envProjectID, "123")
+
+	srv := NewServer(t)
+	srv.recorder.dir = t.TempDir()
+	client := srv.Client()
+
+	resp, err := client.ListNotificationChannels(t.Context(), &uptraceapi.ListNotificationChannelsRequestOptions{
+		PathParams: &uptraceapi.ListNotificationChannelsPath{ProjectID: ProjectID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/internal/v1/projects/123/notification-channels" {
+		t.Errorf("forwarded to %s", gotPath)
+	}
+	if gotAuth != "Bearer "+token {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if resp.Channels[0].ProjectID != ProjectID {
+		t.Errorf("projectId = %d, want %d", resp.Channels[0].ProjectID, ProjectID)
+	}
+
+	b, err := os.ReadFile(filepath.Join(srv.recorder.dir, "list_notification_channels.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := string(b)
+	for _, secret := range []string{token, "xyz"} {
+		if strings.Contains(saved, secret) {
+			t.Errorf("fixture contains %q:\n%s", secret, saved)
+		}
+	}
+	if !strings.Contains(saved, `"webhookUrl": "REDACTED"`) {
+		t.Errorf("webhookUrl is not redacted:\n%s", saved)
+	}
+
+	// Mutations are answered with fixtures and never reach Uptrace.
+	gotPath = ""
+	_, err = client.DeleteNotificationChannel(t.Context(), &uptraceapi.DeleteNotificationChannelRequestOptions{
+		PathParams: &uptraceapi.DeleteNotificationChannelPath{ProjectID: ProjectID, ChannelID: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "" {
+		t.Errorf("DELETE was forwarded to %s", gotPath)
+	}
+}
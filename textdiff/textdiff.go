@@ -0,0 +1,135 @@
+// Package textdiff renders line-based unified diffs of small documents such as
+// dashboard and monitor YAML.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Context is the number of unchanged lines shown around each change.
+const Context = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	line string
+	// aLine and bLine are the 0-based line numbers in a and b before this op.
+	aLine, bLine int
+}
+
+// Unified returns a unified diff turning a into b, or "" when they are equal.
+// The documents are expected to be a few thousand lines at most.
+func Unified(aName, bName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", aName, bName)
+	for _, hunk := range hunks(ops) {
+		first := hunk[0]
+		var aCount, bCount int
+		for _, o := range hunk {
+			if o.kind != opInsert {
+				aCount++
+			}
+			if o.kind != opDelete {
+				bCount++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(first.aLine, aCount), hunkRange(first.bLine, bCount))
+		for _, o := range hunk {
+			sb.WriteByte(byte(o.kind))
+			sb.WriteString(o.line)
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// diffLines computes an edit script from the longest common subsequence of a and b.
+func diffLines(a, b []string) []op {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]op, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, op{opEqual, a[i], i, j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{opDelete, a[i], i, j})
+			i++
+		default:
+			ops = append(ops, op{opInsert, b[j], i, j})
+			j++
+		}
+	}
+	return ops
+}
+
+// hunks groups changes that are at most 2*Context unchanged lines apart, keeping
+// Context lines of surrounding text.
+func hunks(ops []op) [][]op {
+	var result [][]op
+	start, end := -1, -1
+	for i, o := range ops {
+		if o.kind == opEqual {
+			continue
+		}
+		if start >= 0 && i-end > 2*Context {
+			result = append(result, ops[start:min(end+Context+1, len(ops))])
+			start = -1
+		}
+		if start < 0 {
+			start = max(i-Context, 0)
+		}
+		end = i
+	}
+	if start >= 0 {
+		result = append(result, ops[start:min(end+Context+1, len(ops))])
+	}
+	return result
+}
+
+func hunkRange(line, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", line)
+	}
+	if count == 1 {
+		return fmt.Sprint(line + 1)
+	}
+	return fmt.Sprintf("%d,%d", line+1, count)
+}
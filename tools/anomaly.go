@@ -0,0 +1,243 @@
+package tools
+
+import (
+	"math"
+	"slices"
+)
+
+const (
+	anomalyZScore      = "zscore"
+	anomalyMAD         = "mad"
+	anomalySeasonal    = "seasonal"
+	anomalyChangePoint = "changepoint"
+)
+
+var anomalyMethods = []string{anomalyZScore, anomalyMAD, anomalySeasonal, anomalyChangePoint}
+
+// seasonalPeriodMs is the period used by the seasonal detector.
+const seasonalPeriodMs = 24 * 60 * 60 * 1000
+
+// madScale and meanADScale make the median and mean absolute deviations comparable
+// to the standard deviation of a normal distribution.
+const (
+	madScale    = 1.4826
+	meanADScale = 1.2533
+)
+
+// anomalyPoint is a single flagged point produced by a detector.
+type anomalyPoint struct {
+	index    int
+	score    float64
+	baseline float64
+}
+
+// detectZScore flags points whose distance from the mean exceeds threshold
+// standard deviations.
+func detectZScore(values []float64, threshold float64) []anomalyPoint {
+	mean, std := meanStd(values)
+	if std == 0 {
+		return nil
+	}
+
+	var points []anomalyPoint
+	for i, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		if z := (v - mean) / std; math.Abs(z) >= threshold {
+			points = append(points, anomalyPoint{index: i, score: z, baseline: mean})
+		}
+	}
+	return points
+}
+
+// detectMAD flags points whose robust z-score, computed from the median and the
+// median absolute deviation, exceeds threshold.
+func detectMAD(values []float64, threshold float64) []anomalyPoint {
+	median, scale := robustScale(values)
+	if scale == 0 {
+		return nil
+	}
+
+	var points []anomalyPoint
+	for i, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		if z := (v - median) / scale; math.Abs(z) >= threshold {
+			points = append(points, anomalyPoint{index: i, score: z, baseline: median})
+		}
+	}
+	return points
+}
+
+// detectSeasonal removes a seasonal component with the given period (in points)
+// and applies the MAD detector to the residuals. It needs at least two full periods.
+func detectSeasonal(values []float64, period int, threshold float64) []anomalyPoint {
+	if period < 2 || len(values) < 2*period {
+		return nil
+	}
+
+	level, _ := medianMAD(values)
+
+	phases := make([][]float64, period)
+	for i, v := range values {
+		if !math.IsNaN(v) {
+			phases[i%period] = append(phases[i%period], v-level)
+		}
+	}
+	seasonal := make([]float64, period)
+	for i, phase := range phases {
+		seasonal[i], _ = medianMAD(phase)
+	}
+
+	residuals := make([]float64, len(values))
+	for i, v := range values {
+		residuals[i] = v - level - seasonal[i%period]
+	}
+
+	median, scale := robustScale(residuals)
+	if scale == 0 {
+		return nil
+	}
+
+	var points []anomalyPoint
+	for i, r := range residuals {
+		if math.IsNaN(r) {
+			continue
+		}
+		if z := (r - median) / scale; math.Abs(z) >= threshold {
+			points = append(points, anomalyPoint{
+				index:    i,
+				score:    z,
+				baseline: level + seasonal[i%period],
+			})
+		}
+	}
+	return points
+}
+
+// detectChangePoint finds the split that maximizes the shift in mean between the
+// two segments and reports the segment after the split when the shift, measured in
+// pooled standard deviations, exceeds threshold.
+func detectChangePoint(values []float64, threshold float64) []anomalyPoint {
+	const minSegment = 3
+
+	clean := make([]float64, 0, len(values))
+	indexes := make([]int, 0, len(values))
+	for i, v := range values {
+		if !math.IsNaN(v) {
+			clean = append(clean, v)
+			indexes = append(indexes, i)
+		}
+	}
+	if len(clean) < 2*minSegment {
+		return nil
+	}
+
+	bestSplit := -1
+	var bestScore, bestBefore float64
+	for split := minSegment; split <= len(clean)-minSegment; split++ {
+		before, beforeStd := meanStd(clean[:split])
+		after, afterStd := meanStd(clean[split:])
+		pooled := math.Sqrt((beforeStd*beforeStd + afterStd*afterStd) / 2)
+		if pooled == 0 {
+			pooled = math.Abs(before) * 0.01
+		}
+		if pooled == 0 {
+			continue
+		}
+		if score := (after - before) / pooled; math.Abs(score) > math.Abs(bestScore) {
+			bestSplit, bestScore, bestBefore = split, score, before
+		}
+	}
+	if bestSplit == -1 || math.Abs(bestScore) < threshold {
+		return nil
+	}
+
+	points := make([]anomalyPoint, 0, len(clean)-bestSplit)
+	for _, idx := range indexes[bestSplit:] {
+		points = append(points, anomalyPoint{index: idx, score: bestScore, baseline: bestBefore})
+	}
+	return points
+}
+
+func meanStd(values []float64) (mean, std float64) {
+	var n int
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			mean += v
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	mean /= float64(n)
+
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			std += (v - mean) * (v - mean)
+		}
+	}
+	return mean, math.Sqrt(std / float64(n))
+}
+
+func medianMAD(values []float64) (median, mad float64) {
+	clean := make([]float64, 0, len(values))
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			clean = append(clean, v)
+		}
+	}
+	if len(clean) == 0 {
+		return 0, 0
+	}
+	median = quantile(clean, 0.5)
+
+	for i, v := range clean {
+		clean[i] = math.Abs(v - median)
+	}
+	return median, quantile(clean, 0.5)
+}
+
+// robustScale returns the median of values and a robust estimate of their standard
+// deviation. When more than half of the values are equal the MAD is zero, so the
+// mean absolute deviation is used instead.
+func robustScale(values []float64) (median, scale float64) {
+	median, mad := medianMAD(values)
+	if mad != 0 {
+		return median, mad * madScale
+	}
+
+	var sum float64
+	var n int
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			sum += math.Abs(v - median)
+			n++
+		}
+	}
+	if n == 0 {
+		return median, 0
+	}
+	return median, sum / float64(n) * meanADScale
+}
+
+// quantile returns the q-quantile of values using linear interpolation, with q
+// clamped to [0, 1]. The slice is sorted in place.
+func quantile(values []float64, q float64) float64 {
+	if len(values) == 0 || math.IsNaN(q) {
+		return math.NaN()
+	}
+	slices.Sort(values)
+	q = min(max(q, 0), 1)
+
+	pos := q * float64(len(values)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	if lo == hi {
+		return values[lo]
+	}
+	return values[lo] + (values[hi]-values[lo])*(pos-float64(lo))
+}
@@ -0,0 +1,266 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/monitor"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+type BacktestMonitorTool struct {
+	client *uptraceapi.Client
+	conf   *appconf.Config
+}
+
+func NewBacktestMonitorTool(client *uptraceapi.Client, conf *appconf.Config) *BacktestMonitorTool {
+	return &BacktestMonitorTool{
+		client: client,
+		conf:   conf,
+	}
+}
+
+func (t *BacktestMonitorTool) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "backtest_monitor",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Backtest metric monitor",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+		Description: "Replay a metric monitor over historical data (the last 14 days by default) before " +
+			"creating it. Returns when the monitor would have fired and resolved, the total number of " +
+			"alerts and, for every configured bound, the threshold that would have produced the target " +
+			"number of alerts per week. The monitor is evaluated locally at the interval of the " +
+			"timeseries query, so only monitors over uptrace_tracing_spans and uptrace_tracing_logs " +
+			"can be replayed, e.g. metrics [\"uptrace_tracing_spans as $spans\"] with query " +
+			"per_min(count($spans{_status_code=\"error\"})) | group by service_name.",
+	}, t.handler)
+}
+
+type backtestMonitorInput struct {
+	ProjectID       int64                  `json:"project_id,omitempty" jsonschema:"Uptrace project ID."`
+	TimeStart       time.Time              `json:"time_start,omitempty" jsonschema:"Start time (inclusive) as RFC3339 timestamp. Defaults to 14 days before time_end."`
+	TimeEnd         time.Time              `json:"time_end,omitempty" jsonschema:"End time (exclusive) as RFC3339 timestamp."`
+	Metrics         []string               `json:"metrics" jsonschema:"Monitor metrics as \"metric_name as $alias\"." validate:"required"`
+	Query           string                 `json:"query" jsonschema:"Monitor query using the metric aliases." validate:"required"`
+	MinAllowedValue *float64               `json:"min_allowed_value,omitempty" jsonschema:"Fire when the value drops below this bound."`
+	MaxAllowedValue *float64               `json:"max_allowed_value,omitempty" jsonschema:"Fire when the value rises above this bound."`
+	NumEvalPoints   int                    `json:"num_eval_points,omitempty" jsonschema:"Consecutive points outside the bounds that fire an alert. Defaults to 5."`
+	Flapping        *backtestFlappingInput `json:"flapping,omitempty" jsonschema:"Narrower range the value must return to before an alert resolves."`
+	NullsMode       string                 `json:"nulls_mode,omitempty" jsonschema:"How missing points are evaluated: allow (ignored, the default), forbid (outside the bounds) or convert (zero)."`
+	TimeOffset      string                 `json:"time_offset,omitempty" jsonschema:"Delay before a point is evaluated, e.g. 5m."`
+	Where           string                 `json:"where,omitempty" jsonschema:"Additional WHERE clause appended to the query."`
+	TargetPerWeek   float64                `json:"target_alerts_per_week,omitempty" jsonschema:"Alert frequency the suggested thresholds aim for. Defaults to 1."`
+	MaxAlerts       int                    `json:"max_alerts,omitempty" jsonschema:"Maximum number of alerts to list. Defaults to 50."`
+
+	timeRangeOptions
+}
+
+type backtestFlappingInput struct {
+	MinAllowedValue *float64 `json:"min_allowed_value,omitempty"`
+	MaxAllowedValue *float64 `json:"max_allowed_value,omitempty"`
+}
+
+type backtestMonitorOutput struct {
+	TimeStart     time.Time             `json:"time_start"`
+	TimeEnd       time.Time             `json:"time_end"`
+	IntervalMs    int64                 `json:"interval_ms" jsonschema:"Interval between evaluated points."`
+	Query         string                `json:"query" jsonschema:"Span query the monitor query was translated to."`
+	NumSeries     int                   `json:"num_series"`
+	TotalAlerts   int                   `json:"total_alerts"`
+	AlertsPerWeek float64               `json:"alerts_per_week"`
+	StillFiring   int                   `json:"still_firing" jsonschema:"Alerts that had not resolved by time_end."`
+	Alerts        []backtestAlert       `json:"alerts"`
+	Truncated     int                   `json:"truncated,omitempty" jsonschema:"Number of alerts omitted because of max_alerts."`
+	Suggestions   []thresholdSuggestion `json:"suggestions,omitempty"`
+	Notes         []string              `json:"notes,omitempty"`
+}
+
+type backtestAlert struct {
+	Group      map[string]any `json:"group,omitempty"`
+	Column     string         `json:"column"`
+	FiredAt    time.Time      `json:"fired_at"`
+	ResolvedAt *time.Time     `json:"resolved_at,omitempty"`
+	Duration   string         `json:"duration,omitempty"`
+	PeakValue  *float64       `json:"peak_value,omitempty" jsonschema:"Most extreme value while firing; empty when the alert fired on missing data."`
+}
+
+type thresholdSuggestion struct {
+	Param  string  `json:"param" jsonschema:"min_allowed_value or max_allowed_value."`
+	Value  float64 `json:"value"`
+	Alerts int     `json:"alerts" jsonschema:"Alerts the monitor would have fired with this value."`
+}
+
+func (t *BacktestMonitorTool) handler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input *backtestMonitorInput,
+) (*mcp.CallToolResult, *backtestMonitorOutput, error) {
+	projectID := input.ProjectID
+	if projectID == 0 {
+		projectID = t.conf.Uptrace.ProjectID
+	}
+	if input.TimeRange == "" && input.TimeStart.IsZero() {
+		if input.TimeEnd.IsZero() {
+			input.TimeEnd = time.Now()
+		}
+		input.TimeStart = input.TimeEnd.Add(-14 * 24 * time.Hour)
+	}
+	if err := input.resolveTimeRange(t.conf, &input.TimeStart, &input.TimeEnd); err != nil {
+		return nil, nil, err
+	}
+	if input.TargetPerWeek == 0 {
+		input.TargetPerWeek = 1
+	}
+	if input.MaxAlerts == 0 {
+		input.MaxAlerts = 50
+	}
+	if input.MaxAlerts < 1 {
+		return nil, nil, fmt.Errorf("max_alerts must be at least 1, got %d", input.MaxAlerts)
+	}
+
+	if input.MinAllowedValue == nil && input.MaxAllowedValue == nil {
+		return nil, nil, fmt.Errorf("min_allowed_value or max_allowed_value is required")
+	}
+	if input.NullsMode != "" {
+		if err := uptraceapi.MetricMonitorParamsNullsMode(input.NullsMode).Validate(); err != nil {
+			return nil, nil, fmt.Errorf("unknown nulls_mode %q (supported: allow, forbid, convert)", input.NullsMode)
+		}
+	}
+	var offset time.Duration
+	if input.TimeOffset != "" {
+		var err error
+		if offset, err = parseDuration(input.TimeOffset); err != nil {
+			return nil, nil, fmt.Errorf("invalid time_offset: %w", err)
+		}
+	}
+
+	params := &monitor.EvalParams{
+		MinAllowedValue: input.MinAllowedValue,
+		MaxAllowedValue: input.MaxAllowedValue,
+		NumEvalPoints:   input.NumEvalPoints,
+		NullsMode:       input.NullsMode,
+	}
+	if input.Flapping != nil {
+		params.FlappingMin = input.Flapping.MinAllowedValue
+		params.FlappingMax = input.Flapping.MaxAllowedValue
+	}
+
+	spanQuery, system, err := monitor.SpanQuery(input.Metrics, input.Query)
+	if err != nil {
+		return nil, nil, err
+	}
+	query := &uptraceapi.QueryTimeseriesQuery{
+		TimeStart: input.TimeStart,
+		TimeEnd:   input.TimeEnd,
+		Query:     &spanQuery,
+		System:    system,
+	}
+	if input.Where != "" {
+		query.Where = &input.Where
+	}
+
+	resp, err := t.client.QueryTimeseries(ctx, &uptraceapi.QueryTimeseriesRequestOptions{
+		PathParams: &uptraceapi.QueryTimeseriesPath{ProjectID: projectID},
+		Query:      query,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allSeries := timeseriesSeries(resp)
+	out := &backtestMonitorOutput{
+		TimeStart:  input.TimeStart,
+		TimeEnd:    input.TimeEnd,
+		IntervalMs: resp.Interval,
+		Query:      spanQuery,
+		NumSeries:  len(allSeries),
+		Alerts:     []backtestAlert{},
+	}
+	if len(allSeries) == 0 {
+		out.Notes = append(out.Notes, "The query returned no data for the time range.")
+		return nil, out, nil
+	}
+
+	// pointTime is when the point at index i is complete and, after the offset,
+	// evaluated.
+	pointTime := func(i int) time.Time {
+		return time.UnixMilli(int64(resp.Time[i]) + resp.Interval).Add(offset).UTC()
+	}
+
+	values := make([][]float64, len(allSeries))
+	for i, s := range allSeries {
+		if len(s.Values) > len(resp.Time) {
+			s.Values = s.Values[:len(resp.Time)]
+		}
+		values[i] = s.Values
+
+		for _, a := range monitor.Simulate(s.Values, params) {
+			alert := backtestAlert{
+				Group:   s.Group,
+				Column:  s.Column,
+				FiredAt: pointTime(a.Fired),
+			}
+			if !math.IsNaN(a.Peak) {
+				peak := roundTo(a.Peak, 4)
+				alert.PeakValue = &peak
+			}
+			if a.Resolved >= 0 {
+				resolved := pointTime(a.Resolved)
+				alert.ResolvedAt = &resolved
+				alert.Duration = resolved.Sub(alert.FiredAt).String()
+			} else {
+				out.StillFiring++
+			}
+			out.Alerts = append(out.Alerts, alert)
+		}
+	}
+
+	sort.SliceStable(out.Alerts, func(i, j int) bool {
+		return out.Alerts[i].FiredAt.Before(out.Alerts[j].FiredAt)
+	})
+
+	weeks := input.TimeEnd.Sub(input.TimeStart).Hours() / (7 * 24)
+	out.TotalAlerts = len(out.Alerts)
+	if weeks > 0 {
+		out.AlertsPerWeek = roundTo(float64(out.TotalAlerts)/weeks, 2)
+	}
+	if len(out.Alerts) > input.MaxAlerts {
+		out.Truncated = len(out.Alerts) - input.MaxAlerts
+		out.Alerts = out.Alerts[:input.MaxAlerts]
+	}
+
+	target := int(input.TargetPerWeek * weeks)
+	for _, b := range []struct {
+		param string
+		bound monitor.Bound
+		set   bool
+	}{
+		{"max_allowed_value", monitor.BoundMax, input.MaxAllowedValue != nil},
+		{"min_allowed_value", monitor.BoundMin, input.MinAllowedValue != nil},
+	} {
+		if !b.set {
+			continue
+		}
+		value, alerts, ok := monitor.SuggestThreshold(values, params, b.bound, target)
+		if !ok {
+			out.Notes = append(out.Notes, fmt.Sprintf(
+				"No observed value keeps %s at %d alert(s) or fewer.", b.param, target))
+			continue
+		}
+		out.Suggestions = append(out.Suggestions, thresholdSuggestion{
+			Param:  b.param,
+			Value:  roundTo(value, 4),
+			Alerts: alerts,
+		})
+	}
+
+	return nil, out, nil
+}
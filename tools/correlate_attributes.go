@@ -0,0 +1,283 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+type CorrelateAttributesTool struct {
+	client *uptraceapi.Client
+	conf   *appconf.Config
+}
+
+func NewCorrelateAttributesTool(client *uptraceapi.Client, conf *appconf.Config) *CorrelateAttributesTool {
+	return &CorrelateAttributesTool{
+		client: client,
+		conf:   conf,
+	}
+}
+
+func (t *CorrelateAttributesTool) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "correlate_attributes",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Correlate attributes",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+		Description: "Find what slow or failing spans have in common. Samples spans of one span group " +
+			"(or any UQL filter), splits them into a bad set (slower than a duration percentile, or errored) " +
+			"and a baseline set, then compares the distribution of every span attribute between the two. " +
+			"Returns attribute values ranked by lift, e.g. k8s_node_name = node-7 in 80% of slow spans " +
+			"versus 5% of the baseline.",
+	}, t.handler)
+}
+
+type correlateAttributesInput struct {
+	ProjectID  int64     `json:"project_id,omitempty" jsonschema:"Uptrace project ID."`
+	TimeStart  time.Time `json:"time_start,omitempty" jsonschema:"Start time (inclusive) as RFC3339 timestamp."`
+	TimeEnd    time.Time `json:"time_end,omitempty" jsonschema:"End time (exclusive) as RFC3339 timestamp."`
+	GroupID    string    `json:"group_id,omitempty" jsonschema:"Span group ID (the groupId field of spans or _group_id of span groups)."`
+	Query      string    `json:"query,omitempty" jsonschema:"Additional UQL filter (e.g., where service_name = \"checkout\")."`
+	System     []string  `json:"system,omitempty" jsonschema:"Filter by system (e.g., httpserver:all)."`
+	Mode       string    `json:"mode,omitempty" jsonschema:"How to select bad spans: slow or error. Defaults to slow."`
+	Percentile float64   `json:"percentile,omitempty" jsonschema:"In slow mode, spans at or above this duration percentile are bad. Defaults to 90."`
+	Sample     int       `json:"sample,omitempty" jsonschema:"Number of spans to sample per set. Defaults to 1000."`
+	MinSupport float64   `json:"min_support,omitempty" jsonschema:"Minimum percentage of bad spans that must have a value for it to be reported. Defaults to 10."`
+	MaxResults int       `json:"max_results,omitempty" jsonschema:"Maximum number of attribute values to return. Defaults to 20."`
+
+	timeRangeOptions
+}
+
+type correlateAttributesOutput struct {
+	TimeStart         time.Time              `json:"time_start"`
+	TimeEnd           time.Time              `json:"time_end"`
+	Mode              string                 `json:"mode"`
+	BadSpans          int                    `json:"bad_spans"`
+	BaselineSpans     int                    `json:"baseline_spans"`
+	DurationThreshold float64                `json:"duration_threshold_ms,omitempty" jsonschema:"Duration separating bad spans from the baseline in slow mode."`
+	Correlations      []attributeCorrelation `json:"correlations"`
+	SkippedAttributes []string               `json:"skipped_attributes,omitempty" jsonschema:"Attributes skipped because they are unique per span (IDs, timestamps)."`
+}
+
+type attributeCorrelation struct {
+	Attribute     string  `json:"attribute"`
+	Value         string  `json:"value"`
+	BadPct        float64 `json:"bad_pct" jsonschema:"Percentage of bad spans with this value."`
+	BaselinePct   float64 `json:"baseline_pct" jsonschema:"Percentage of baseline spans with this value."`
+	Lift          float64 `json:"lift" jsonschema:"How many times more frequent the value is among bad spans."`
+	BadCount      int     `json:"bad_count"`
+	BaselineCount int     `json:"baseline_count"`
+}
+
+func (t *CorrelateAttributesTool) handler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input *correlateAttributesInput,
+) (*mcp.CallToolResult, *correlateAttributesOutput, error) {
+	projectID := input.ProjectID
+	if projectID == 0 {
+		projectID = t.conf.Uptrace.ProjectID
+	}
+	if err := input.resolveTimeRange(t.conf, &input.TimeStart, &input.TimeEnd); err != nil {
+		return nil, nil, err
+	}
+	if input.Mode == "" {
+		input.Mode = "slow"
+	}
+	if input.Percentile == 0 {
+		input.Percentile = 90
+	}
+	if input.Sample == 0 {
+		input.Sample = 1000
+	}
+	if input.MinSupport == 0 {
+		input.MinSupport = 10
+	}
+	if input.MaxResults == 0 {
+		input.MaxResults = 20
+	}
+	if input.MaxResults < 1 {
+		return nil, nil, fmt.Errorf("max_results must be at least 1, got %d", input.MaxResults)
+	}
+	if input.Percentile <= 0 || input.Percentile > 100 {
+		return nil, nil, fmt.Errorf("percentile must be greater than 0 and at most 100, got %g", input.Percentile)
+	}
+	if input.Sample < 1 {
+		return nil, nil, fmt.Errorf("sample must be at least 1, got %d", input.Sample)
+	}
+
+	var filters []string
+	if input.GroupID != "" {
+		filters = append(filters, fmt.Sprintf("where _group_id = %s", strconv.Quote(input.GroupID)))
+	}
+	if input.Query != "" {
+		filters = append(filters, input.Query)
+	}
+
+	out := &correlateAttributesOutput{
+		TimeStart: input.TimeStart,
+		TimeEnd:   input.TimeEnd,
+		Mode:      input.Mode,
+	}
+	var bad, baseline []uptraceapi.Span
+
+	switch input.Mode {
+	case "slow":
+		spans, err := t.listSpans(ctx, projectID, input, filters)
+		if err != nil {
+			return nil, nil, err
+		}
+		durations := make([]float64, len(spans))
+		for i := range spans {
+			durations[i] = spans[i].Duration
+		}
+		threshold := quantile(durations, input.Percentile/100)
+		out.DurationThreshold = threshold
+
+		for _, span := range spans {
+			if span.Duration >= threshold {
+				bad = append(bad, span)
+			} else {
+				baseline = append(baseline, span)
+			}
+		}
+	case "error":
+		var err error
+		bad, err = t.listSpans(ctx, projectID, input,
+			append(filters, `where _status_code = "error"`))
+		if err != nil {
+			return nil, nil, err
+		}
+		baseline, err = t.listSpans(ctx, projectID, input,
+			append(filters, `where _status_code != "error"`))
+		if err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown mode %q (supported: slow, error)", input.Mode)
+	}
+
+	out.BadSpans = len(bad)
+	out.BaselineSpans = len(baseline)
+	if len(bad) == 0 || len(baseline) == 0 {
+		out.Correlations = []attributeCorrelation{}
+		return nil, out, nil
+	}
+
+	out.Correlations, out.SkippedAttributes = correlateAttrs(bad, baseline, input.MinSupport/100)
+	if len(out.Correlations) > input.MaxResults {
+		out.Correlations = out.Correlations[:input.MaxResults]
+	}
+	return nil, out, nil
+}
+
+func (t *CorrelateAttributesTool) listSpans(
+	ctx context.Context,
+	projectID int64,
+	input *correlateAttributesInput,
+	filters []string,
+) ([]uptraceapi.Span, error) {
+	q := &uptraceapi.ListSpansQuery{
+		TimeStart: input.TimeStart,
+		TimeEnd:   input.TimeEnd,
+		System:    input.System,
+	}
+	if len(filters) > 0 {
+		query := strings.Join(filters, " | ")
+		q.Query = &query
+	}
+	limit := uptraceapi.Limit(input.Sample)
+	q.Limit = &limit
+
+	resp, err := t.client.ListSpans(ctx, &uptraceapi.ListSpansRequestOptions{
+		PathParams: &uptraceapi.ListSpansPath{ProjectID: projectID},
+		Query:      q,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Spans, nil
+}
+
+// correlateAttrs compares attribute value frequencies between the bad and baseline
+// spans and returns values ranked by lift. Attributes that are unique per span are
+// skipped since they can't be shared by a meaningful fraction of bad spans.
+func correlateAttrs(
+	bad, baseline []uptraceapi.Span, minSupport float64,
+) ([]attributeCorrelation, []string) {
+	badCounts := countAttrValues(bad)
+	baseCounts := countAttrValues(baseline)
+
+	var skipped []string
+	var out []attributeCorrelation
+	for attr, values := range badCounts {
+		distinct := len(values) + len(baseCounts[attr])
+		if distinct > 20 && float64(distinct) > 0.5*float64(len(bad)+len(baseline)) {
+			skipped = append(skipped, attr)
+			continue
+		}
+
+		for value, badCount := range values {
+			badPct := float64(badCount) / float64(len(bad))
+			if badPct < minSupport {
+				continue
+			}
+
+			baseCount := baseCounts[attr][value]
+			basePct := float64(baseCount) / float64(len(baseline))
+			// Laplace smoothing keeps the lift finite for values never seen in the baseline.
+			smoothed := (float64(baseCount) + 1) / (float64(len(baseline)) + 1)
+
+			out = append(out, attributeCorrelation{
+				Attribute:     attr,
+				Value:         value,
+				BadPct:        roundTo(badPct*100, 1),
+				BaselinePct:   roundTo(basePct*100, 1),
+				Lift:          roundTo(badPct/smoothed, 2),
+				BadCount:      badCount,
+				BaselineCount: baseCount,
+			})
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Lift != out[j].Lift {
+			return out[i].Lift > out[j].Lift
+		}
+		return out[i].BadPct > out[j].BadPct
+	})
+	sort.Strings(skipped)
+	return out, skipped
+}
+
+// countAttrValues counts attribute values across spans, keyed by attribute name
+// without the type suffix.
+func countAttrValues(spans []uptraceapi.Span) map[string]map[string]int {
+	counts := make(map[string]map[string]int)
+	for _, span := range spans {
+		for key, value := range span.Attrs {
+			name := attrName(key)
+			if counts[name] == nil {
+				counts[name] = make(map[string]int)
+			}
+			counts[name][fmt.Sprint(value)]++
+		}
+	}
+	return counts
+}
+
+// attrName strips the type suffix from attribute keys such as service_name::str.
+func attrName(key string) string {
+	name, _, _ := strings.Cut(key, "::")
+	return name
+}
@@ -68,6 +68,10 @@ func (t *CreateDashboardTool) handler(
 		}, nil, nil
 	}
 
+	if res := dashboardLintResult(input.Body); res != nil {
+		return res, nil, nil
+	}
+
 	opts := &uptraceapi.CreateDashboardFromYamlRequestOptions{
 		PathParams: &uptraceapi.CreateDashboardFromYamlPath{
 			ProjectID: projectID,
@@ -0,0 +1,242 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+type DetectAnomaliesTool struct {
+	client *uptraceapi.Client
+	conf   *appconf.Config
+}
+
+func NewDetectAnomaliesTool(client *uptraceapi.Client, conf *appconf.Config) *DetectAnomaliesTool {
+	return &DetectAnomaliesTool{
+		client: client,
+		conf:   conf,
+	}
+}
+
+func (t *DetectAnomaliesTool) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "detect_anomalies",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Detect anomalies",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+		Description: "Run a UQL timeseries query and detect anomalies in every returned series. " +
+			"Supported detectors: zscore (distance from the mean), mad (robust distance from the median), " +
+			"seasonal (residuals after removing a daily pattern, needs at least 2 days of data) and " +
+			"changepoint (the most significant level shift). Returns only anomalous intervals with " +
+			"severity, group labels and the expected baseline value instead of raw datapoints.",
+	}, t.handler)
+}
+
+type detectAnomaliesInput struct {
+	ProjectID   int64     `json:"project_id,omitempty" jsonschema:"Uptrace project ID."`
+	TimeStart   time.Time `json:"time_start,omitempty" jsonschema:"Start time (inclusive) as RFC3339 timestamp."`
+	TimeEnd     time.Time `json:"time_end,omitempty" jsonschema:"End time (exclusive) as RFC3339 timestamp."`
+	Query       string    `json:"query" jsonschema:"UQL aggregation query (e.g., perMin(count()) | group by service_name)." validate:"required"`
+	Where       string    `json:"where,omitempty" jsonschema:"Additional WHERE clause appended to the query."`
+	System      []string  `json:"system,omitempty" jsonschema:"Filter by system (e.g., log:error, db:postgresql)."`
+	Column      []string  `json:"column,omitempty" jsonschema:"Aggregate column names to analyze. Defaults to all aggregated columns."`
+	Methods     []string  `json:"methods,omitempty" jsonschema:"Detectors to apply: zscore, mad, seasonal, changepoint. Defaults to all."`
+	Threshold   float64   `json:"threshold,omitempty" jsonschema:"Score threshold for flagging a point. Defaults to 3."`
+	Limit       int       `json:"limit,omitempty" jsonschema:"Maximum number of groups to query."`
+	MaxFindings int       `json:"max_findings,omitempty" jsonschema:"Maximum number of anomalous intervals to return. Defaults to 50."`
+
+	timeRangeOptions
+}
+
+type detectAnomaliesOutput struct {
+	TimeStart  time.Time `json:"time_start"`
+	TimeEnd    time.Time `json:"time_end"`
+	IntervalMs int64     `json:"interval_ms"`
+	NumSeries  int       `json:"num_series"`
+	Anomalies  []anomaly `json:"anomalies"`
+	Truncated  int       `json:"truncated,omitempty" jsonschema:"Number of findings omitted because of max_findings."`
+}
+
+type anomaly struct {
+	Group    map[string]any `json:"group,omitempty"`
+	Column   string         `json:"column"`
+	Unit     string         `json:"unit,omitempty"`
+	Method   string         `json:"method"`
+	Start    time.Time      `json:"start"`
+	End      time.Time      `json:"end"`
+	Points   int            `json:"points"`
+	Severity string         `json:"severity" jsonschema:"low, medium or high."`
+	Score    float64        `json:"score" jsonschema:"Largest absolute detector score within the interval."`
+	Value    float64        `json:"value" jsonschema:"Most extreme observed value within the interval."`
+	Baseline float64        `json:"baseline" jsonschema:"Expected value according to the detector."`
+}
+
+func (t *DetectAnomaliesTool) handler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input *detectAnomaliesInput,
+) (*mcp.CallToolResult, *detectAnomaliesOutput, error) {
+	projectID := input.ProjectID
+	if projectID == 0 {
+		projectID = t.conf.Uptrace.ProjectID
+	}
+	if err := input.resolveTimeRange(t.conf, &input.TimeStart, &input.TimeEnd); err != nil {
+		return nil, nil, err
+	}
+	if input.Threshold == 0 {
+		input.Threshold = 3
+	}
+	if input.MaxFindings == 0 {
+		input.MaxFindings = 50
+	}
+	if input.MaxFindings < 1 {
+		return nil, nil, fmt.Errorf("max_findings must be at least 1, got %d", input.MaxFindings)
+	}
+
+	methods := input.Methods
+	if len(methods) == 0 {
+		methods = anomalyMethods
+	}
+	for _, m := range methods {
+		if !slices.Contains(anomalyMethods, m) {
+			return nil, nil, fmt.Errorf("unknown anomaly detector %q (supported: %v)", m, anomalyMethods)
+		}
+	}
+
+	query := &uptraceapi.QueryTimeseriesQuery{
+		TimeStart: input.TimeStart,
+		TimeEnd:   input.TimeEnd,
+		Query:     &input.Query,
+		System:    input.System,
+		Column:    input.Column,
+	}
+	if input.Where != "" {
+		query.Where = &input.Where
+	}
+	if input.Limit != 0 {
+		limit := uptraceapi.Limit(input.Limit)
+		query.Limit = &limit
+	} else if t.conf.Default.Limit != 0 {
+		limit := uptraceapi.Limit(t.conf.Default.Limit)
+		query.Limit = &limit
+	}
+
+	resp, err := t.client.QueryTimeseries(ctx, &uptraceapi.QueryTimeseriesRequestOptions{
+		PathParams: &uptraceapi.QueryTimeseriesPath{ProjectID: projectID},
+		Query:      query,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allSeries := timeseriesSeries(resp)
+	out := &detectAnomaliesOutput{
+		TimeStart:  input.TimeStart,
+		TimeEnd:    input.TimeEnd,
+		IntervalMs: resp.Interval,
+		NumSeries:  len(allSeries),
+		Anomalies:  []anomaly{},
+	}
+
+	period := 0
+	if resp.Interval > 0 {
+		period = int(seasonalPeriodMs / resp.Interval)
+	}
+
+	for _, s := range allSeries {
+		if len(s.Values) > len(resp.Time) {
+			s.Values = s.Values[:len(resp.Time)]
+		}
+		for _, method := range methods {
+			var points []anomalyPoint
+			switch method {
+			case anomalyZScore:
+				points = detectZScore(s.Values, input.Threshold)
+			case anomalyMAD:
+				points = detectMAD(s.Values, input.Threshold)
+			case anomalySeasonal:
+				points = detectSeasonal(s.Values, period, input.Threshold)
+			case anomalyChangePoint:
+				points = detectChangePoint(s.Values, input.Threshold)
+			}
+			out.Anomalies = append(out.Anomalies,
+				anomalyIntervals(s, method, points, resp.Time, resp.Interval, input.Threshold)...)
+		}
+	}
+
+	sort.Slice(out.Anomalies, func(i, j int) bool {
+		return out.Anomalies[i].Score > out.Anomalies[j].Score
+	})
+	if len(out.Anomalies) > input.MaxFindings {
+		out.Truncated = len(out.Anomalies) - input.MaxFindings
+		out.Anomalies = out.Anomalies[:input.MaxFindings]
+	}
+
+	return nil, out, nil
+}
+
+// anomalyIntervals merges consecutive flagged points into intervals.
+func anomalyIntervals(
+	s series,
+	method string,
+	points []anomalyPoint,
+	times []float64,
+	intervalMs int64,
+	threshold float64,
+) []anomaly {
+	var out []anomaly
+	for i := 0; i < len(points); {
+		j := i + 1
+		for j < len(points) && points[j].index == points[j-1].index+1 {
+			j++
+		}
+		group := points[i:j]
+
+		a := anomaly{
+			Group:  s.Group,
+			Column: s.Column,
+			Unit:   s.Unit,
+			Method: method,
+			Start:  time.UnixMilli(int64(times[group[0].index])).UTC(),
+			End:    time.UnixMilli(int64(times[group[len(group)-1].index]) + intervalMs).UTC(),
+			Points: len(group),
+		}
+		var extreme float64
+		for _, p := range group {
+			if score := math.Abs(p.score); score > a.Score {
+				a.Score = score
+			}
+			if dev := math.Abs(s.Values[p.index] - p.baseline); dev >= extreme {
+				extreme = dev
+				a.Value = s.Values[p.index]
+				a.Baseline = p.baseline
+			}
+		}
+		a.Severity = anomalySeverity(a.Score, threshold)
+
+		out = append(out, a)
+		i = j
+	}
+	return out
+}
+
+func anomalySeverity(score, threshold float64) string {
+	switch {
+	case score >= 2*threshold:
+		return "high"
+	case score >= 1.5*threshold:
+		return "medium"
+	default:
+		return "low"
+	}
+}
@@ -0,0 +1,592 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+const (
+	formatJSON          = "json"
+	formatCompact       = "compact"
+	formatMarkdownTable = "markdown_table"
+	formatCSV           = "csv"
+)
+
+// formatOptions are embedded into the input of query tools to control how results are rendered.
+type formatOptions struct {
+	Format  string   `json:"format,omitempty" jsonschema:"Output format: json (structured content), compact (one key=value line per row), markdown_table or csv. Defaults to the configured output format."`
+	Columns []string `json:"columns,omitempty" jsonschema:"Columns to include, e.g. _time, _name, _duration_ms, service_name. Attribute names are given without type suffixes. Defaults to all columns."`
+}
+
+// table is a tool result flattened into rows. Echoed request fields such as
+// Query, Order, Search and WhereAttrs are not carried over.
+type table struct {
+	Meta    []metaField
+	Columns []string
+	Rows    []map[string]any
+}
+
+type metaField struct {
+	Key   string
+	Value any
+}
+
+// tableResult is the structured content of query tools in the json format: the
+// table with trimmed values and only as many rows as fit in output.max_size.
+type tableResult struct {
+	Meta      map[string]any   `json:"meta"`
+	Rows      []map[string]any `json:"rows"`
+	Truncated string           `json:"truncated,omitempty" jsonschema:"Set when rows were left out to stay under output.max_size, e.g. truncated, 20 more rows."`
+}
+
+// tableResultSchema returns the output schema of tools that return a table.
+func tableResultSchema() *jsonschema.Schema {
+	schema, err := jsonschema.For[tableResult](nil)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// formatResult renders the table in the requested format, keeping it under the
+// configured maximum size. The json format is returned as structured content;
+// the text formats are returned as text only.
+func formatResult(
+	conf *appconf.Config, opts *formatOptions, tbl *table,
+) (*mcp.CallToolResult, any, error) {
+	format := opts.Format
+	if format == "" {
+		format = conf.Output.Format
+	}
+
+	f := &tableFormatter{
+		columns:     tbl.selectColumns(opts.Columns),
+		maxSize:     conf.Output.MaxSize,
+		maxValueLen: conf.Output.MaxValueLen,
+	}
+
+	var text string
+	switch format {
+	case formatJSON:
+		return nil, f.structured(tbl), nil
+	case formatCompact:
+		text = f.compact(tbl)
+	case formatMarkdownTable:
+		text = f.markdownTable(tbl)
+	case formatCSV:
+		text = f.csv(tbl)
+	default:
+		return nil, nil, fmt.Errorf(
+			"unknown format %q (supported: json, compact, markdown_table, csv)", format)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, nil, nil
+}
+
+// selectColumns returns the requested columns or, by default, every column that
+// has at least one non-empty value.
+func (t *table) selectColumns(requested []string) []string {
+	if len(requested) > 0 {
+		out := make([]string, len(requested))
+		for i, col := range requested {
+			out[i] = attrName(col)
+		}
+		return out
+	}
+
+	out := make([]string, 0, len(t.Columns))
+	for _, col := range t.Columns {
+		for _, row := range t.Rows {
+			if !isEmptyValue(row[col]) {
+				out = append(out, col)
+				break
+			}
+		}
+	}
+	return out
+}
+
+type tableFormatter struct {
+	columns     []string
+	maxSize     int
+	maxValueLen int
+}
+
+func (f *tableFormatter) compact(tbl *table) string {
+	var b strings.Builder
+	if line := f.metaLine(tbl); line != "" {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+
+	var line []byte
+	for i, row := range tbl.Rows {
+		line = line[:0]
+		for _, col := range f.columns {
+			value := row[col]
+			if isEmptyValue(value) {
+				continue
+			}
+			if len(line) > 0 {
+				line = append(line, ' ')
+			}
+			line = append(line, col...)
+			line = append(line, '=')
+			line = append(line, logfmtValue(f.cell(value))...)
+		}
+		line = append(line, '\n')
+
+		if !f.fits(&b, line, len(tbl.Rows)-i) {
+			break
+		}
+		b.Write(line)
+	}
+	return b.String()
+}
+
+func (f *tableFormatter) markdownTable(tbl *table) string {
+	var b strings.Builder
+	if line := f.metaLine(tbl); line != "" {
+		b.WriteString(line)
+		b.WriteString("\n\n")
+	}
+	if len(tbl.Rows) == 0 {
+		b.WriteString("No rows.\n")
+		return b.String()
+	}
+
+	b.WriteString("|")
+	for _, col := range f.columns {
+		b.WriteString(" " + markdownCell(col) + " |")
+	}
+	b.WriteString("\n|")
+	for range f.columns {
+		b.WriteString("---|")
+	}
+	b.WriteByte('\n')
+
+	var line []byte
+	for i, row := range tbl.Rows {
+		line = append(line[:0], '|')
+		for _, col := range f.columns {
+			line = append(line, ' ')
+			line = append(line, markdownCell(f.cell(row[col]))...)
+			line = append(line, " |"...)
+		}
+		line = append(line, '\n')
+
+		if !f.fits(&b, line, len(tbl.Rows)-i) {
+			break
+		}
+		b.Write(line)
+	}
+	return b.String()
+}
+
+func (f *tableFormatter) csv(tbl *table) string {
+	var b strings.Builder
+	if line := f.metaLine(tbl); line != "" {
+		b.WriteString("# " + line + "\n")
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(f.columns)
+	w.Flush()
+	b.Write(buf.Bytes())
+
+	record := make([]string, len(f.columns))
+	for i, row := range tbl.Rows {
+		for j, col := range f.columns {
+			record[j] = f.cell(row[col])
+		}
+		buf.Reset()
+		_ = w.Write(record)
+		w.Flush()
+
+		if !f.fits(&b, buf.Bytes(), len(tbl.Rows)-i) {
+			break
+		}
+		b.Write(buf.Bytes())
+	}
+	return b.String()
+}
+
+func (f *tableFormatter) structured(tbl *table) *tableResult {
+	res := &tableResult{
+		Meta: make(map[string]any, len(tbl.Meta)),
+		Rows: make([]map[string]any, 0, len(tbl.Rows)),
+	}
+	for _, field := range tbl.Meta {
+		res.Meta[field.Key] = f.jsonValue(field.Value)
+	}
+	b, _ := json.Marshal(res)
+	size := len(b)
+
+	for i, row := range tbl.Rows {
+		obj := make(map[string]any, len(f.columns))
+		for _, col := range f.columns {
+			if value := row[col]; !isEmptyValue(value) {
+				obj[col] = f.jsonValue(value)
+			}
+		}
+		b, err := json.Marshal(obj)
+		if err != nil {
+			continue
+		}
+
+		// Unless this is the last row, keep room for the truncation note.
+		size += len(b) + 1
+		remaining := len(tbl.Rows) - i
+		maxSize := f.maxSize
+		if remaining > 1 {
+			maxSize -= footerSize
+		}
+		if size > maxSize {
+			res.Truncated = fmt.Sprintf("truncated, %d more rows", remaining)
+			break
+		}
+		res.Rows = append(res.Rows, obj)
+	}
+	return res
+}
+
+// footerSize is the room kept for the truncation footer.
+const footerSize = 64
+
+// fits reports whether line can be added to b without exceeding the maximum size.
+// Unless line is the last row, room is kept for the footer, which is written
+// with the number of remaining rows when the line doesn't fit.
+func (f *tableFormatter) fits(b *strings.Builder, line []byte, remaining int) bool {
+	size := b.Len() + len(line)
+	if remaining > 1 {
+		size += footerSize
+	}
+	if size <= f.maxSize {
+		return true
+	}
+	fmt.Fprintf(b, "... truncated, %d more rows\n", remaining)
+	return false
+}
+
+func (f *tableFormatter) metaLine(tbl *table) string {
+	parts := make([]string, 0, len(tbl.Meta))
+	for _, field := range tbl.Meta {
+		parts = append(parts, field.Key+"="+logfmtValue(f.cell(field.Value)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// cell formats a value for text output, truncating long strings.
+func (f *tableFormatter) cell(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return truncateString(value, f.maxValueLen)
+	case float64:
+		return formatFloat(value)
+	case []float64:
+		parts := make([]string, len(value))
+		for i, v := range value {
+			parts[i] = formatFloat(v)
+		}
+		return strings.Join(parts, ",")
+	case time.Time:
+		return value.UTC().Format(time.RFC3339)
+	case fmt.Stringer:
+		return truncateString(value.String(), f.maxValueLen)
+	case bool, int, int64:
+		return fmt.Sprint(value)
+	default:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return truncateString(fmt.Sprint(value), f.maxValueLen)
+		}
+		return truncateString(string(b), f.maxValueLen)
+	}
+}
+
+// jsonValue prepares a value for JSON output: long strings are truncated and
+// NaN is replaced with null.
+func (f *tableFormatter) jsonValue(value any) any {
+	switch value := value.(type) {
+	case string:
+		return truncateString(value, f.maxValueLen)
+	case float64:
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil
+		}
+		return value
+	case []float64:
+		out := make([]any, len(value))
+		for i, v := range value {
+			if !math.IsNaN(v) && !math.IsInf(v, 0) {
+				out[i] = v
+			}
+		}
+		return out
+	case time.Time:
+		return value.UTC().Format(time.RFC3339)
+	case []any, map[string]any:
+		return f.cell(value)
+	default:
+		return value
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// spansTable flattens spans into rows keyed by UQL column names. Attribute keys
+// lose their type suffixes and nested events, logs and links are replaced by counts.
+func spansTable(spans []uptraceapi.Span) *table {
+	tbl := &table{
+		Columns: []string{
+			"_time", "_trace_id", "_id", "_parent_id", "_group_id", "_system", "_kind",
+			"_name", "_display_name", "_duration_ms", "_status_code", "_status_message",
+			"_events", "_logs",
+		},
+		Rows: make([]map[string]any, len(spans)),
+	}
+
+	attrs := make(map[string]bool)
+	for i := range spans {
+		span := &spans[i]
+		row := map[string]any{
+			"_time":        time.UnixMilli(int64(span.Time)).UTC().Format("2006-01-02T15:04:05.000Z"),
+			"_trace_id":    span.TraceID,
+			"_id":          span.ID,
+			"_name":        span.Name,
+			"_duration_ms": span.Duration,
+			"_status_code": span.StatusCode,
+		}
+		setString(row, "_parent_id", span.ParentID)
+		setString(row, "_group_id", span.GroupID)
+		setString(row, "_system", span.System)
+		setString(row, "_kind", span.Kind)
+		setString(row, "_display_name", span.DisplayName)
+		setString(row, "_status_message", span.StatusMessage)
+		if len(span.Events) > 0 {
+			row["_events"] = len(span.Events)
+		}
+		if len(span.Logs) > 0 {
+			row["_logs"] = len(span.Logs)
+		}
+
+		for key, value := range span.Attrs {
+			name := attrName(key)
+			row[name] = value
+			attrs[name] = true
+		}
+		tbl.Rows[i] = row
+	}
+
+	tbl.Columns = append(tbl.Columns, sortedKeys(attrs)...)
+	return tbl
+}
+
+// groupsTable converts group rows, dropping the __hash, __name and __query metadata.
+func groupsTable(groups []map[string]any, columns []uptraceapi.QueryColumn) *table {
+	tbl := &table{
+		Rows: make([]map[string]any, len(groups)),
+	}
+
+	seen := make(map[string]bool)
+	for _, col := range columns {
+		name := attrName(col.Name)
+		if !seen[name] {
+			seen[name] = true
+			tbl.Columns = append(tbl.Columns, name)
+		}
+	}
+
+	extra := make(map[string]bool)
+	for i, group := range groups {
+		row := make(map[string]any, len(group))
+		for key, value := range group {
+			if strings.HasPrefix(key, "__") {
+				continue
+			}
+			name := attrName(key)
+			row[name] = value
+			if !seen[name] {
+				extra[name] = true
+			}
+		}
+		tbl.Rows[i] = row
+	}
+
+	tbl.Columns = append(tbl.Columns, sortedKeys(extra)...)
+	return tbl
+}
+
+// timeseriesTable summarizes each series with min, avg, max and last values
+// followed by the values themselves.
+func timeseriesTable(resp *uptraceapi.QueryTimeseriesResponse) *table {
+	tbl := &table{
+		Meta: []metaField{
+			{"interval_ms", resp.Interval},
+			{"points", len(resp.Time)},
+		},
+	}
+
+	labels := make(map[string]bool)
+	for _, s := range timeseriesSeries(resp) {
+		row := make(map[string]any, len(s.Group)+7)
+		for key, value := range s.Group {
+			name := attrName(key)
+			row[name] = value
+			labels[name] = true
+		}
+		row["_column"] = s.Column
+		if s.Unit != "" {
+			row["_unit"] = s.Unit
+		}
+		addSeriesStats(row, s.Values)
+		tbl.Rows = append(tbl.Rows, row)
+	}
+
+	tbl.Columns = append(sortedKeys(labels), "_column", "_unit", "min", "avg", "max", "last", "values")
+	return tbl
+}
+
+func quantilesTable(resp *uptraceapi.QueryQuantilesResponse) *table {
+	tbl := &table{
+		Columns: []string{"_name", "min", "avg", "max", "last", "values"},
+		Rows:    make([]map[string]any, len(resp.Timeseries)),
+	}
+	for i, ts := range resp.Timeseries {
+		row := map[string]any{"_name": ts.Name}
+		addSeriesStats(row, ts.Value)
+		tbl.Rows[i] = row
+
+		if i == 0 {
+			tbl.Meta = []metaField{{"points", len(ts.Time)}}
+		}
+	}
+	return tbl
+}
+
+func addSeriesStats(row map[string]any, values []float64) {
+	if stats, ok := newSeriesStats(values); ok {
+		row["min"] = stats.Min
+		row["avg"] = stats.Avg
+		row["max"] = stats.Max
+		row["last"] = stats.Last
+	}
+	row["values"] = values
+}
+
+type seriesStats struct {
+	Min, Avg, Max, Last float64
+}
+
+// newSeriesStats summarizes values ignoring NaNs. It returns false when there are no values.
+func newSeriesStats(values []float64) (seriesStats, bool) {
+	stats := seriesStats{Min: math.Inf(1), Max: math.Inf(-1)}
+	var sum float64
+	var n int
+	for _, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		sum += v
+		n++
+		stats.Min = math.Min(stats.Min, v)
+		stats.Max = math.Max(stats.Max, v)
+		stats.Last = v
+	}
+	if n == 0 {
+		return seriesStats{}, false
+	}
+	stats.Avg = sum / float64(n)
+	return stats, true
+}
+
+//------------------------------------------------------------------------------
+
+func setString(row map[string]any, key string, value *string) {
+	if value != nil && *value != "" {
+		row[key] = *value
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isEmptyValue(value any) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case []any:
+		return len(value) == 0
+	case []float64:
+		return len(value) == 0
+	default:
+		return false
+	}
+}
+
+// formatFloat prints integers without a fractional part and rounds other values
+// to 3 decimal places, keeping 3 significant digits for tiny values.
+func formatFloat(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "null"
+	case v == math.Trunc(v) && math.Abs(v) < 1e15:
+		return strconv.FormatInt(int64(v), 10)
+	case math.Abs(v) < 0.001:
+		return strconv.FormatFloat(v, 'g', 3, 64)
+	default:
+		return strconv.FormatFloat(roundTo(v, 3), 'f', -1, 64)
+	}
+}
+
+func truncateString(s string, maxLen int) string {
+	if maxLen <= 0 || len(s) <= maxLen {
+		return s
+	}
+	cut := maxLen
+	// Don't split a multi-byte rune.
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + fmt.Sprintf("…(%d more bytes)", len(s)-cut)
+}
+
+func logfmtValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
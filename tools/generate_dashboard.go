@@ -0,0 +1,275 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/dashboard"
+	"github.com/uptrace/mcp/uptraceapi"
+	"golang.org/x/sync/errgroup"
+)
+
+type GenerateDashboardTool struct {
+	client *uptraceapi.Client
+	conf   *appconf.Config
+}
+
+func NewGenerateDashboardTool(client *uptraceapi.Client, conf *appconf.Config) *GenerateDashboardTool {
+	return &GenerateDashboardTool{
+		client: client,
+		conf:   conf,
+	}
+}
+
+func (t *GenerateDashboardTool) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "generate_dashboard",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Generate service dashboard",
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   boolPtr(true),
+		},
+		Description: "Generate a dashboard for a service from its discovered metrics and span systems. " +
+			"Finds metrics that carry the service name attribute and the span systems of the service, " +
+			"then picks charts per instrument: rates for counters, p50/p90/p99 for histograms and the " +
+			"current value for gauges, with one row per span system and per metric subsystem (http, db, ...). " +
+			"Returns schema v2 YAML; set create=true to also create the dashboard.",
+	}, t.handler)
+}
+
+type generateDashboardInput struct {
+	Service    string    `json:"service" jsonschema:"Service name, the value of the service_name attribute."`
+	ProjectID  int64     `json:"project_id,omitempty" jsonschema:"Uptrace project ID."`
+	TimeStart  time.Time `json:"time_start,omitempty" jsonschema:"Start of the time range used to discover metrics and spans, as RFC3339 timestamp."`
+	TimeEnd    time.Time `json:"time_end,omitempty" jsonschema:"End of the time range used to discover metrics and spans, as RFC3339 timestamp."`
+	Name       string    `json:"name,omitempty" jsonschema:"Dashboard name. Defaults to Service: <service>."`
+	MaxMetrics int       `json:"max_metrics,omitempty" jsonschema:"Maximum number of metrics to chart. Defaults to 30."`
+	NoSpans    bool      `json:"no_spans,omitempty" jsonschema:"Don't add rows for span systems."`
+	Create     bool      `json:"create,omitempty" jsonschema:"Create the dashboard in Uptrace instead of only returning the YAML."`
+
+	timeRangeOptions
+}
+
+type generateDashboardOutput struct {
+	Name        string   `json:"name"`
+	Metrics     []string `json:"metrics" jsonschema:"Metrics included in the dashboard."`
+	Systems     []string `json:"systems" jsonschema:"Span systems included in the dashboard."`
+	Rows        int      `json:"rows"`
+	Items       int      `json:"items"`
+	YAML        string   `json:"yaml"`
+	DashboardID int64    `json:"dashboard_id,omitempty" jsonschema:"ID of the created dashboard when create=true."`
+}
+
+func (t *GenerateDashboardTool) handler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input *generateDashboardInput,
+) (*mcp.CallToolResult, *generateDashboardOutput, error) {
+	projectID := input.ProjectID
+	if projectID == 0 {
+		projectID = t.conf.Uptrace.ProjectID
+	}
+	if err := input.resolveTimeRange(t.conf, &input.TimeStart, &input.TimeEnd); err != nil {
+		return nil, nil, err
+	}
+	if input.Service == "" {
+		return nil, nil, fmt.Errorf("service is required")
+	}
+	if input.MaxMetrics == 0 {
+		input.MaxMetrics = 30
+	}
+
+	metrics, serviceAttr, err := t.serviceMetrics(ctx, newProgress(req, 0), projectID, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	var systems []string
+	if !input.NoSpans {
+		systems, err = t.serviceSystems(ctx, projectID, input)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(metrics) == 0 && len(systems) == 0 {
+		return nil, nil, fmt.Errorf("no metrics or spans found for service %q in the time range", input.Service)
+	}
+
+	dash := dashboard.GenerateService(&dashboard.ServiceInput{
+		Name:        input.Name,
+		Service:     input.Service,
+		ServiceAttr: serviceAttr,
+		Metrics:     metrics,
+		Systems:     systems,
+	})
+	body, err := dash.YAML()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := &generateDashboardOutput{
+		Name:    dash.Name,
+		Metrics: make([]string, len(metrics)),
+		Systems: systems,
+		Rows:    len(dash.GridRows),
+		YAML:    string(body),
+	}
+	if out.Systems == nil {
+		out.Systems = []string{}
+	}
+	for i, m := range metrics {
+		out.Metrics[i] = m.Name
+	}
+	for _, row := range dash.GridRows {
+		out.Items += len(row.Items)
+	}
+
+	if input.Create {
+		resp, err := t.client.CreateDashboardFromYaml(ctx, &uptraceapi.CreateDashboardFromYamlRequestOptions{
+			PathParams: &uptraceapi.CreateDashboardFromYamlPath{ProjectID: projectID},
+		}, dashboard.RequestBody(body))
+		if err != nil {
+			return nil, nil, err
+		}
+		out.DashboardID = resp.Dashboard.ID
+	}
+	return nil, out, nil
+}
+
+// metricCheckBatch is the number of metrics checked for the service concurrently.
+const metricCheckBatch = 8
+
+// serviceMetrics returns the metrics that have data for the service, preferring
+// metrics with the most timeseries, together with the name of the service attribute.
+func (t *GenerateDashboardTool) serviceMetrics(
+	ctx context.Context, prog *progress, projectID int64, input *generateDashboardInput,
+) ([]dashboard.Metric, string, error) {
+	resp, err := t.client.ExploreMetrics(ctx, &uptraceapi.ExploreMetricsRequestOptions{
+		PathParams: &uptraceapi.ExploreMetricsPath{ProjectID: projectID},
+		Query: &uptraceapi.ExploreMetricsQuery{
+			TimeStart: input.TimeStart,
+			TimeEnd:   input.TimeEnd,
+		},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	candidates := slices.Clone(resp.Metrics)
+	candidates = slices.DeleteFunc(candidates, func(m uptraceapi.ExploredMetric) bool {
+		return serviceAttrKey(m.AttrKeys) == ""
+	})
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return numTimeseries(&candidates[i]) > numTimeseries(&candidates[j])
+	})
+
+	// Candidates are checked in batches, most timeseries first, until enough
+	// metrics have data for the service.
+	var metrics []dashboard.Metric
+	serviceAttr := ""
+	for start := 0; start < len(candidates) && len(metrics) < input.MaxMetrics; start += metricCheckBatch {
+		batch := candidates[start:min(start+metricCheckBatch, len(candidates))]
+		prog.addTotal(len(batch))
+
+		keep := make([]bool, len(batch))
+		g, gctx := errgroup.WithContext(ctx)
+		for i := range batch {
+			m := &batch[i]
+			g.Go(func() error {
+				key := serviceAttrKey(m.AttrKeys)
+				if !strings.Contains(key, "::") {
+					key += "::str"
+				}
+				values, err := t.client.ListMetricAttributeValues(gctx, &uptraceapi.ListMetricAttributeValuesRequestOptions{
+					PathParams: &uptraceapi.ListMetricAttributeValuesPath{ProjectID: projectID, AttrKey: key},
+					Query: &uptraceapi.ListMetricAttributeValuesQuery{
+						TimeStart: input.TimeStart,
+						TimeEnd:   input.TimeEnd,
+						Search:    &m.Name,
+					},
+				})
+				if err != nil {
+					return err
+				}
+				keep[i] = slices.ContainsFunc(values.Items, func(v uptraceapi.MetricAttributeValue) bool {
+					return v.Value == input.Service
+				})
+				prog.step(gctx, "checked "+m.Name)
+				return nil
+			})
+		}
+		if err := g.Wait(); err != nil {
+			return nil, "", err
+		}
+
+		for i := range batch {
+			if !keep[i] || len(metrics) == input.MaxMetrics {
+				continue
+			}
+			m := &batch[i]
+			if serviceAttr == "" {
+				serviceAttr = attrName(serviceAttrKey(m.AttrKeys))
+			}
+			metric := dashboard.Metric{Name: m.Name, Instrument: string(m.Instrument)}
+			if m.Description != nil {
+				metric.Description = *m.Description
+			}
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics, serviceAttr, nil
+}
+
+// serviceAttrKey finds service_name (or service.name) among the attribute keys.
+func serviceAttrKey(keys []string) string {
+	for _, key := range keys {
+		if normalizeAttrName(key) == "service_name" {
+			return key
+		}
+	}
+	return ""
+}
+
+// serviceSystems returns the span systems of the service ordered by span count.
+// Log and event systems are skipped.
+func (t *GenerateDashboardTool) serviceSystems(
+	ctx context.Context, projectID int64, input *generateDashboardInput,
+) ([]string, error) {
+	query := fmt.Sprintf("where service_name = %s | group by _system | count()", strconv.Quote(input.Service))
+	resp, err := t.client.ListSpanGroups(ctx, &uptraceapi.ListSpanGroupsRequestOptions{
+		PathParams: &uptraceapi.ListSpanGroupsPath{ProjectID: projectID},
+		Query: &uptraceapi.ListSpanGroupsQuery{
+			TimeStart: input.TimeStart,
+			TimeEnd:   input.TimeEnd,
+			Query:     &query,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]float64)
+	for _, row := range resp.Groups {
+		system, _ := row["_system"].(string)
+		if system == "" || strings.HasPrefix(system, "log:") || strings.HasPrefix(system, "event:") {
+			continue
+		}
+		counts[system] += sumAggColumn(&uptraceapi.GroupsResult{
+			Groups:  []map[string]any{row},
+			Columns: resp.Columns,
+		})
+	}
+
+	systems := sortedKeys(counts)
+	sort.SliceStable(systems, func(i, j int) bool {
+		return counts[systems[i]] > counts[systems[j]]
+	})
+	return systems, nil
+}
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/dashboard"
+)
+
+type LintDashboardYamlTool struct{}
+
+func NewLintDashboardYamlTool() *LintDashboardYamlTool {
+	return &LintDashboardYamlTool{}
+}
+
+func (t *LintDashboardYamlTool) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "lint_dashboard_yaml",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Lint dashboard YAML",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(false),
+		},
+		Description: "Check a YAML dashboard definition locally without calling Uptrace. " +
+			"Validates it against schema v2: required fields, item types (chart, table, heatmap, gauge), " +
+			"widths between 1 and 24 columns that add up to at most 24 per line of a row, metrics declared as \"name as $alias\" and query lines " +
+			"that only use declared aliases. Problems include line numbers. " +
+			"create_dashboard and update_dashboard_yaml run the same checks and refuse YAML with errors.",
+	}, t.handler)
+}
+
+type lintDashboardYamlInput struct {
+	Body string `json:"body" jsonschema:"YAML dashboard definition." validate:"required"`
+}
+
+type lintDashboardYamlOutput struct {
+	Valid    bool                `json:"valid" jsonschema:"True when there are no errors. Warnings don't make the dashboard invalid."`
+	Errors   []dashboard.Problem `json:"errors"`
+	Warnings []dashboard.Problem `json:"warnings"`
+}
+
+func (t *LintDashboardYamlTool) handler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input *lintDashboardYamlInput,
+) (*mcp.CallToolResult, *lintDashboardYamlOutput, error) {
+	if input.Body == "" {
+		return nil, nil, fmt.Errorf("body is required")
+	}
+
+	out := &lintDashboardYamlOutput{
+		Errors:   []dashboard.Problem{},
+		Warnings: []dashboard.Problem{},
+	}
+	for _, p := range dashboard.Lint([]byte(input.Body)) {
+		if p.Severity == dashboard.SeverityError {
+			out.Errors = append(out.Errors, p)
+		} else {
+			out.Warnings = append(out.Warnings, p)
+		}
+	}
+	out.Valid = len(out.Errors) == 0
+	return nil, out, nil
+}
+
+// dashboardLintResult lints a YAML dashboard before it is sent to Uptrace and
+// returns an error result listing the problems, or nil when there are no errors.
+func dashboardLintResult(body string) *mcp.CallToolResult {
+	problems := dashboard.Lint([]byte(body))
+	if !dashboard.HasErrors(problems) {
+		return nil
+	}
+
+	var b strings.Builder
+	b.WriteString("The dashboard YAML is invalid, nothing was sent to Uptrace:\n\n")
+	for _, p := range problems {
+		fmt.Fprintf(&b, "- %s: %s\n", p.Severity, p)
+	}
+	b.WriteString("\nFix the errors and try again. Use lint_dashboard_yaml to check the YAML without saving it.")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: b.String()}},
+		IsError: true,
+	}
+}
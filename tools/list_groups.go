@@ -30,15 +30,24 @@ func (t *ListSpanGroupsTool) Register(server *mcp.Server) {
 			IdempotentHint: true,
 			OpenWorldHint:  boolPtr(true),
 		},
-		Description: uptraceapi.Operations["list_span_groups"].Description,
+		InputSchema:  timeRangeInputSchema[listSpanGroupsInput](),
+		OutputSchema: tableResultSchema(),
+		Description:  uptraceapi.Operations["list_span_groups"].Description,
 	}, t.handler)
 }
 
+type listSpanGroupsInput struct {
+	uptraceapi.ListSpanGroupsRequestOptions
+	timeRangeOptions
+	paginationOptions
+	formatOptions
+}
+
 func (t *ListSpanGroupsTool) handler(
 	ctx context.Context,
 	req *mcp.CallToolRequest,
-	input *uptraceapi.ListSpanGroupsRequestOptions,
-) (*mcp.CallToolResult, *uptraceapi.ListSpanGroupsResponse, error) {
+	input *listSpanGroupsInput,
+) (*mcp.CallToolResult, any, error) {
 	if input.PathParams.ProjectID == 0 {
 		input.PathParams.ProjectID = t.conf.Uptrace.ProjectID
 	}
@@ -46,11 +55,10 @@ func (t *ListSpanGroupsTool) handler(
 	if input.Query == nil {
 		input.Query = &uptraceapi.ListSpanGroupsQuery{}
 	}
-	if input.Query.TimeStart.IsZero() {
-		input.Query.TimeStart = time.Now().Add(-t.conf.Default.TimeDuration)
-	}
-	if input.Query.TimeEnd.IsZero() {
-		input.Query.TimeEnd = time.Now()
+	if err := input.resolveTimeRange(
+		t.conf, &input.Query.TimeStart, &input.Query.TimeEnd,
+	); err != nil {
+		return nil, nil, err
 	}
 	if input.Query.Limit == nil {
 		defaultLimit := uptraceapi.Limit(t.conf.Default.Limit)
@@ -60,10 +68,38 @@ func (t *ListSpanGroupsTool) handler(
 		input.Query.Query = &t.conf.Default.Query
 	}
 
-	resp, err := t.client.ListSpanGroups(ctx, input)
+	p, err := input.newPager(
+		&input.ListSpanGroupsRequestOptions, &input.Query.TimeStart, &input.Query.TimeEnd,
+		input.Query.Limit, false,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var columns []uptraceapi.QueryColumn
+	groups, err := fetchPages(ctx, req, p, nil,
+		func(ctx context.Context, end time.Time, limit int) ([]map[string]any, bool, error) {
+			opts := input.ListSpanGroupsRequestOptions
+			query := *opts.Query
+			query.TimeEnd = end
+			pageLimit := uptraceapi.Limit(limit)
+			query.Limit = &pageLimit
+			opts.Query = &query
+
+			resp, err := t.client.ListSpanGroups(ctx, &opts)
+			if err != nil {
+				return nil, false, err
+			}
+			columns = resp.Columns
+			return resp.Groups, hasMore(resp.HasMore, len(resp.Groups), limit), nil
+		})
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return nil, resp, nil
+	tbl := groupsTable(groups, columns)
+	tbl.Meta = []metaField{{"returned", len(groups)}, {"has_more", p.more}}
+	tbl.Meta = append(tbl.Meta, p.meta()...)
+	tbl.Meta = append(timeRangeMeta(input.Query.TimeStart, input.Query.TimeEnd), tbl.Meta...)
+	return formatResult(t.conf, &input.formatOptions, tbl)
 }
@@ -30,15 +30,24 @@ func (t *PublicListSpanGroupsTool) Register(server *mcp.Server) {
 			IdempotentHint: true,
 			OpenWorldHint:  boolPtr(true),
 		},
-		Description: uptraceapi.Operations["public_list_span_groups"].Description,
+		InputSchema:  timeRangeInputSchema[publicListSpanGroupsInput](),
+		OutputSchema: tableResultSchema(),
+		Description:  uptraceapi.Operations["public_list_span_groups"].Description,
 	}, t.handler)
 }
 
+type publicListSpanGroupsInput struct {
+	uptraceapi.PublicListSpanGroupsRequestOptions
+	timeRangeOptions
+	paginationOptions
+	formatOptions
+}
+
 func (t *PublicListSpanGroupsTool) handler(
 	ctx context.Context,
 	req *mcp.CallToolRequest,
-	input *uptraceapi.PublicListSpanGroupsRequestOptions,
-) (*mcp.CallToolResult, *uptraceapi.PublicListSpanGroupsResponse, error) {
+	input *publicListSpanGroupsInput,
+) (*mcp.CallToolResult, any, error) {
 	if input.PathParams.ProjectID == 0 {
 		input.PathParams.ProjectID = t.conf.Uptrace.ProjectID
 	}
@@ -46,11 +55,10 @@ func (t *PublicListSpanGroupsTool) handler(
 	if input.Query == nil {
 		input.Query = &uptraceapi.PublicListSpanGroupsQuery{}
 	}
-	if input.Query.TimeStart.IsZero() {
-		input.Query.TimeStart = time.Now().Add(-t.conf.Default.TimeDuration)
-	}
-	if input.Query.TimeEnd.IsZero() {
-		input.Query.TimeEnd = time.Now()
+	if err := input.resolveTimeRange(
+		t.conf, &input.Query.TimeStart, &input.Query.TimeEnd,
+	); err != nil {
+		return nil, nil, err
 	}
 	if input.Query.Limit == nil {
 		defaultLimit := uptraceapi.Limit(t.conf.Default.Limit)
@@ -60,10 +68,36 @@ func (t *PublicListSpanGroupsTool) handler(
 		input.Query.Query = &t.conf.Default.Query
 	}
 
-	resp, err := t.client.PublicListSpanGroups(ctx, input)
+	p, err := input.newPager(
+		&input.PublicListSpanGroupsRequestOptions, &input.Query.TimeStart, &input.Query.TimeEnd,
+		input.Query.Limit, false,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	groups, err := fetchPages(ctx, req, p, nil,
+		func(ctx context.Context, end time.Time, limit int) ([]map[string]any, bool, error) {
+			opts := input.PublicListSpanGroupsRequestOptions
+			query := *opts.Query
+			query.TimeEnd = end
+			pageLimit := uptraceapi.Limit(limit)
+			query.Limit = &pageLimit
+			opts.Query = &query
+
+			resp, err := t.client.PublicListSpanGroups(ctx, &opts)
+			if err != nil {
+				return nil, false, err
+			}
+			return resp.Groups, hasMore(resp.HasMore, len(resp.Groups), limit), nil
+		})
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return nil, resp, nil
+	tbl := groupsTable(groups, nil)
+	tbl.Meta = []metaField{{"returned", len(groups)}, {"has_more", p.more}}
+	tbl.Meta = append(tbl.Meta, p.meta()...)
+	tbl.Meta = append(timeRangeMeta(input.Query.TimeStart, input.Query.TimeEnd), tbl.Meta...)
+	return formatResult(t.conf, &input.formatOptions, tbl)
 }
@@ -0,0 +1,185 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+type ListSpanAttributeValuesTool struct {
+	client  *uptraceapi.Client
+	conf    *appconf.Config
+	sampler *spanAttrSampler
+}
+
+func NewListSpanAttributeValuesTool(
+	client *uptraceapi.Client, conf *appconf.Config, sampler *spanAttrSampler,
+) *ListSpanAttributeValuesTool {
+	return &ListSpanAttributeValuesTool{
+		client:  client,
+		conf:    conf,
+		sampler: sampler,
+	}
+}
+
+func (t *ListSpanAttributeValuesTool) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "list_span_attribute_values",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "List span attribute values",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+		Description: "List the most common values of a span or log attribute with span counts. " +
+			"Counts come from a group by query over all matching spans, not just a sample. " +
+			"The attribute name is matched leniently, so http.route finds http_route.",
+		OutputSchema: tableResultSchema(),
+	}, t.handler)
+}
+
+type listSpanAttributeValuesInput struct {
+	Attribute string    `json:"attribute" jsonschema:"Attribute name, e.g. http_route or service_name."`
+	ProjectID int64     `json:"project_id,omitempty" jsonschema:"Uptrace project ID."`
+	TimeStart time.Time `json:"time_start,omitempty" jsonschema:"Start time (inclusive) as RFC3339 timestamp."`
+	TimeEnd   time.Time `json:"time_end,omitempty" jsonschema:"End time (exclusive) as RFC3339 timestamp."`
+	System    []string  `json:"system,omitempty" jsonschema:"Filter by system (e.g., httpserver:all or log:all for logs)."`
+	Query     string    `json:"query,omitempty" jsonschema:"UQL filter (e.g., where service_name = \"checkout\")."`
+	Limit     int       `json:"limit,omitempty" jsonschema:"Maximum number of values to return. Defaults to 20."`
+
+	timeRangeOptions
+	formatOptions
+}
+
+// maxAttributeGroups is the number of groups fetched to rank attribute values.
+const maxAttributeGroups = 1000
+
+func (t *ListSpanAttributeValuesTool) handler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input *listSpanAttributeValuesInput,
+) (*mcp.CallToolResult, any, error) {
+	projectID := input.ProjectID
+	if projectID == 0 {
+		projectID = t.conf.Uptrace.ProjectID
+	}
+	if err := input.resolveTimeRange(t.conf, &input.TimeStart, &input.TimeEnd); err != nil {
+		return nil, nil, err
+	}
+	if input.Attribute == "" {
+		return nil, nil, fmt.Errorf("attribute is required")
+	}
+	if input.Limit == 0 {
+		input.Limit = 20
+	}
+	if input.Limit < 1 {
+		return nil, nil, fmt.Errorf("limit must be at least 1, got %d", input.Limit)
+	}
+
+	sample, err := t.sampler.sample(ctx, &spanSampleFilter{
+		ProjectID: projectID,
+		TimeStart: input.TimeStart,
+		TimeEnd:   input.TimeEnd,
+		System:    input.System,
+		Query:     input.Query,
+		Limit:     1000,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name := resolveSampledAttr(sample, input.Attribute)
+	query := fmt.Sprintf("where %s exists | group by %s | count()", name, name)
+	if input.Query != "" {
+		query = input.Query + " | " + query
+	}
+	limit := uptraceapi.Limit(maxAttributeGroups)
+	resp, err := t.client.ListSpanGroups(ctx, &uptraceapi.ListSpanGroupsRequestOptions{
+		PathParams: &uptraceapi.ListSpanGroupsPath{ProjectID: projectID},
+		Query: &uptraceapi.ListSpanGroupsQuery{
+			TimeStart: sample.TimeStart,
+			TimeEnd:   sample.TimeEnd,
+			Query:     &query,
+			System:    input.System,
+			Limit:     &limit,
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	type valueRow struct {
+		value any
+		count float64
+	}
+	var rows []valueRow
+	var total float64
+	for _, group := range resp.Groups {
+		var value any
+		for key, v := range group {
+			if attrName(key) == name {
+				value = v
+				break
+			}
+		}
+		count := sumAggColumn(&uptraceapi.GroupsResult{
+			Groups:  []map[string]any{group},
+			Columns: resp.Columns,
+		})
+		rows = append(rows, valueRow{value, count})
+		total += count
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].count > rows[j].count
+	})
+
+	tbl := &table{Columns: []string{"value", "count", "pct"}}
+	for i, row := range rows {
+		if i == input.Limit {
+			break
+		}
+		pct := 0.0
+		if total > 0 {
+			pct = roundTo(row.count/total*100, 1)
+		}
+		tbl.Rows = append(tbl.Rows, map[string]any{
+			"value": row.value,
+			"count": row.count,
+			"pct":   pct,
+		})
+	}
+
+	tbl.Meta = append(timeRangeMeta(sample.TimeStart, sample.TimeEnd),
+		metaField{"attribute", name},
+		metaField{"distinct_values", len(rows)},
+		metaField{"spans_with_attribute", total},
+	)
+	if attr := sample.Attrs[name]; attr != nil {
+		tbl.Meta = append(tbl.Meta, metaField{"type", attr.Type})
+	}
+	if resp.HasMore != nil && *resp.HasMore {
+		tbl.Meta = append(tbl.Meta, metaField{"has_more", true})
+	}
+	return formatResult(t.conf, &input.formatOptions, tbl)
+}
+
+// resolveSampledAttr maps a loosely spelled attribute name such as http.route to
+// the name seen in the sample. Unknown names are returned without the type suffix.
+func resolveSampledAttr(sample *spanSample, name string) string {
+	name = attrName(name)
+	if _, ok := sample.Attrs[name]; ok {
+		return name
+	}
+	normalized := normalizeAttrName(name)
+	for _, key := range sortedKeys(sample.Attrs) {
+		if normalizeAttrName(key) == normalized {
+			return key
+		}
+	}
+	return name
+}
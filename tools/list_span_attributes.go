@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+type ListSpanAttributesTool struct {
+	client  *uptraceapi.Client
+	conf    *appconf.Config
+	sampler *spanAttrSampler
+}
+
+func NewListSpanAttributesTool(
+	client *uptraceapi.Client, conf *appconf.Config, sampler *spanAttrSampler,
+) *ListSpanAttributesTool {
+	return &ListSpanAttributesTool{
+		client:  client,
+		conf:    conf,
+		sampler: sampler,
+	}
+}
+
+func (t *ListSpanAttributesTool) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "list_span_attributes",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "List span attributes",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+		Description: "List span and log attribute keys with their types, how many sampled spans have them, " +
+			"cardinality and the most common values. Samples recent spans matching a system or UQL filter. " +
+			"Use it to find exact attribute names (e.g., http_route vs http.route) before writing queries; " +
+			"search ignores the difference between dots and underscores.",
+		OutputSchema: tableResultSchema(),
+	}, t.handler)
+}
+
+type listSpanAttributesInput struct {
+	ProjectID int64     `json:"project_id,omitempty" jsonschema:"Uptrace project ID."`
+	TimeStart time.Time `json:"time_start,omitempty" jsonschema:"Start time (inclusive) as RFC3339 timestamp."`
+	TimeEnd   time.Time `json:"time_end,omitempty" jsonschema:"End time (exclusive) as RFC3339 timestamp."`
+	System    []string  `json:"system,omitempty" jsonschema:"Filter by system (e.g., httpserver:all or log:all for logs)."`
+	Query     string    `json:"query,omitempty" jsonschema:"UQL filter selecting the spans to sample (e.g., where service_name = \"checkout\")."`
+	Search    string    `json:"search,omitempty" jsonschema:"Only return attributes whose name contains this text."`
+	Sample    int       `json:"sample,omitempty" jsonschema:"Number of spans to sample. Defaults to 1000."`
+	TopValues int       `json:"top_values,omitempty" jsonschema:"Number of most common values to show per attribute. Defaults to 5."`
+
+	timeRangeOptions
+	formatOptions
+}
+
+func (t *ListSpanAttributesTool) handler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input *listSpanAttributesInput,
+) (*mcp.CallToolResult, any, error) {
+	projectID := input.ProjectID
+	if projectID == 0 {
+		projectID = t.conf.Uptrace.ProjectID
+	}
+	if err := input.resolveTimeRange(t.conf, &input.TimeStart, &input.TimeEnd); err != nil {
+		return nil, nil, err
+	}
+	if input.Sample == 0 {
+		input.Sample = 1000
+	}
+	if input.TopValues == 0 {
+		input.TopValues = 5
+	}
+	if input.Sample < 1 {
+		return nil, nil, fmt.Errorf("sample must be at least 1, got %d", input.Sample)
+	}
+	if input.TopValues < 1 {
+		return nil, nil, fmt.Errorf("top_values must be at least 1, got %d", input.TopValues)
+	}
+
+	sample, err := t.sampler.sample(ctx, &spanSampleFilter{
+		ProjectID: projectID,
+		TimeStart: input.TimeStart,
+		TimeEnd:   input.TimeEnd,
+		System:    input.System,
+		Query:     input.Query,
+		Limit:     input.Sample,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	search := normalizeAttrName(input.Search)
+	attrs := make([]*sampledAttr, 0, len(sample.Attrs))
+	for _, attr := range sample.Attrs {
+		if search == "" || strings.Contains(normalizeAttrName(attr.Name), search) {
+			attrs = append(attrs, attr)
+		}
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].Count != attrs[j].Count {
+			return attrs[i].Count > attrs[j].Count
+		}
+		return attrs[i].Name < attrs[j].Name
+	})
+
+	tbl := &table{
+		Columns: []string{"attribute", "type", "coverage_pct", "cardinality", "top_values"},
+	}
+	for _, attr := range attrs {
+		values := attr.topValues(input.TopValues)
+		top := make([]string, len(values))
+		for i, v := range values {
+			top[i] = fmt.Sprintf("%s (%d)", v.Value, v.Count)
+		}
+		tbl.Rows = append(tbl.Rows, map[string]any{
+			"attribute":    attr.Name,
+			"type":         attr.Type,
+			"coverage_pct": sample.coverage(attr),
+			"cardinality":  attr.cardinality(),
+			"top_values":   strings.Join(top, ", "),
+		})
+	}
+	tbl.Meta = append(timeRangeMeta(sample.TimeStart, sample.TimeEnd),
+		metaField{"matching_spans", sample.Total},
+		metaField{"sampled_spans", sample.Spans},
+		metaField{"attributes", len(attrs)},
+	)
+	return formatResult(t.conf, &input.formatOptions, tbl)
+}
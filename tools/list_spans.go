@@ -30,15 +30,24 @@ func (t *ListSpansTool) Register(server *mcp.Server) {
 			IdempotentHint: true,
 			OpenWorldHint:  boolPtr(true),
 		},
-		Description: uptraceapi.Operations["list_spans"].Description,
+		InputSchema:  timeRangeInputSchema[listSpansInput](),
+		OutputSchema: tableResultSchema(),
+		Description:  uptraceapi.Operations["list_spans"].Description,
 	}, t.handler)
 }
 
+type listSpansInput struct {
+	uptraceapi.ListSpansRequestOptions
+	timeRangeOptions
+	paginationOptions
+	formatOptions
+}
+
 func (t *ListSpansTool) handler(
 	ctx context.Context,
 	req *mcp.CallToolRequest,
-	input *uptraceapi.ListSpansRequestOptions,
-) (*mcp.CallToolResult, *uptraceapi.ListSpansResponse, error) {
+	input *listSpansInput,
+) (*mcp.CallToolResult, any, error) {
 	if input.PathParams.ProjectID == 0 {
 		input.PathParams.ProjectID = t.conf.Uptrace.ProjectID
 	}
@@ -46,21 +55,50 @@ func (t *ListSpansTool) handler(
 	if input.Query == nil {
 		input.Query = &uptraceapi.ListSpansQuery{}
 	}
-	if input.Query.TimeStart.IsZero() {
-		input.Query.TimeStart = time.Now().Add(-t.conf.Default.TimeDuration)
-	}
-	if input.Query.TimeEnd.IsZero() {
-		input.Query.TimeEnd = time.Now()
+	if err := input.resolveTimeRange(
+		t.conf, &input.Query.TimeStart, &input.Query.TimeEnd,
+	); err != nil {
+		return nil, nil, err
 	}
 	if input.Query.Limit == nil {
 		defaultLimit := uptraceapi.Limit(t.conf.Default.Limit)
 		input.Query.Limit = &defaultLimit
 	}
 
-	resp, err := t.client.ListSpans(ctx, input)
+	p, err := input.newPager(
+		&input.ListSpansRequestOptions, &input.Query.TimeStart, &input.Query.TimeEnd,
+		input.Query.Limit, sortedByTime(input.Query.SortBy, input.Query.SortDir),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var count int64
+	spans, err := fetchPages(ctx, req, p, spanTimeKey,
+		func(ctx context.Context, end time.Time, limit int) ([]uptraceapi.Span, bool, error) {
+			opts := input.ListSpansRequestOptions
+			query := *opts.Query
+			query.TimeEnd = end
+			pageLimit := uptraceapi.Limit(limit)
+			query.Limit = &pageLimit
+			opts.Query = &query
+
+			resp, err := t.client.ListSpans(ctx, &opts)
+			if err != nil {
+				return nil, false, err
+			}
+			if count == 0 {
+				count = resp.Count
+			}
+			return resp.Spans, resp.Count > int64(len(resp.Spans)), nil
+		})
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return nil, resp, nil
+	tbl := spansTable(spans)
+	tbl.Meta = []metaField{{"count", count}, {"returned", len(spans)}}
+	tbl.Meta = append(tbl.Meta, p.meta()...)
+	tbl.Meta = append(timeRangeMeta(input.Query.TimeStart, input.Query.TimeEnd), tbl.Meta...)
+	return formatResult(t.conf, &input.formatOptions, tbl)
 }
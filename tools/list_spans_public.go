@@ -30,15 +30,24 @@ func (t *PublicListSpansTool) Register(server *mcp.Server) {
 			IdempotentHint: true,
 			OpenWorldHint:  boolPtr(true),
 		},
-		Description: uptraceapi.Operations["public_list_spans"].Description,
+		InputSchema:  timeRangeInputSchema[publicListSpansInput](),
+		OutputSchema: tableResultSchema(),
+		Description:  uptraceapi.Operations["public_list_spans"].Description,
 	}, t.handler)
 }
 
+type publicListSpansInput struct {
+	uptraceapi.PublicListSpansRequestOptions
+	timeRangeOptions
+	paginationOptions
+	formatOptions
+}
+
 func (t *PublicListSpansTool) handler(
 	ctx context.Context,
 	req *mcp.CallToolRequest,
-	input *uptraceapi.PublicListSpansRequestOptions,
-) (*mcp.CallToolResult, *uptraceapi.PublicListSpansResponse, error) {
+	input *publicListSpansInput,
+) (*mcp.CallToolResult, any, error) {
 	if input.PathParams.ProjectID == 0 {
 		input.PathParams.ProjectID = t.conf.Uptrace.ProjectID
 	}
@@ -46,21 +55,46 @@ func (t *PublicListSpansTool) handler(
 	if input.Query == nil {
 		input.Query = &uptraceapi.PublicListSpansQuery{}
 	}
-	if input.Query.TimeStart.IsZero() {
-		input.Query.TimeStart = time.Now().Add(-t.conf.Default.TimeDuration)
-	}
-	if input.Query.TimeEnd.IsZero() {
-		input.Query.TimeEnd = time.Now()
+	if err := input.resolveTimeRange(
+		t.conf, &input.Query.TimeStart, &input.Query.TimeEnd,
+	); err != nil {
+		return nil, nil, err
 	}
 	if input.Query.Limit == nil {
 		defaultLimit := uptraceapi.Limit(t.conf.Default.Limit)
 		input.Query.Limit = &defaultLimit
 	}
 
-	resp, err := t.client.PublicListSpans(ctx, input)
+	p, err := input.newPager(
+		&input.PublicListSpansRequestOptions, &input.Query.TimeStart, &input.Query.TimeEnd,
+		input.Query.Limit, true,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	spans, err := fetchPages(ctx, req, p, spanTimeKey,
+		func(ctx context.Context, end time.Time, limit int) ([]uptraceapi.Span, bool, error) {
+			opts := input.PublicListSpansRequestOptions
+			query := *opts.Query
+			query.TimeEnd = end
+			pageLimit := uptraceapi.Limit(limit)
+			query.Limit = &pageLimit
+			opts.Query = &query
+
+			resp, err := t.client.PublicListSpans(ctx, &opts)
+			if err != nil {
+				return nil, false, err
+			}
+			return resp.Spans, hasMore(resp.HasMore, len(resp.Spans), limit), nil
+		})
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return nil, resp, nil
+	tbl := spansTable(spans)
+	tbl.Meta = []metaField{{"returned", len(spans)}, {"has_more", p.more}}
+	tbl.Meta = append(tbl.Meta, p.meta()...)
+	tbl.Meta = append(timeRangeMeta(input.Query.TimeStart, input.Query.TimeEnd), tbl.Meta...)
+	return formatResult(t.conf, &input.formatOptions, tbl)
 }
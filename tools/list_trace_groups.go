@@ -30,15 +30,24 @@ func (t *ListTraceGroupsTool) Register(server *mcp.Server) {
 			IdempotentHint: true,
 			OpenWorldHint:  boolPtr(true),
 		},
-		Description: uptraceapi.Operations["list_trace_groups"].Description,
+		InputSchema:  timeRangeInputSchema[listTraceGroupsInput](),
+		OutputSchema: tableResultSchema(),
+		Description:  uptraceapi.Operations["list_trace_groups"].Description,
 	}, t.handler)
 }
 
+type listTraceGroupsInput struct {
+	uptraceapi.ListTraceGroupsRequestOptions
+	timeRangeOptions
+	paginationOptions
+	formatOptions
+}
+
 func (t *ListTraceGroupsTool) handler(
 	ctx context.Context,
 	req *mcp.CallToolRequest,
-	input *uptraceapi.ListTraceGroupsRequestOptions,
-) (*mcp.CallToolResult, *uptraceapi.ListTraceGroupsResponse, error) {
+	input *listTraceGroupsInput,
+) (*mcp.CallToolResult, any, error) {
 	if input.PathParams.ProjectID == 0 {
 		input.PathParams.ProjectID = t.conf.Uptrace.ProjectID
 	}
@@ -46,21 +55,48 @@ func (t *ListTraceGroupsTool) handler(
 	if input.Query == nil {
 		input.Query = &uptraceapi.ListTraceGroupsQuery{}
 	}
-	if input.Query.TimeStart.IsZero() {
-		input.Query.TimeStart = time.Now().Add(-t.conf.Default.TimeDuration)
-	}
-	if input.Query.TimeEnd.IsZero() {
-		input.Query.TimeEnd = time.Now()
+	if err := input.resolveTimeRange(
+		t.conf, &input.Query.TimeStart, &input.Query.TimeEnd,
+	); err != nil {
+		return nil, nil, err
 	}
 	if input.Query.Limit == nil {
 		defaultLimit := uptraceapi.Limit(t.conf.Default.Limit)
 		input.Query.Limit = &defaultLimit
 	}
 
-	resp, err := t.client.ListTraceGroups(ctx, input)
+	p, err := input.newPager(
+		&input.ListTraceGroupsRequestOptions, &input.Query.TimeStart, &input.Query.TimeEnd,
+		input.Query.Limit, false,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var columns []uptraceapi.QueryColumn
+	groups, err := fetchPages(ctx, req, p, nil,
+		func(ctx context.Context, end time.Time, limit int) ([]map[string]any, bool, error) {
+			opts := input.ListTraceGroupsRequestOptions
+			query := *opts.Query
+			query.TimeEnd = end
+			pageLimit := uptraceapi.Limit(limit)
+			query.Limit = &pageLimit
+			opts.Query = &query
+
+			resp, err := t.client.ListTraceGroups(ctx, &opts)
+			if err != nil {
+				return nil, false, err
+			}
+			columns = resp.Columns
+			return resp.Groups, hasMore(resp.HasMore, len(resp.Groups), limit), nil
+		})
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return nil, resp, nil
+	tbl := groupsTable(groups, columns)
+	tbl.Meta = []metaField{{"returned", len(groups)}, {"has_more", p.more}}
+	tbl.Meta = append(tbl.Meta, p.meta()...)
+	tbl.Meta = append(timeRangeMeta(input.Query.TimeStart, input.Query.TimeEnd), tbl.Meta...)
+	return formatResult(t.conf, &input.formatOptions, tbl)
 }
@@ -30,15 +30,24 @@ func (t *ListTracesTool) Register(server *mcp.Server) {
 			IdempotentHint: true,
 			OpenWorldHint:  boolPtr(true),
 		},
-		Description: uptraceapi.Operations["list_traces"].Description,
+		InputSchema:  timeRangeInputSchema[listTracesInput](),
+		OutputSchema: tableResultSchema(),
+		Description:  uptraceapi.Operations["list_traces"].Description,
 	}, t.handler)
 }
 
+type listTracesInput struct {
+	uptraceapi.ListTracesRequestOptions
+	timeRangeOptions
+	paginationOptions
+	formatOptions
+}
+
 func (t *ListTracesTool) handler(
 	ctx context.Context,
 	req *mcp.CallToolRequest,
-	input *uptraceapi.ListTracesRequestOptions,
-) (*mcp.CallToolResult, *uptraceapi.ListTracesResponse, error) {
+	input *listTracesInput,
+) (*mcp.CallToolResult, any, error) {
 	if input.PathParams.ProjectID == 0 {
 		input.PathParams.ProjectID = t.conf.Uptrace.ProjectID
 	}
@@ -46,21 +55,50 @@ func (t *ListTracesTool) handler(
 	if input.Query == nil {
 		input.Query = &uptraceapi.ListTracesQuery{}
 	}
-	if input.Query.TimeStart.IsZero() {
-		input.Query.TimeStart = time.Now().Add(-t.conf.Default.TimeDuration)
-	}
-	if input.Query.TimeEnd.IsZero() {
-		input.Query.TimeEnd = time.Now()
+	if err := input.resolveTimeRange(
+		t.conf, &input.Query.TimeStart, &input.Query.TimeEnd,
+	); err != nil {
+		return nil, nil, err
 	}
 	if input.Query.Limit == nil {
 		defaultLimit := uptraceapi.Limit(t.conf.Default.Limit)
 		input.Query.Limit = &defaultLimit
 	}
 
-	resp, err := t.client.ListTraces(ctx, input)
+	p, err := input.newPager(
+		&input.ListTracesRequestOptions, &input.Query.TimeStart, &input.Query.TimeEnd,
+		input.Query.Limit, sortedByTime(input.Query.SortBy, input.Query.SortDir),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var count int64
+	spans, err := fetchPages(ctx, req, p, spanTimeKey,
+		func(ctx context.Context, end time.Time, limit int) ([]uptraceapi.Span, bool, error) {
+			opts := input.ListTracesRequestOptions
+			query := *opts.Query
+			query.TimeEnd = end
+			pageLimit := uptraceapi.Limit(limit)
+			query.Limit = &pageLimit
+			opts.Query = &query
+
+			resp, err := t.client.ListTraces(ctx, &opts)
+			if err != nil {
+				return nil, false, err
+			}
+			if count == 0 {
+				count = resp.Count
+			}
+			return resp.Spans, resp.Count > int64(len(resp.Spans)), nil
+		})
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return nil, resp, nil
+	tbl := spansTable(spans)
+	tbl.Meta = []metaField{{"count", count}, {"returned", len(spans)}}
+	tbl.Meta = append(tbl.Meta, p.meta()...)
+	tbl.Meta = append(timeRangeMeta(input.Query.TimeStart, input.Query.TimeEnd), tbl.Meta...)
+	return formatResult(t.conf, &input.formatOptions, tbl)
 }
@@ -0,0 +1,374 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/uptraceapi"
+	"golang.org/x/sync/errgroup"
+)
+
+type MetricCardinalityReportTool struct {
+	client *uptraceapi.Client
+	conf   *appconf.Config
+}
+
+func NewMetricCardinalityReportTool(client *uptraceapi.Client, conf *appconf.Config) *MetricCardinalityReportTool {
+	return &MetricCardinalityReportTool{
+		client: client,
+		conf:   conf,
+	}
+}
+
+func (t *MetricCardinalityReportTool) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "metric_cardinality_report",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Metric cardinality report",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+		Description: "Find metric cardinality explosions. Ranks metrics by active timeseries, " +
+			"counts the distinct values of each attribute key of the top metrics to find which keys " +
+			"drive the cardinality (e.g., user_id on a histogram), totals timeseries per " +
+			"instrumentation library and suggests attributes to drop.",
+	}, t.handler)
+}
+
+type metricCardinalityReportInput struct {
+	ProjectID     int64     `json:"project_id,omitempty" jsonschema:"Uptrace project ID."`
+	TimeStart     time.Time `json:"time_start,omitempty" jsonschema:"Start time (inclusive) as RFC3339 timestamp."`
+	TimeEnd       time.Time `json:"time_end,omitempty" jsonschema:"End time (exclusive) as RFC3339 timestamp."`
+	Search        string    `json:"search,omitempty" jsonschema:"Only include metrics whose name contains this text."`
+	Limit         int       `json:"limit,omitempty" jsonschema:"Number of top metrics to analyze. Defaults to 10."`
+	MaxAttributes int       `json:"max_attributes,omitempty" jsonschema:"Maximum number of attribute keys to analyze per metric. Defaults to 20."`
+	MinValues     int       `json:"min_values,omitempty" jsonschema:"Attributes with at least this many distinct values are suggested for dropping. Defaults to 100."`
+
+	timeRangeOptions
+}
+
+type metricCardinalityReportOutput struct {
+	TimeStart       time.Time            `json:"time_start"`
+	TimeEnd         time.Time            `json:"time_end"`
+	TotalMetrics    int                  `json:"total_metrics"`
+	TotalTimeseries int                  `json:"total_timeseries"`
+	HasMore         bool                 `json:"has_more,omitempty" jsonschema:"More metrics exist than were returned; narrow the search."`
+	Metrics         []metricCardinality  `json:"metrics"`
+	Libraries       []libraryCardinality `json:"libraries"`
+	Suggestions     []dropSuggestion     `json:"suggestions"`
+}
+
+type metricCardinality struct {
+	Name       string            `json:"name"`
+	Instrument string            `json:"instrument"`
+	Library    string            `json:"library,omitempty"`
+	Timeseries int               `json:"timeseries"`
+	SharePct   float64           `json:"share_pct" jsonschema:"Percentage of all active timeseries."`
+	Attributes []attrCardinality `json:"attributes,omitempty" jsonschema:"Attribute keys ordered by the number of distinct values."`
+}
+
+type attrCardinality struct {
+	Key    string `json:"key"`
+	Values int    `json:"values" jsonschema:"Number of distinct values."`
+	More   bool   `json:"more,omitempty" jsonschema:"The attribute has more values than were counted."`
+}
+
+type libraryCardinality struct {
+	Library    string  `json:"library"`
+	Metrics    int     `json:"metrics"`
+	Timeseries int     `json:"timeseries"`
+	SharePct   float64 `json:"share_pct"`
+	TopMetric  string  `json:"top_metric"`
+}
+
+type dropSuggestion struct {
+	Metric    string `json:"metric"`
+	Attribute string `json:"attribute"`
+	Values    int    `json:"values"`
+	Reason    string `json:"reason"`
+}
+
+// idLikeAttr matches attribute names that usually hold per-request or per-user values.
+var idLikeAttr = regexp.MustCompile(`(?i)(^|[._])((user|session|request|trace|span|order|customer|account)_?)?(id|uuid|guid)$|(^|[._])(email|ip|url|uri|path|query)$`)
+
+func (t *MetricCardinalityReportTool) handler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input *metricCardinalityReportInput,
+) (*mcp.CallToolResult, *metricCardinalityReportOutput, error) {
+	projectID := input.ProjectID
+	if projectID == 0 {
+		projectID = t.conf.Uptrace.ProjectID
+	}
+	if err := input.resolveTimeRange(t.conf, &input.TimeStart, &input.TimeEnd); err != nil {
+		return nil, nil, err
+	}
+	if input.Limit == 0 {
+		input.Limit = 10
+	}
+	if input.MaxAttributes == 0 {
+		input.MaxAttributes = 20
+	}
+	if input.Limit < 1 {
+		return nil, nil, fmt.Errorf("limit must be at least 1, got %d", input.Limit)
+	}
+	if input.MaxAttributes < 1 {
+		return nil, nil, fmt.Errorf("max_attributes must be at least 1, got %d", input.MaxAttributes)
+	}
+	if input.MinValues == 0 {
+		input.MinValues = 100
+	}
+
+	query := &uptraceapi.ExploreMetricsQuery{
+		TimeStart: input.TimeStart,
+		TimeEnd:   input.TimeEnd,
+	}
+	if input.Search != "" {
+		query.Search = &input.Search
+	}
+	resp, err := t.client.ExploreMetrics(ctx, &uptraceapi.ExploreMetricsRequestOptions{
+		PathParams: &uptraceapi.ExploreMetricsPath{ProjectID: projectID},
+		Query:      query,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metrics := resp.Metrics
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return numTimeseries(&metrics[i]) > numTimeseries(&metrics[j])
+	})
+
+	out := &metricCardinalityReportOutput{
+		TimeStart:    input.TimeStart,
+		TimeEnd:      input.TimeEnd,
+		TotalMetrics: len(metrics),
+		HasMore:      resp.HasMore,
+		Metrics:      []metricCardinality{},
+		Suggestions:  []dropSuggestion{},
+	}
+	for i := range metrics {
+		out.TotalTimeseries += numTimeseries(&metrics[i])
+	}
+	out.Libraries = libraryCardinalities(metrics, out.TotalTimeseries)
+
+	top := metrics[:min(input.Limit, len(metrics))]
+	attrKeys, err := t.attrKeys(ctx, projectID, input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prog := newProgress(req, 0)
+	for i := range top {
+		prog.addTotal(min(len(top[i].AttrKeys), input.MaxAttributes))
+	}
+
+	out.Metrics = make([]metricCardinality, len(top))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(8)
+	var mu sync.Mutex
+	for i := range top {
+		m := &top[i]
+		out.Metrics[i] = metricCardinality{
+			Name:       m.Name,
+			Instrument: string(m.Instrument),
+			Timeseries: numTimeseries(m),
+			SharePct:   sharePct(numTimeseries(m), out.TotalTimeseries),
+		}
+		if m.LibraryName != nil {
+			out.Metrics[i].Library = *m.LibraryName
+		}
+
+		keys := m.AttrKeys
+		if len(keys) > input.MaxAttributes {
+			keys = keys[:input.MaxAttributes]
+		}
+		for _, key := range keys {
+			g.Go(func() error {
+				values, more, err := t.countValues(gctx, projectID, input, m.Name, attrKeys.typed(key))
+				if err != nil {
+					return err
+				}
+				mu.Lock()
+				out.Metrics[i].Attributes = append(out.Metrics[i].Attributes, attrCardinality{
+					Key:    attrName(key),
+					Values: values,
+					More:   more,
+				})
+				mu.Unlock()
+				prog.step(gctx, m.Name+": "+attrName(key))
+				return nil
+			})
+		}
+	}
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	for i := range out.Metrics {
+		m := &out.Metrics[i]
+		sort.Slice(m.Attributes, func(a, b int) bool {
+			if m.Attributes[a].Values != m.Attributes[b].Values {
+				return m.Attributes[a].Values > m.Attributes[b].Values
+			}
+			return m.Attributes[a].Key < m.Attributes[b].Key
+		})
+		out.Suggestions = append(out.Suggestions, dropSuggestions(m, input.MinValues)...)
+	}
+	sort.SliceStable(out.Suggestions, func(i, j int) bool {
+		return out.Suggestions[i].Values > out.Suggestions[j].Values
+	})
+	return nil, out, nil
+}
+
+// metricAttrKeys maps attribute names to keys with a type suffix, e.g. host_name
+// to host_name::str, as required by ListMetricAttributeValues.
+type metricAttrKeys map[string]string
+
+func (keys metricAttrKeys) typed(key string) string {
+	if strings.Contains(key, "::") {
+		return key
+	}
+	if typed, ok := keys[key]; ok {
+		return typed
+	}
+	return key + "::str"
+}
+
+func (t *MetricCardinalityReportTool) attrKeys(
+	ctx context.Context, projectID int64, input *metricCardinalityReportInput,
+) (metricAttrKeys, error) {
+	query := &uptraceapi.ListMetricAttributesQuery{
+		TimeStart: input.TimeStart,
+		TimeEnd:   input.TimeEnd,
+	}
+	if input.Search != "" {
+		query.Search = &input.Search
+	}
+	resp, err := t.client.ListMetricAttributes(ctx, &uptraceapi.ListMetricAttributesRequestOptions{
+		PathParams: &uptraceapi.ListMetricAttributesPath{ProjectID: projectID},
+		Query:      query,
+	})
+	if err != nil {
+		return nil, err
+	}
+	keys := make(metricAttrKeys, len(resp.Items))
+	for _, item := range resp.Items {
+		keys[attrName(item.Value)] = item.Value
+	}
+	return keys, nil
+}
+
+// countValues returns the number of distinct values of the attribute on the metric.
+func (t *MetricCardinalityReportTool) countValues(
+	ctx context.Context,
+	projectID int64,
+	input *metricCardinalityReportInput,
+	metric, attrKey string,
+) (int, bool, error) {
+	resp, err := t.client.ListMetricAttributeValues(ctx, &uptraceapi.ListMetricAttributeValuesRequestOptions{
+		PathParams: &uptraceapi.ListMetricAttributeValuesPath{ProjectID: projectID, AttrKey: attrKey},
+		Query: &uptraceapi.ListMetricAttributeValuesQuery{
+			TimeStart: input.TimeStart,
+			TimeEnd:   input.TimeEnd,
+			Search:    &metric,
+		},
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("count values of %s on %s: %w", attrKey, metric, err)
+	}
+	return len(resp.Items), resp.HasMore, nil
+}
+
+func numTimeseries(m *uptraceapi.ExploredMetric) int {
+	if m.NumTimeseries == nil {
+		return 0
+	}
+	return *m.NumTimeseries
+}
+
+func sharePct(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return roundTo(float64(n)/float64(total)*100, 1)
+}
+
+func libraryCardinalities(metrics []uptraceapi.ExploredMetric, total int) []libraryCardinality {
+	byName := make(map[string]*libraryCardinality)
+	topSeries := make(map[string]int)
+	for i := range metrics {
+		m := &metrics[i]
+		name := "unknown"
+		if m.LibraryName != nil && *m.LibraryName != "" {
+			name = *m.LibraryName
+		}
+		lib := byName[name]
+		if lib == nil {
+			lib = &libraryCardinality{Library: name}
+			byName[name] = lib
+		}
+		lib.Metrics++
+		lib.Timeseries += numTimeseries(m)
+		if n := numTimeseries(m); lib.TopMetric == "" || n > topSeries[name] {
+			lib.TopMetric = m.Name
+			topSeries[name] = n
+		}
+	}
+
+	libs := make([]libraryCardinality, 0, len(byName))
+	for _, lib := range byName {
+		lib.SharePct = sharePct(lib.Timeseries, total)
+		libs = append(libs, *lib)
+	}
+	sort.Slice(libs, func(i, j int) bool {
+		if libs[i].Timeseries != libs[j].Timeseries {
+			return libs[i].Timeseries > libs[j].Timeseries
+		}
+		return libs[i].Library < libs[j].Library
+	})
+	return libs
+}
+
+// dropSuggestions flags attributes with many distinct values and identifier-like
+// attributes, which multiply the number of timeseries without being useful to group by.
+func dropSuggestions(m *metricCardinality, minValues int) []dropSuggestion {
+	var out []dropSuggestion
+	for _, attr := range m.Attributes {
+		var reasons []string
+		if attr.Values >= minValues {
+			values := fmt.Sprintf("%d", attr.Values)
+			if attr.More {
+				values += "+"
+			}
+			reasons = append(reasons, values+" distinct values")
+		}
+		if idLikeAttr.MatchString(attr.Key) && attr.Values >= 10 {
+			reasons = append(reasons, "looks like a per-request or per-user identifier")
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+		if m.Timeseries > 0 && attr.Values*2 >= m.Timeseries {
+			reasons = append(reasons, "accounts for most of the metric's timeseries")
+		}
+		if m.Instrument == string(uptraceapi.Histogram) {
+			reasons = append(reasons, "every histogram timeseries also stores a set of buckets")
+		}
+		out = append(out, dropSuggestion{
+			Metric:    m.Name,
+			Attribute: attr.Key,
+			Values:    attr.Values,
+			Reason:    strings.Join(reasons, "; "),
+		})
+	}
+	return out
+}
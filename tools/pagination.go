@@ -0,0 +1,299 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+const (
+	// defaultPageSize is used when neither limit nor default.limit is set.
+	defaultPageSize = 100
+	// fetchAllPageSize is the page size of fetch_all when pages are fetched by
+	// time.
+	fetchAllPageSize = 1000
+	// maxPagedRows caps fetch_all and, since Uptrace has no offset parameter,
+	// how deep pages without a time sort key can go: every such page fetches all
+	// the rows before it again.
+	maxPagedRows = 10000
+)
+
+// paginationOptions are embedded into the input of list tools.
+type paginationOptions struct {
+	Cursor   string `json:"cursor,omitempty" jsonschema:"next_cursor of the previous page. Repeat the other parameters of the first call; the time range is taken from the cursor."`
+	FetchAll bool   `json:"fetch_all,omitempty" jsonschema:"Fetch pages until there are no more results, up to 10000 rows."`
+}
+
+// pageCursor is encoded into the opaque cursor returned as next_cursor.
+type pageCursor struct {
+	// Shape is a hash of the request without its time range and limit, so that
+	// a cursor is not resumed with a different query.
+	Shape     string    `json:"q"`
+	TimeStart time.Time `json:"s"`
+	// TimeEnd is the original end of the time range or, when paging by time,
+	// the end of the next page.
+	TimeEnd time.Time `json:"e"`
+	// Offset is the number of rows returned by the previous pages.
+	Offset int `json:"o,omitempty"`
+	// SeenIDs are the spans in the last millisecond of the previous page, which
+	// the next page includes again.
+	SeenIDs []string `json:"i,omitempty"`
+}
+
+// pager pages through the results of a list tool. Spans sorted by time, newest
+// first, are paged by moving time_end past the oldest span returned so far;
+// other results are paged by offset, requesting offset+limit rows and dropping
+// the rows of the previous pages.
+type pager struct {
+	cursor   pageCursor
+	limit    int
+	fetchAll bool
+	byTime   bool
+
+	pages int
+	more  bool
+}
+
+// newPager returns a pager for the request req, whose time range is start and
+// end, replacing the time range with the one of the cursor.
+func (o *paginationOptions) newPager(
+	req any, start, end *time.Time, limit *uptraceapi.Limit, byTime bool,
+) (*pager, error) {
+	shape, err := requestShape(req)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &pager{
+		limit:    defaultPageSize,
+		fetchAll: o.FetchAll,
+		byTime:   byTime,
+	}
+	if limit != nil && *limit > 0 {
+		p.limit = int(*limit)
+	}
+	if o.FetchAll {
+		if byTime {
+			p.limit = fetchAllPageSize
+		} else {
+			p.limit = maxPagedRows
+		}
+	}
+
+	if o.Cursor == "" {
+		p.cursor = pageCursor{Shape: shape, TimeStart: *start, TimeEnd: *end}
+		return p, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(o.Cursor)
+	if err == nil {
+		err = json.Unmarshal(b, &p.cursor)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor %q", o.Cursor)
+	}
+	if p.cursor.Shape != shape {
+		return nil, fmt.Errorf("cursor belongs to a different query; " +
+			"repeat the parameters of the call that returned it, changing only cursor, limit and fetch_all")
+	}
+	*start, *end = p.cursor.TimeStart, p.cursor.TimeEnd
+	return p, nil
+}
+
+// meta returns the pagination fields of the formatted result.
+func (p *pager) meta() []metaField {
+	var meta []metaField
+	if p.fetchAll {
+		meta = append(meta, metaField{"pages", p.pages})
+	}
+	if p.more {
+		meta = append(meta, metaField{"next_cursor", p.nextCursor()})
+	}
+	return meta
+}
+
+func (p *pager) nextCursor() string {
+	b, _ := json.Marshal(p.cursor)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// pageFetch requests up to limit rows ending at end and reports whether Uptrace
+// has more rows than it returned.
+type pageFetch[T any] func(ctx context.Context, end time.Time, limit int) ([]T, bool, error)
+
+// spanKey returns the time in unix milliseconds and the ID of a row paged by
+// time.
+type spanKey[T any] func(row T) (float64, string)
+
+// fetchPages fetches one page or, with fetch_all, every page up to maxPagedRows.
+// key is only used when paging by time.
+func fetchPages[T any](
+	ctx context.Context, req *mcp.CallToolRequest, p *pager, key spanKey[T], fetch pageFetch[T],
+) ([]T, error) {
+	maxRows := p.limit
+	if p.fetchAll {
+		maxRows = maxPagedRows
+	}
+	prog := newProgress(req, 0)
+
+	var rows []T
+	for {
+		var page []T
+		var err error
+		if p.byTime {
+			page, err = fetchTimePage(ctx, p, min(p.limit, maxRows-len(rows)), key, fetch)
+		} else {
+			page, err = fetchOffsetPage(ctx, p, min(p.limit, maxRows-len(rows)), fetch)
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, page...)
+		p.pages++
+		if p.fetchAll {
+			prog.step(ctx, fmt.Sprintf("page %d: %d rows", p.pages, len(rows)))
+		}
+
+		if !p.more || len(rows) >= maxRows || len(page) == 0 {
+			return rows, nil
+		}
+	}
+}
+
+func fetchOffsetPage[T any](ctx context.Context, p *pager, limit int, fetch pageFetch[T]) ([]T, error) {
+	offset := p.cursor.Offset
+	if offset+limit > maxPagedRows {
+		if offset >= maxPagedRows {
+			return nil, fmt.Errorf("cannot page past %d rows without sorting by time; narrow the query", maxPagedRows)
+		}
+		limit = maxPagedRows - offset
+	}
+
+	rows, more, err := fetch(ctx, p.cursor.TimeEnd, offset+limit)
+	if err != nil {
+		return nil, err
+	}
+	rows = rows[min(offset, len(rows)):]
+	p.cursor.Offset += len(rows)
+	p.more = more
+	return rows, nil
+}
+
+func fetchTimePage[T any](
+	ctx context.Context, p *pager, limit int, key spanKey[T], fetch pageFetch[T],
+) ([]T, error) {
+	seen := make(map[string]bool, len(p.cursor.SeenIDs))
+	for _, id := range p.cursor.SeenIDs {
+		seen[id] = true
+	}
+
+	rows, more, err := fetch(ctx, p.cursor.TimeEnd, limit+len(seen))
+	if err != nil {
+		return nil, err
+	}
+	page := make([]T, 0, min(limit, len(rows)))
+	for _, row := range rows {
+		if _, id := key(row); seen[id] {
+			continue
+		}
+		if len(page) == limit {
+			more = true
+			break
+		}
+		page = append(page, row)
+	}
+
+	p.more = more
+	p.cursor.Offset += len(page)
+	if len(page) == 0 {
+		return page, nil
+	}
+
+	// Continue with the millisecond of the oldest row, which may hold more rows,
+	// and skip the ones already returned.
+	lastMs, _ := key(page[len(page)-1])
+	last := math.Floor(lastMs)
+	end := time.UnixMilli(int64(last) + 1).UTC()
+	if !end.Equal(p.cursor.TimeEnd) {
+		p.cursor.SeenIDs = nil
+	}
+	p.cursor.TimeEnd = end
+	for _, row := range page {
+		if ms, id := key(row); math.Floor(ms) == last {
+			p.cursor.SeenIDs = append(p.cursor.SeenIDs, id)
+		}
+	}
+	return page, nil
+}
+
+// hasMore reports whether there are rows beyond a page of n rows, assuming so
+// for full pages when Uptrace does not say.
+func hasMore(flag *bool, n, limit int) bool {
+	if flag != nil {
+		return *flag
+	}
+	return n >= limit
+}
+
+// sortedByTime reports whether sortBy and sortDir keep the default order of
+// spans, newest first.
+func sortedByTime(sortBy []string, sortDir []uptraceapi.SortDirection) bool {
+	if len(sortBy) > 1 || len(sortDir) > 1 {
+		return false
+	}
+	if len(sortBy) == 1 && sortBy[0] != "_time" && sortBy[0] != "time" {
+		return false
+	}
+	return len(sortDir) == 0 || strings.EqualFold(string(sortDir[0]), string(uptraceapi.DESC))
+}
+
+func spanTimeKey(span uptraceapi.Span) (float64, string) {
+	return span.Time, span.ID
+}
+
+// requestShape hashes req without time_start, time_end and limit.
+func requestShape(req any) (string, error) {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return "", err
+	}
+	b, err = json.Marshal(dropPageKeys(v))
+	if err != nil {
+		return "", err
+	}
+	h := fnv.New64a()
+	h.Write(b)
+	return strconv.FormatUint(h.Sum64(), 36), nil
+}
+
+func dropPageKeys(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			switch key {
+			case "time_start", "time_end", "limit":
+				delete(v, key)
+			default:
+				v[key] = dropPageKeys(value)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = dropPageKeys(value)
+		}
+	}
+	return v
+}
@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/monitor"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+type PlanMonitorsTool struct {
+	client *uptraceapi.Client
+	conf   *appconf.Config
+}
+
+func NewPlanMonitorsTool(client *uptraceapi.Client, conf *appconf.Config) *PlanMonitorsTool {
+	return &PlanMonitorsTool{
+		client: client,
+		conf:   conf,
+	}
+}
+
+func (t *PlanMonitorsTool) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "plan_monitors",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Plan monitor changes",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+		Description: "Compare YAML monitor definitions with the monitors in Uptrace and show which " +
+			"monitors would be created, updated or deleted, with a diff for each. Nothing is changed; " +
+			"apply the plan with the `monitors apply` CLI command. Monitors are matched by name. " +
+			"A definition has name, type (metric or error), metrics (\"metric_name as $alias\"), query, " +
+			"channels (notification channel names), repeat_interval (strategy default, fixed, linear or " +
+			"exponential with interval or min/max durations such as 15m) and, for metric monitors, " +
+			"min_allowed_value, max_allowed_value, num_eval_points, flapping, nulls_mode and time_offset.",
+	}, t.handler)
+}
+
+type planMonitorsInput struct {
+	ProjectID int64  `json:"project_id,omitempty" jsonschema:"Uptrace project ID."`
+	YAML      string `json:"yaml" jsonschema:"Monitor definitions, one per YAML document separated by ---."`
+	Prune     bool   `json:"prune,omitempty" jsonschema:"Plan to delete monitors that have no definition."`
+}
+
+type planMonitorsOutput struct {
+	Summary   string            `json:"summary"`
+	Changes   []*monitor.Change `json:"changes"`
+	Unchanged int               `json:"unchanged"`
+	Unmanaged []string          `json:"unmanaged,omitempty" jsonschema:"Monitors that only exist in Uptrace and are kept because prune is off."`
+	Invalid   []monitor.Invalid `json:"invalid,omitempty" jsonschema:"Definitions with errors. A plan with invalid definitions can't be applied."`
+	Warnings  []string          `json:"warnings,omitempty" jsonschema:"Problems with monitors in Uptrace, e.g. references to deleted notification channels."`
+}
+
+func (t *PlanMonitorsTool) handler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input *planMonitorsInput,
+) (*mcp.CallToolResult, *planMonitorsOutput, error) {
+	projectID := input.ProjectID
+	if projectID == 0 {
+		projectID = t.conf.Uptrace.ProjectID
+	}
+
+	if input.YAML == "" {
+		return nil, nil, fmt.Errorf("yaml is required")
+	}
+	files := monitor.ParseFile("input", []byte(input.YAML))
+
+	plan, err := monitor.NewSyncer(t.client, projectID).Plan(ctx, files, input.Prune)
+	if err != nil {
+		return nil, nil, err
+	}
+	out := &planMonitorsOutput{
+		Summary:   plan.Summary(),
+		Changes:   plan.Changes,
+		Unchanged: plan.Unchanged,
+		Unmanaged: plan.Unmanaged,
+		Invalid:   plan.Invalid,
+		Warnings:  plan.Warnings,
+	}
+	if out.Changes == nil {
+		out.Changes = []*monitor.Change{}
+	}
+	return nil, out, nil
+}
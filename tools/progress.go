@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"context"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// progress reports the progress of a tool call with notifications/progress. It
+// only sends notifications when the client passed a progress token and is safe
+// for concurrent use, so errgroup workers can report finished steps directly.
+type progress struct {
+	session *mcp.ServerSession
+	token   any
+
+	mu    sync.Mutex
+	done  float64
+	total float64
+}
+
+// newProgress returns a progress for req with the given number of steps; total
+// can be zero when it is not known yet.
+func newProgress(req *mcp.CallToolRequest, total int) *progress {
+	p := &progress{total: float64(total)}
+	if req != nil && req.Session != nil && req.Params != nil {
+		p.session = req.Session
+		p.token = req.Params.GetProgressToken()
+	}
+	return p
+}
+
+// addTotal adds n steps discovered while the call is running.
+func (p *progress) addTotal(n int) {
+	p.mu.Lock()
+	p.total += float64(n)
+	p.mu.Unlock()
+}
+
+// step marks one step as done and notifies the client. Notifications are best
+// effort: a client that went away is noticed through ctx by the next request.
+func (p *progress) step(ctx context.Context, message string) {
+	if p.token == nil {
+		return
+	}
+
+	// Send under the lock so that concurrent steps arrive in increasing order.
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	_ = p.session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: p.token,
+		Progress:      p.done,
+		Total:         p.total,
+		Message:       message,
+	})
+}
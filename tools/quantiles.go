@@ -2,7 +2,6 @@ package tools
 
 import (
 	"context"
-	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/uptrace/mcp/appconf"
@@ -30,15 +29,23 @@ func (t *QueryQuantilesTool) Register(server *mcp.Server) {
 			IdempotentHint: true,
 			OpenWorldHint:  boolPtr(true),
 		},
-		Description: uptraceapi.Operations["query_quantiles"].Description,
+		InputSchema:  timeRangeInputSchema[queryQuantilesInput](),
+		OutputSchema: tableResultSchema(),
+		Description:  uptraceapi.Operations["query_quantiles"].Description,
 	}, t.handler)
 }
 
+type queryQuantilesInput struct {
+	uptraceapi.QueryQuantilesRequestOptions
+	timeRangeOptions
+	formatOptions
+}
+
 func (t *QueryQuantilesTool) handler(
 	ctx context.Context,
 	req *mcp.CallToolRequest,
-	input *uptraceapi.QueryQuantilesRequestOptions,
-) (*mcp.CallToolResult, *uptraceapi.QueryQuantilesResponse, error) {
+	input *queryQuantilesInput,
+) (*mcp.CallToolResult, any, error) {
 	if input.PathParams.ProjectID == 0 {
 		input.PathParams.ProjectID = t.conf.Uptrace.ProjectID
 	}
@@ -46,21 +53,22 @@ func (t *QueryQuantilesTool) handler(
 	if input.Query == nil {
 		input.Query = &uptraceapi.QueryQuantilesQuery{}
 	}
-	if input.Query.TimeStart.IsZero() {
-		input.Query.TimeStart = time.Now().Add(-t.conf.Default.TimeDuration)
-	}
-	if input.Query.TimeEnd.IsZero() {
-		input.Query.TimeEnd = time.Now()
+	if err := input.resolveTimeRange(
+		t.conf, &input.Query.TimeStart, &input.Query.TimeEnd,
+	); err != nil {
+		return nil, nil, err
 	}
 	if input.Query.Limit == nil {
 		defaultLimit := uptraceapi.Limit(t.conf.Default.Limit)
 		input.Query.Limit = &defaultLimit
 	}
 
-	resp, err := t.client.QueryQuantiles(ctx, input)
+	resp, err := t.client.QueryQuantiles(ctx, &input.QueryQuantilesRequestOptions)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return nil, resp, nil
+	tbl := quantilesTable(resp)
+	tbl.Meta = append(timeRangeMeta(input.Query.TimeStart, input.Query.TimeEnd), tbl.Meta...)
+	return formatResult(t.conf, &input.formatOptions, tbl)
 }
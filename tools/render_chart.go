@@ -0,0 +1,341 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/chart"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+type RenderChartTool struct {
+	client *uptraceapi.Client
+	conf   *appconf.Config
+}
+
+func NewRenderChartTool(client *uptraceapi.Client, conf *appconf.Config) *RenderChartTool {
+	return &RenderChartTool{
+		client: client,
+		conf:   conf,
+	}
+}
+
+func (t *RenderChartTool) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "render_chart",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Render chart",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+		Description: "Render a timeseries or quantiles query as a chart image (PNG or SVG). " +
+			"Use source=timeseries with a UQL aggregation query (e.g., perMin(count()) | group by service_name) " +
+			"or source=quantiles for latency percentiles. Supports line, stacked_area and heatmap charts " +
+			"with units, a legend per group and annotation markers (e.g., deploys or incidents). " +
+			"Returns the image together with a short text summary of each series.",
+	}, t.handler)
+}
+
+type renderChartInput struct {
+	ProjectID   int64             `json:"project_id,omitempty" jsonschema:"Uptrace project ID."`
+	TimeStart   time.Time         `json:"time_start,omitempty" jsonschema:"Start time (inclusive) as RFC3339 timestamp."`
+	TimeEnd     time.Time         `json:"time_end,omitempty" jsonschema:"End time (exclusive) as RFC3339 timestamp."`
+	Source      string            `json:"source,omitempty" jsonschema:"Data source: timeseries or quantiles. Defaults to timeseries."`
+	Query       string            `json:"query,omitempty" jsonschema:"UQL query. For timeseries, an aggregation query (e.g., perMin(count()) | group by service_name); for quantiles, a filter (e.g., where service_name = \"checkout\")."`
+	Where       string            `json:"where,omitempty" jsonschema:"Additional WHERE clause appended to the query."`
+	System      []string          `json:"system,omitempty" jsonschema:"Filter by system (e.g., httpserver:all)."`
+	Column      []string          `json:"column,omitempty" jsonschema:"Columns to plot. For quantiles, timeseries names such as durationP50, durationP99 or countPerMin. Defaults to all aggregated columns for timeseries and durationP50, durationP90, durationP99 for quantiles."`
+	Kind        string            `json:"kind,omitempty" jsonschema:"Chart kind: line, stacked_area or heatmap. Defaults to line."`
+	Render      string            `json:"render,omitempty" jsonschema:"Image format: png or svg. Defaults to png."`
+	Title       string            `json:"title,omitempty" jsonschema:"Chart title. Defaults to the query."`
+	Width       int               `json:"width,omitempty" jsonschema:"Image width in pixels. Defaults to 800."`
+	Height      int               `json:"height,omitempty" jsonschema:"Image height in pixels. Defaults to 400."`
+	Annotations []chartAnnotation `json:"annotations,omitempty" jsonschema:"Vertical markers, e.g. deploys or incidents."`
+	MaxSeries   int               `json:"max_series,omitempty" jsonschema:"Maximum number of series to draw; the series with the largest average are kept. Defaults to 10."`
+
+	timeRangeOptions
+}
+
+type chartAnnotation struct {
+	Time  time.Time `json:"time" jsonschema:"Marker time as RFC3339 timestamp."`
+	Label string    `json:"label,omitempty" jsonschema:"Marker label."`
+}
+
+func (t *RenderChartTool) handler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input *renderChartInput,
+) (*mcp.CallToolResult, any, error) {
+	projectID := input.ProjectID
+	if projectID == 0 {
+		projectID = t.conf.Uptrace.ProjectID
+	}
+	if err := input.resolveTimeRange(t.conf, &input.TimeStart, &input.TimeEnd); err != nil {
+		return nil, nil, err
+	}
+	if input.Source == "" {
+		input.Source = "timeseries"
+	}
+	if input.Render == "" {
+		input.Render = "png"
+	}
+	if input.MaxSeries == 0 {
+		input.MaxSeries = 10
+	}
+	if input.MaxSeries < 1 {
+		return nil, nil, fmt.Errorf("max_series must be at least 1, got %d", input.MaxSeries)
+	}
+
+	var c *chart.Chart
+	var err error
+	switch input.Source {
+	case "timeseries":
+		c, err = t.timeseriesChart(ctx, projectID, input)
+	case "quantiles":
+		c, err = t.quantilesChart(ctx, projectID, input)
+	default:
+		return nil, nil, fmt.Errorf("unknown source %q (supported: timeseries, quantiles)", input.Source)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(c.Series) == 0 || len(c.Times) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: "The query returned no data to chart."},
+			},
+		}, nil, nil
+	}
+
+	c.Kind = chart.Kind(input.Kind)
+	c.Title = input.Title
+	if c.Title == "" {
+		c.Title = input.Query
+	}
+	c.Width = input.Width
+	c.Height = input.Height
+	for _, ann := range input.Annotations {
+		c.Annotations = append(c.Annotations, chart.Annotation{Time: ann.Time, Label: ann.Label})
+	}
+
+	omitted := limitSeries(c, input.MaxSeries)
+
+	var image *mcp.ImageContent
+	switch input.Render {
+	case "png":
+		data, err := c.PNG()
+		if err != nil {
+			return nil, nil, err
+		}
+		image = &mcp.ImageContent{Data: data, MIMEType: "image/png"}
+	case "svg":
+		data, err := c.SVG()
+		if err != nil {
+			return nil, nil, err
+		}
+		image = &mcp.ImageContent{Data: data, MIMEType: "image/svg+xml"}
+	default:
+		return nil, nil, fmt.Errorf("unknown render format %q (supported: png, svg)", input.Render)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			image,
+			&mcp.TextContent{Text: chartSummary(c, input.TimeStart, input.TimeEnd, omitted)},
+		},
+	}, nil, nil
+}
+
+func (t *RenderChartTool) timeseriesChart(
+	ctx context.Context, projectID int64, input *renderChartInput,
+) (*chart.Chart, error) {
+	query := &uptraceapi.QueryTimeseriesQuery{
+		TimeStart: input.TimeStart,
+		TimeEnd:   input.TimeEnd,
+		System:    input.System,
+		Column:    input.Column,
+	}
+	if input.Query != "" {
+		query.Query = &input.Query
+	} else {
+		query.Query = &t.conf.Default.Query
+	}
+	if input.Where != "" {
+		query.Where = &input.Where
+	}
+	if t.conf.Default.Limit != 0 {
+		limit := uptraceapi.Limit(t.conf.Default.Limit)
+		query.Limit = &limit
+	}
+
+	resp, err := t.client.QueryTimeseries(ctx, &uptraceapi.QueryTimeseriesRequestOptions{
+		PathParams: &uptraceapi.QueryTimeseriesPath{ProjectID: projectID},
+		Query:      query,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c := &chart.Chart{Times: chartTimes(resp.Time)}
+	allSeries := timeseriesSeries(resp)
+	multiColumn := false
+	for _, s := range allSeries {
+		if s.Column != allSeries[0].Column {
+			multiColumn = true
+			break
+		}
+	}
+
+	for _, s := range allSeries {
+		if c.Unit == "" {
+			c.Unit = s.Unit
+		}
+		c.Series = append(c.Series, chart.Series{
+			Name:   seriesName(s, multiColumn),
+			Values: alignValues(s.Values, len(c.Times)),
+		})
+	}
+	return c, nil
+}
+
+func (t *RenderChartTool) quantilesChart(
+	ctx context.Context, projectID int64, input *renderChartInput,
+) (*chart.Chart, error) {
+	query := &uptraceapi.QueryQuantilesQuery{
+		TimeStart: input.TimeStart,
+		TimeEnd:   input.TimeEnd,
+		System:    input.System,
+	}
+	if input.Query != "" {
+		query.Query = &input.Query
+	}
+	if input.Where != "" {
+		query.Where = &input.Where
+	}
+
+	resp, err := t.client.QueryQuantiles(ctx, &uptraceapi.QueryQuantilesRequestOptions{
+		PathParams: &uptraceapi.QueryQuantilesPath{ProjectID: projectID},
+		Query:      query,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	columns := input.Column
+	if len(columns) == 0 {
+		columns = []string{"durationP50", "durationP90", "durationP99"}
+	}
+
+	c := new(chart.Chart)
+	for _, ts := range resp.Timeseries {
+		if !slices.Contains(columns, ts.Name) {
+			continue
+		}
+		if c.Times == nil {
+			c.Times = chartTimes(ts.Time)
+		}
+		if c.Unit == "" && strings.HasPrefix(ts.Name, "duration") {
+			c.Unit = "milliseconds"
+		}
+		c.Series = append(c.Series, chart.Series{
+			Name:   ts.Name,
+			Values: alignValues(ts.Value, len(c.Times)),
+		})
+	}
+	return c, nil
+}
+
+func chartTimes(ms []float64) []time.Time {
+	times := make([]time.Time, len(ms))
+	for i, v := range ms {
+		times[i] = time.UnixMilli(int64(v)).UTC()
+	}
+	return times
+}
+
+// alignValues pads or truncates values to n points.
+func alignValues(values []float64, n int) []float64 {
+	if len(values) >= n {
+		return values[:n]
+	}
+	out := make([]float64, n)
+	copy(out, values)
+	for i := len(values); i < n; i++ {
+		out[i] = math.NaN()
+	}
+	return out
+}
+
+// seriesName joins group labels, e.g. service_name=checkout host_name=web-1.
+func seriesName(s series, withColumn bool) string {
+	keys := make([]string, 0, len(s.Group))
+	for key := range s.Group {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys)+1)
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", attrName(key), s.Group[key]))
+	}
+	if withColumn || len(parts) == 0 {
+		parts = append(parts, s.Column)
+	}
+	return strings.Join(parts, " ")
+}
+
+// limitSeries keeps the series with the largest average and returns how many were dropped.
+func limitSeries(c *chart.Chart, maxSeries int) int {
+	if len(c.Series) <= maxSeries {
+		return 0
+	}
+	sort.SliceStable(c.Series, func(i, j int) bool {
+		return seriesAvg(c.Series[i].Values) > seriesAvg(c.Series[j].Values)
+	})
+	omitted := len(c.Series) - maxSeries
+	c.Series = c.Series[:maxSeries]
+	return omitted
+}
+
+func seriesAvg(values []float64) float64 {
+	if stats, ok := newSeriesStats(values); ok {
+		return stats.Avg
+	}
+	return math.Inf(-1)
+}
+
+func chartSummary(c *chart.Chart, start, end time.Time, omitted int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s chart of %d series from %s to %s",
+		strings.ReplaceAll(string(c.Kind), "_", " "), len(c.Series),
+		start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339))
+	if len(c.Times) > 1 {
+		fmt.Fprintf(&b, " (%s interval)", formatDuration(c.Times[1].Sub(c.Times[0])))
+	}
+	b.WriteString(".\n")
+
+	for _, s := range c.Series {
+		stats, ok := newSeriesStats(s.Values)
+		if !ok {
+			fmt.Fprintf(&b, "- %s: no data\n", s.Name)
+			continue
+		}
+		fmt.Fprintf(&b, "- %s: min %s, avg %s, max %s, last %s\n", s.Name,
+			chart.FormatValue(stats.Min, c.Unit),
+			chart.FormatValue(stats.Avg, c.Unit),
+			chart.FormatValue(stats.Max, c.Unit),
+			chart.FormatValue(stats.Last, c.Unit))
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "%d more series with smaller averages were omitted; increase max_series to draw them.\n", omitted)
+	}
+	return b.String()
+}
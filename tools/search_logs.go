@@ -0,0 +1,354 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+type SearchLogsTool struct {
+	client *uptraceapi.Client
+	conf   *appconf.Config
+}
+
+func NewSearchLogsTool(client *uptraceapi.Client, conf *appconf.Config) *SearchLogsTool {
+	return &SearchLogsTool{
+		client: client,
+		conf:   conf,
+	}
+}
+
+func (t *SearchLogsTool) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "search_logs",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Search logs",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+		OutputSchema: tableResultSchema(),
+		Description: "Search logs stored in Uptrace (spans with log:* systems). Filter by severity, " +
+			"service, free text and trace ID. In lines mode, returns one row per log with " +
+			"_time, severity, service, message and trace_id. In patterns mode, clusters similar " +
+			"messages by masking variable parts (numbers, IDs) and returns message patterns with counts; " +
+			"meta has the number of matching logs per log system.",
+	}, t.handler)
+}
+
+type searchLogsInput struct {
+	ProjectID   int64     `json:"project_id,omitempty" jsonschema:"Uptrace project ID."`
+	TimeStart   time.Time `json:"time_start,omitempty" jsonschema:"Start time (inclusive) as RFC3339 timestamp."`
+	TimeEnd     time.Time `json:"time_end,omitempty" jsonschema:"End time (exclusive) as RFC3339 timestamp."`
+	Severity    []string  `json:"severity,omitempty" jsonschema:"Severities to include: trace, debug, info, warn, error, fatal. Defaults to all."`
+	Service     string    `json:"service,omitempty" jsonschema:"Filter by service name."`
+	Search      string    `json:"search,omitempty" jsonschema:"Full-text search for logs containing the given text."`
+	SearchAttrs []string  `json:"search_attrs,omitempty" jsonschema:"Attribute names to search within when using search."`
+	TraceID     string    `json:"trace_id,omitempty" jsonschema:"Only return logs correlated with this trace ID."`
+	Query       string    `json:"query,omitempty" jsonschema:"Additional UQL filter (e.g., where host_name = \"web-1\")."`
+	Mode        string    `json:"mode,omitempty" jsonschema:"Output mode: lines or patterns. Defaults to lines."`
+	Limit       int       `json:"limit,omitempty" jsonschema:"Maximum number of logs to fetch. Defaults to 100 in lines mode and 1000 in patterns mode."`
+
+	timeRangeOptions
+	formatOptions
+}
+
+func (t *SearchLogsTool) handler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input *searchLogsInput,
+) (*mcp.CallToolResult, any, error) {
+	projectID := input.ProjectID
+	if projectID == 0 {
+		projectID = t.conf.Uptrace.ProjectID
+	}
+	if err := input.resolveTimeRange(t.conf, &input.TimeStart, &input.TimeEnd); err != nil {
+		return nil, nil, err
+	}
+	if input.Mode == "" {
+		input.Mode = "lines"
+	}
+	if input.Limit == 0 {
+		input.Limit = 100
+		if input.Mode == "patterns" {
+			input.Limit = 1000
+		}
+	}
+
+	systems := []string{"log:all"}
+	if len(input.Severity) > 0 {
+		systems = make([]string, len(input.Severity))
+		for i, sev := range input.Severity {
+			systems[i] = "log:" + strings.ToLower(sev)
+		}
+	}
+
+	var filters []string
+	if input.Service != "" {
+		filters = append(filters, fmt.Sprintf("where service_name = %s", strconv.Quote(input.Service)))
+	}
+	if input.TraceID != "" {
+		filters = append(filters, fmt.Sprintf("where _trace_id = %s", strconv.Quote(input.TraceID)))
+	}
+	if input.Query != "" {
+		filters = append(filters, input.Query)
+	}
+
+	q := &uptraceapi.ListSpansQuery{
+		TimeStart:   input.TimeStart,
+		TimeEnd:     input.TimeEnd,
+		System:      systems,
+		SearchAttrs: input.SearchAttrs,
+	}
+	if len(filters) > 0 {
+		query := strings.Join(filters, " | ")
+		q.Query = &query
+	}
+	if input.Search != "" {
+		q.Search = &input.Search
+	}
+	limit := uptraceapi.Limit(input.Limit)
+	q.Limit = &limit
+
+	resp, err := t.client.ListSpans(ctx, &uptraceapi.ListSpansRequestOptions{
+		PathParams: &uptraceapi.ListSpansPath{ProjectID: projectID},
+		Query:      q,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tbl *table
+	switch input.Mode {
+	case "lines":
+		tbl = logLinesTable(resp)
+	case "patterns":
+		bySystem, err := t.countBySystem(ctx, projectID, q)
+		if err != nil {
+			return nil, nil, err
+		}
+		tbl = logPatternsTable(resp, bySystem)
+	default:
+		return nil, nil, fmt.Errorf("unknown mode %q (supported: lines, patterns)", input.Mode)
+	}
+	tbl.Meta = append(timeRangeMeta(input.TimeStart, input.TimeEnd), tbl.Meta...)
+	return formatResult(t.conf, &input.formatOptions, tbl)
+}
+
+// countBySystem returns the number of matching logs per log system, e.g. log:error.
+func (t *SearchLogsTool) countBySystem(
+	ctx context.Context, projectID int64, spansQuery *uptraceapi.ListSpansQuery,
+) (map[string]float64, error) {
+	query := "group by _system | count()"
+	if spansQuery.Query != nil {
+		query = *spansQuery.Query + " | " + query
+	}
+
+	resp, err := t.client.ListSpanGroups(ctx, &uptraceapi.ListSpanGroupsRequestOptions{
+		PathParams: &uptraceapi.ListSpanGroupsPath{ProjectID: projectID},
+		Query: &uptraceapi.ListSpanGroupsQuery{
+			TimeStart:   spansQuery.TimeStart,
+			TimeEnd:     spansQuery.TimeEnd,
+			Query:       &query,
+			System:      spansQuery.System,
+			Search:      spansQuery.Search,
+			SearchAttrs: spansQuery.SearchAttrs,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]float64, len(resp.Groups))
+	for _, row := range resp.Groups {
+		system, _ := row["_system"].(string)
+		if system == "" {
+			continue
+		}
+		counts[system] += sumAggColumn(&uptraceapi.GroupsResult{
+			Groups:  []map[string]any{row},
+			Columns: resp.Columns,
+		})
+	}
+	return counts, nil
+}
+
+// logLine holds the fields of a log record that are worth showing to a reader.
+type logLine struct {
+	Time     time.Time
+	Severity string
+	Service  string
+	Message  string
+	TraceID  string
+}
+
+func newLogLine(span *uptraceapi.Span) logLine {
+	line := logLine{
+		Time:    time.UnixMilli(int64(span.Time)).UTC(),
+		TraceID: span.TraceID,
+		Message: span.Name,
+	}
+
+	if sev := attrString(span.Attrs, "log_severity"); sev != "" {
+		line.Severity = strings.ToUpper(sev)
+	} else if span.System != nil {
+		_, sev, _ := strings.Cut(*span.System, ":")
+		line.Severity = strings.ToUpper(sev)
+	}
+	line.Service = attrString(span.Attrs, "service_name")
+
+	if msg := attrString(span.Attrs, "log_message"); msg != "" {
+		line.Message = msg
+	} else if span.DisplayName != nil && *span.DisplayName != "" {
+		line.Message = *span.DisplayName
+	}
+	line.Message = strings.ReplaceAll(strings.TrimSpace(line.Message), "\n", `\n`)
+
+	return line
+}
+
+func logLinesTable(resp *uptraceapi.ListSpansResponse) *table {
+	tbl := &table{
+		Meta:    []metaField{{"count", resp.Count}, {"returned", len(resp.Spans)}},
+		Columns: []string{"_time", "severity", "service", "message", "trace_id"},
+		Rows:    make([]map[string]any, len(resp.Spans)),
+	}
+	for i := range resp.Spans {
+		line := newLogLine(&resp.Spans[i])
+		tbl.Rows[i] = map[string]any{
+			"_time":    line.Time.Format("2006-01-02T15:04:05.000Z07:00"),
+			"severity": line.Severity,
+			"service":  line.Service,
+			"message":  line.Message,
+			"trace_id": line.TraceID,
+		}
+	}
+	return tbl
+}
+
+// logPatternsTable returns a row per message pattern. The number of matching
+// logs per system, e.g. log:error, goes into meta.
+func logPatternsTable(resp *uptraceapi.ListSpansResponse, bySystem map[string]float64) *table {
+	systems := make([]string, 0, len(bySystem))
+	for system := range bySystem {
+		systems = append(systems, system)
+	}
+	sort.Slice(systems, func(i, j int) bool { return bySystem[systems[i]] > bySystem[systems[j]] })
+
+	patterns := clusterLogMessages(resp.Spans)
+	tbl := &table{
+		Meta: []metaField{
+			{"count", resp.Count},
+			{"sampled", len(resp.Spans)},
+			{"patterns", len(patterns)},
+		},
+		Columns: []string{"count", "severity", "service", "pattern", "trace_id"},
+		Rows:    make([]map[string]any, len(patterns)),
+	}
+	for _, system := range systems {
+		tbl.Meta = append(tbl.Meta, metaField{system, bySystem[system]})
+	}
+	for i, p := range patterns {
+		tbl.Rows[i] = map[string]any{
+			"count":    p.Count,
+			"severity": p.Severity,
+			"service":  p.Service,
+			"pattern":  p.Pattern,
+			"trace_id": p.TraceID,
+		}
+	}
+	return tbl
+}
+
+// logPattern is a message template shared by similar log messages.
+type logPattern struct {
+	Pattern  string
+	tokens   []string
+	Count    int
+	Severity string
+	Service  string
+	TraceID  string // trace ID of an example log
+}
+
+// clusterLogMessages groups log messages by template. Tokens containing digits are
+// masked first, then templates with the same number of tokens that mostly agree are
+// merged, with disagreeing tokens replaced by a wildcard.
+func clusterLogMessages(spans []uptraceapi.Span) []*logPattern {
+	const wildcard = "<*>"
+	const minSimilarity = 0.7
+
+	var patterns []*logPattern
+	for i := range spans {
+		line := newLogLine(&spans[i])
+
+		tokens := strings.Fields(line.Message)
+		for j, tok := range tokens {
+			if strings.ContainsAny(tok, "0123456789") {
+				tokens[j] = wildcard
+			}
+		}
+
+		var match *logPattern
+		for _, p := range patterns {
+			if p.Severity != line.Severity || len(p.tokens) != len(tokens) {
+				continue
+			}
+			var same int
+			for j := range tokens {
+				if tokens[j] == p.tokens[j] || p.tokens[j] == wildcard {
+					same++
+				}
+			}
+			if len(tokens) == 0 || float64(same)/float64(len(tokens)) >= minSimilarity {
+				match = p
+				break
+			}
+		}
+
+		if match == nil {
+			patterns = append(patterns, &logPattern{
+				tokens:   tokens,
+				Count:    1,
+				Severity: line.Severity,
+				Service:  line.Service,
+				TraceID:  line.TraceID,
+			})
+			continue
+		}
+
+		match.Count++
+		for j := range tokens {
+			if match.tokens[j] != tokens[j] {
+				match.tokens[j] = wildcard
+			}
+		}
+		if match.Service != line.Service {
+			match.Service = wildcard
+		}
+	}
+
+	for _, p := range patterns {
+		p.Pattern = strings.Join(p.tokens, " ")
+	}
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return patterns[i].Count > patterns[j].Count
+	})
+	return patterns
+}
+
+// attrString returns a string attribute by name, ignoring the type suffix.
+func attrString(attrs map[string]any, name string) string {
+	for _, key := range []string{name + "::str", name} {
+		if v, ok := attrs[key].(string); ok {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+// series is a single aggregated column of one group in a timeseries response.
+type series struct {
+	Group  map[string]any
+	Column string
+	Unit   string
+	Values []float64
+}
+
+// timeseriesSeries flattens a timeseries response into one series per group and
+// aggregated column. Missing values are represented as NaN.
+func timeseriesSeries(resp *uptraceapi.QueryTimeseriesResponse) []series {
+	units := make(map[string]string)
+	groupCols := make(map[string]bool)
+	for _, col := range resp.Columns {
+		if col.Unit != nil {
+			units[col.Name] = *col.Unit
+		}
+		if col.IsGroup != nil && *col.IsGroup {
+			groupCols[col.Name] = true
+		}
+	}
+
+	var out []series
+	for _, row := range resp.Groups {
+		labels := make(map[string]any)
+		var columns []string
+		for key, value := range row {
+			if strings.HasPrefix(key, "_") {
+				continue
+			}
+			if _, ok := value.([]any); ok && !groupCols[key] {
+				columns = append(columns, key)
+				continue
+			}
+			labels[key] = value
+		}
+		sort.Strings(columns)
+
+		for _, col := range columns {
+			raw := row[col].([]any)
+			values := make([]float64, len(raw))
+			for i, v := range raw {
+				values[i] = toFloat(v)
+			}
+			out = append(out, series{
+				Group:  labels,
+				Column: col,
+				Unit:   units[col],
+				Values: values,
+			})
+		}
+	}
+	return out
+}
+
+// toFloat converts a decoded JSON value to float64, returning NaN for nulls
+// and non-numeric values.
+func toFloat(v any) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	default:
+		return math.NaN()
+	}
+}
+
+// roundTo rounds v to the given number of decimal places.
+func roundTo(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
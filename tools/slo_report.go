@@ -0,0 +1,341 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+// sloBurnWindows are the multi-window burn rate alerting windows together with
+// the burn rate that should trigger an alert in each of them.
+var sloBurnWindows = []struct {
+	window    time.Duration
+	threshold float64
+}{
+	{time.Hour, 14.4},
+	{6 * time.Hour, 6},
+	{3 * 24 * time.Hour, 1},
+}
+
+type SLOReportTool struct {
+	client *uptraceapi.Client
+	conf   *appconf.Config
+	slos   []appconf.SLO
+}
+
+func NewSLOReportTool(client *uptraceapi.Client, conf *appconf.Config) (*SLOReportTool, error) {
+	t := &SLOReportTool{
+		client: client,
+		conf:   conf,
+	}
+	if conf.SLO.File != "" {
+		slos, err := appconf.LoadSLOs(conf.SLO.File)
+		if err != nil {
+			return nil, err
+		}
+		t.slos = slos
+	}
+	return t, nil
+}
+
+func (t *SLOReportTool) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "slo_report",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "SLO report",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+		Description: "Compute service level objective attainment, remaining error budget and " +
+			"multi-window burn rates (1h, 6h, 3d) from span counts. The SLI is good events / total events: " +
+			"total events match system and total_query, good events additionally match good_query " +
+			"and/or are faster than latency_threshold. Either reference an SLO from the configured " +
+			"SLO file by name or define one inline. Without arguments, reports every configured SLO.",
+	}, t.handler)
+}
+
+type sloReportInput struct {
+	ProjectID        int64     `json:"project_id,omitempty" jsonschema:"Uptrace project ID."`
+	Name             string    `json:"name,omitempty" jsonschema:"Name of a configured SLO, or a label for an inline definition."`
+	System           []string  `json:"system,omitempty" jsonschema:"Filter total events by system (e.g., httpserver:all)."`
+	TotalQuery       string    `json:"total_query,omitempty" jsonschema:"UQL filter selecting all events of the SLI (e.g., where service_name = \"checkout\")."`
+	GoodQuery        string    `json:"good_query,omitempty" jsonschema:"UQL filter selecting good events among total events (e.g., where _status_code != \"error\")."`
+	LatencyThreshold string    `json:"latency_threshold,omitempty" jsonschema:"Events faster than this duration are good (e.g., 500ms)."`
+	Objective        float64   `json:"objective,omitempty" jsonschema:"Target percentage of good events (e.g., 99.9)."`
+	Window           string    `json:"window,omitempty" jsonschema:"SLO window (e.g., 30d, 7d, 24h). Defaults to 30d."`
+	TimeEnd          time.Time `json:"time_end,omitempty" jsonschema:"End of the evaluated window as RFC3339 timestamp. Defaults to now."`
+}
+
+type sloReportOutput struct {
+	Reports []sloReport `json:"reports"`
+}
+
+type sloReport struct {
+	Name                 string        `json:"name"`
+	Objective            float64       `json:"objective" jsonschema:"Target percentage of good events."`
+	Window               string        `json:"window"`
+	TimeStart            time.Time     `json:"time_start"`
+	TimeEnd              time.Time     `json:"time_end"`
+	TotalEvents          float64       `json:"total_events"`
+	GoodEvents           float64       `json:"good_events"`
+	Attainment           float64       `json:"attainment" jsonschema:"Percentage of good events over the window."`
+	ErrorBudget          float64       `json:"error_budget" jsonschema:"Number of bad events allowed over the window."`
+	ErrorBudgetRemaining float64       `json:"error_budget_remaining" jsonschema:"Percentage of the error budget left; negative when exhausted."`
+	BurnRates            []sloBurnRate `json:"burn_rates"`
+	Status               string        `json:"status" jsonschema:"ok, burning or exhausted."`
+}
+
+type sloBurnRate struct {
+	Window      string  `json:"window"`
+	TotalEvents float64 `json:"total_events"`
+	GoodEvents  float64 `json:"good_events"`
+	BurnRate    float64 `json:"burn_rate" jsonschema:"Rate at which the error budget is consumed; 1 means exactly on budget."`
+	Threshold   float64 `json:"threshold" jsonschema:"Burn rate above which an alert is recommended."`
+	Alerting    bool    `json:"alerting"`
+}
+
+func (t *SLOReportTool) handler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input *sloReportInput,
+) (*mcp.CallToolResult, *sloReportOutput, error) {
+	projectID := input.ProjectID
+	if projectID == 0 {
+		projectID = t.conf.Uptrace.ProjectID
+	}
+	if input.TimeEnd.IsZero() {
+		input.TimeEnd = time.Now()
+	}
+
+	slos, err := t.resolveSLOs(input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	steps := 0
+	for _, slo := range slos {
+		steps++
+		for _, bw := range sloBurnWindows {
+			if bw.window <= slo.Window {
+				steps++
+			}
+		}
+	}
+	prog := newProgress(req, steps)
+
+	out := &sloReportOutput{Reports: make([]sloReport, 0, len(slos))}
+	for _, slo := range slos {
+		report, err := t.report(ctx, prog, projectID, slo, input.TimeEnd)
+		if err != nil {
+			return nil, nil, fmt.Errorf("SLO %q: %w", slo.Name, err)
+		}
+		out.Reports = append(out.Reports, *report)
+	}
+	return nil, out, nil
+}
+
+func (t *SLOReportTool) resolveSLOs(input *sloReportInput) ([]appconf.SLO, error) {
+	inline := input.TotalQuery != "" || input.GoodQuery != "" || input.LatencyThreshold != ""
+
+	if !inline {
+		if input.Name == "" {
+			if len(t.slos) == 0 {
+				return nil, fmt.Errorf("no SLOs configured: define the SLI inline or set slo.file in the config")
+			}
+			return t.slos, nil
+		}
+		for _, slo := range t.slos {
+			if slo.Name == input.Name {
+				return []appconf.SLO{slo}, nil
+			}
+		}
+		names := make([]string, len(t.slos))
+		for i, slo := range t.slos {
+			names[i] = slo.Name
+		}
+		return nil, fmt.Errorf("SLO %q not found (configured: %s)", input.Name, strings.Join(names, ", "))
+	}
+
+	slo := appconf.SLO{
+		Name:       input.Name,
+		System:     input.System,
+		TotalQuery: input.TotalQuery,
+		GoodQuery:  input.GoodQuery,
+		Objective:  input.Objective,
+	}
+	if slo.Name == "" {
+		slo.Name = "inline"
+	}
+	if input.LatencyThreshold != "" {
+		d, err := parseDuration(input.LatencyThreshold)
+		if err != nil {
+			return nil, err
+		}
+		slo.LatencyThreshold = d
+	}
+	if input.Window != "" {
+		d, err := parseDuration(input.Window)
+		if err != nil {
+			return nil, err
+		}
+		slo.Window = d
+	}
+	slo.SetDefaults()
+	if err := slo.Validate(); err != nil {
+		return nil, err
+	}
+	return []appconf.SLO{slo}, nil
+}
+
+func (t *SLOReportTool) report(
+	ctx context.Context, prog *progress, projectID int64, slo appconf.SLO, timeEnd time.Time,
+) (*sloReport, error) {
+	allowedBad := 1 - slo.Objective/100
+
+	total, good, err := t.countEvents(ctx, projectID, slo, timeEnd.Add(-slo.Window), timeEnd)
+	if err != nil {
+		return nil, err
+	}
+	prog.step(ctx, fmt.Sprintf("%s: %s window", slo.Name, formatDuration(slo.Window)))
+
+	report := &sloReport{
+		Name:        slo.Name,
+		Objective:   slo.Objective,
+		Window:      formatDuration(slo.Window),
+		TimeStart:   timeEnd.Add(-slo.Window),
+		TimeEnd:     timeEnd,
+		TotalEvents: total,
+		GoodEvents:  good,
+		Attainment:  100,
+		ErrorBudget: math.Round(total * allowedBad),
+		BurnRates:   make([]sloBurnRate, 0, len(sloBurnWindows)),
+	}
+	report.ErrorBudgetRemaining = 100
+	if total > 0 {
+		report.Attainment = good / total * 100
+		report.ErrorBudgetRemaining = (1 - (total-good)/(total*allowedBad)) * 100
+	}
+
+	report.Status = "ok"
+	for _, bw := range sloBurnWindows {
+		if bw.window > slo.Window {
+			continue
+		}
+
+		total, good, err := t.countEvents(ctx, projectID, slo, timeEnd.Add(-bw.window), timeEnd)
+		if err != nil {
+			return nil, err
+		}
+		prog.step(ctx, fmt.Sprintf("%s: %s burn rate", slo.Name, formatDuration(bw.window)))
+
+		burn := sloBurnRate{
+			Window:      formatDuration(bw.window),
+			TotalEvents: total,
+			GoodEvents:  good,
+			Threshold:   bw.threshold,
+		}
+		if total > 0 {
+			burn.BurnRate = (total - good) / total / allowedBad
+		}
+		burn.Alerting = burn.BurnRate > bw.threshold
+		if burn.Alerting {
+			report.Status = "burning"
+		}
+		report.BurnRates = append(report.BurnRates, burn)
+	}
+	if report.ErrorBudgetRemaining <= 0 {
+		report.Status = "exhausted"
+	}
+
+	return report, nil
+}
+
+// countEvents returns the number of total and good events between start and end.
+func (t *SLOReportTool) countEvents(
+	ctx context.Context, projectID int64, slo appconf.SLO, start, end time.Time,
+) (total, good float64, _ error) {
+	total, err := t.countSpans(ctx, projectID, slo, start, end, false)
+	if err != nil {
+		return 0, 0, err
+	}
+	if total == 0 {
+		return 0, 0, nil
+	}
+	good, err = t.countSpans(ctx, projectID, slo, start, end, true)
+	if err != nil {
+		return 0, 0, err
+	}
+	return total, good, nil
+}
+
+func (t *SLOReportTool) countSpans(
+	ctx context.Context, projectID int64, slo appconf.SLO, start, end time.Time, good bool,
+) (float64, error) {
+	parts := make([]string, 0, 3)
+	if slo.TotalQuery != "" {
+		parts = append(parts, slo.TotalQuery)
+	}
+	if good && slo.GoodQuery != "" {
+		parts = append(parts, slo.GoodQuery)
+	}
+	parts = append(parts, "count()")
+	query := strings.Join(parts, " | ")
+
+	q := &uptraceapi.ListSpanGroupsQuery{
+		TimeStart: start,
+		TimeEnd:   end,
+		Query:     &query,
+		System:    slo.System,
+	}
+	if good && slo.LatencyThreshold > 0 {
+		lt := slo.LatencyThreshold.Milliseconds()
+		q.DurationLt = &lt
+	}
+
+	resp, err := t.client.ListSpanGroups(ctx, &uptraceapi.ListSpanGroupsRequestOptions{
+		PathParams: &uptraceapi.ListSpanGroupsPath{ProjectID: projectID},
+		Query:      q,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return sumAggColumn(resp), nil
+}
+
+// sumAggColumn sums the first aggregated column over all groups.
+func sumAggColumn(resp *uptraceapi.GroupsResult) float64 {
+	var column string
+	for _, col := range resp.Columns {
+		if col.IsAgg != nil && *col.IsAgg {
+			column = col.Name
+			break
+		}
+	}
+
+	var sum float64
+	for _, row := range resp.Groups {
+		if column != "" {
+			if v := toFloat(row[column]); !math.IsNaN(v) {
+				sum += v
+			}
+			continue
+		}
+		for key, value := range row {
+			if strings.HasPrefix(key, "_") {
+				continue
+			}
+			if v := toFloat(value); !math.IsNaN(v) {
+				sum += v
+				break
+			}
+		}
+	}
+	return sum
+}
@@ -0,0 +1,247 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uptrace/mcp/uptraceapi"
+)
+
+const (
+	// spanSampleBucket is the granularity of sampled time ranges. Ranges are widened
+	// to whole buckets so that relative ranges such as "last 1h" hit the cache until
+	// the next bucket starts.
+	spanSampleBucket = 5 * time.Minute
+	// spanSampleCacheSize is the maximum number of cached samples.
+	spanSampleCacheSize = 64
+	// maxSampledValues caps the number of distinct values tracked per attribute.
+	maxSampledValues = 1000
+)
+
+// spanAttrSampler infers span and log attributes from a sample of spans. It is
+// shared by list_span_attributes and list_span_attribute_values.
+type spanAttrSampler struct {
+	client *uptraceapi.Client
+
+	mu    sync.Mutex
+	cache map[spanSampleKey]*spanSample
+}
+
+func newSpanAttrSampler(client *uptraceapi.Client) *spanAttrSampler {
+	return &spanAttrSampler{
+		client: client,
+		cache:  make(map[spanSampleKey]*spanSample),
+	}
+}
+
+// spanSampleFilter selects the spans to sample.
+type spanSampleFilter struct {
+	ProjectID int64
+	TimeStart time.Time
+	TimeEnd   time.Time
+	System    []string
+	Query     string
+	Limit     int
+}
+
+type spanSampleKey struct {
+	projectID  int64
+	start, end time.Time
+	system     string
+	query      string
+	limit      int
+}
+
+type spanSample struct {
+	TimeStart time.Time
+	TimeEnd   time.Time
+	// Spans is the number of sampled spans and Total the number of matching spans.
+	Spans int
+	Total float64
+	Attrs map[string]*sampledAttr
+
+	expires time.Time
+}
+
+type sampledAttr struct {
+	Name   string
+	Type   string
+	Count  int
+	Values map[string]int
+	// Capped is set when the attribute has more than maxSampledValues distinct values.
+	Capped bool
+}
+
+// coverage is the percentage of sampled spans that have the attribute.
+func (s *spanSample) coverage(attr *sampledAttr) float64 {
+	if s.Spans == 0 {
+		return 0
+	}
+	return roundTo(float64(attr.Count)/float64(s.Spans)*100, 1)
+}
+
+func (a *sampledAttr) cardinality() string {
+	if a.Capped {
+		return fmt.Sprintf("%d+", len(a.Values))
+	}
+	return fmt.Sprint(len(a.Values))
+}
+
+type valueCount struct {
+	Value string
+	Count int
+}
+
+func (a *sampledAttr) topValues(n int) []valueCount {
+	values := make([]valueCount, 0, len(a.Values))
+	for value, count := range a.Values {
+		values = append(values, valueCount{value, count})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+	n = max(n, 0)
+	if len(values) > n {
+		values = values[:n]
+	}
+	return values
+}
+
+// sample returns the attributes of up to filter.Limit spans, using the cache when
+// the same filter was sampled within the current time bucket.
+func (s *spanAttrSampler) sample(ctx context.Context, filter *spanSampleFilter) (*spanSample, error) {
+	start, end := bucketTimeRange(filter.TimeStart, filter.TimeEnd)
+	key := spanSampleKey{
+		projectID: filter.ProjectID,
+		start:     start,
+		end:       end,
+		system:    strings.Join(filter.System, ","),
+		query:     filter.Query,
+		limit:     filter.Limit,
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached, nil
+	}
+
+	query := &uptraceapi.ListSpansQuery{
+		TimeStart: start,
+		TimeEnd:   end,
+		System:    filter.System,
+	}
+	if filter.Query != "" {
+		query.Query = &filter.Query
+	}
+	limit := uptraceapi.Limit(filter.Limit)
+	query.Limit = &limit
+
+	resp, err := s.client.ListSpans(ctx, &uptraceapi.ListSpansRequestOptions{
+		PathParams: &uptraceapi.ListSpansPath{ProjectID: filter.ProjectID},
+		Query:      query,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sample := &spanSample{
+		TimeStart: start,
+		TimeEnd:   end,
+		Spans:     len(resp.Spans),
+		Total:     float64(resp.Count),
+		Attrs:     sampleSpanAttrs(resp.Spans),
+		expires:   now.Add(spanSampleBucket),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.cache) >= spanSampleCacheSize {
+		for k, v := range s.cache {
+			if now.After(v.expires) {
+				delete(s.cache, k)
+			}
+		}
+		// Still full: drop arbitrary entries.
+		for k := range s.cache {
+			if len(s.cache) < spanSampleCacheSize {
+				break
+			}
+			delete(s.cache, k)
+		}
+	}
+	s.cache[key] = sample
+	return sample, nil
+}
+
+// bucketTimeRange widens the range to whole buckets.
+func bucketTimeRange(start, end time.Time) (time.Time, time.Time) {
+	start = start.Truncate(spanSampleBucket)
+	if t := end.Truncate(spanSampleBucket); !t.Equal(end) {
+		end = t.Add(spanSampleBucket)
+	}
+	return start.UTC(), end.UTC()
+}
+
+func sampleSpanAttrs(spans []uptraceapi.Span) map[string]*sampledAttr {
+	attrs := make(map[string]*sampledAttr)
+	for _, span := range spans {
+		for key, value := range span.Attrs {
+			name, typ, _ := strings.Cut(key, "::")
+			attr := attrs[name]
+			if attr == nil {
+				if typ == "" {
+					typ = valueType(value)
+				}
+				attr = &sampledAttr{Name: name, Type: typ, Values: make(map[string]int)}
+				attrs[name] = attr
+			}
+			attr.Count++
+
+			str := fmt.Sprint(value)
+			if _, ok := attr.Values[str]; ok || len(attr.Values) < maxSampledValues {
+				attr.Values[str]++
+			} else {
+				attr.Capped = true
+			}
+		}
+	}
+	return attrs
+}
+
+// valueType infers the UQL type of an attribute value decoded from JSON.
+func valueType(value any) string {
+	switch v := value.(type) {
+	case string:
+		return "str"
+	case bool:
+		return "bool"
+	case float64:
+		if v == math.Trunc(v) {
+			return "int"
+		}
+		return "float"
+	case []any:
+		if len(v) > 0 {
+			return valueType(v[0]) + "[]"
+		}
+		return "str[]"
+	default:
+		return "str"
+	}
+}
+
+// normalizeAttrName makes http.route and http_route compare equal.
+func normalizeAttrName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(attrName(name), ".", "_"))
+}
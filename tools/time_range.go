@@ -0,0 +1,297 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // resolve IANA time zones such as Europe/Berlin on any host
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/uptrace/mcp/appconf"
+)
+
+const timeRangeHelp = "supported forms: last 15m, -2h..-1h, -30m..now, today, " +
+	"yesterday 14:00-15:00 Europe/Berlin, 2026-01-02 09:00-10:00 UTC, around 2026-01-02T15:04:05Z ±10m"
+
+// timeRangeOptions are embedded into the input of query tools so that a time range
+// can be given relative to the server clock instead of as absolute timestamps.
+type timeRangeOptions struct {
+	TimeRange string `json:"time_range,omitempty" jsonschema:"Time range resolved against the server clock; takes precedence over time_start and time_end. Examples: last 15m, last 2 hours, -2h..-1h, today, yesterday 14:00-15:00 Europe/Berlin, around 2026-01-02T15:04:05Z ±10m."`
+}
+
+// resolveTimeRange sets start and end from the time range or, when it is empty,
+// fills in the defaults for missing times.
+func (o *timeRangeOptions) resolveTimeRange(conf *appconf.Config, start, end *time.Time) error {
+	now := time.Now()
+	if o.TimeRange != "" {
+		s, e, err := parseTimeRange(o.TimeRange, now)
+		if err != nil {
+			return err
+		}
+		*start, *end = s, e
+		return nil
+	}
+
+	if end.IsZero() {
+		*end = now
+	}
+	if start.IsZero() {
+		*start = end.Add(-conf.Default.TimeDuration)
+	}
+	return nil
+}
+
+// timeRangeMeta echoes the resolved range in formatted results.
+func timeRangeMeta(start, end time.Time) []metaField {
+	return []metaField{
+		{"time_start", start.UTC().Format(time.RFC3339)},
+		{"time_end", end.UTC().Format(time.RFC3339)},
+	}
+}
+
+// timeRangeInputSchema infers the input schema of T and makes time_start and
+// time_end of the nested Query optional, since time_range can be used instead.
+func timeRangeInputSchema[T any]() *jsonschema.Schema {
+	schema, err := jsonschema.For[T](nil)
+	if err != nil {
+		panic(fmt.Errorf("input schema: %w", err))
+	}
+	if q := schema.Properties["Query"]; q != nil {
+		q.Required = slices.DeleteFunc(q.Required, func(name string) bool {
+			return name == "time_start" || name == "time_end"
+		})
+	}
+	return schema
+}
+
+// parseTimeRange resolves a relative or natural-language time range against now.
+func parseTimeRange(s string, now time.Time) (time.Time, time.Time, error) {
+	s = strings.TrimSpace(s)
+	start, end, err := parseTimeRangeForms(s, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid time_range %q: %w (%s)", s, err, timeRangeHelp)
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf(
+			"invalid time_range %q: start %s is not before end %s",
+			s, start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+	return start, end, nil
+}
+
+func parseTimeRangeForms(s string, now time.Time) (time.Time, time.Time, error) {
+	if from, to, ok := strings.Cut(s, ".."); ok {
+		start, err := parseTimePoint(from, now)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end, err := parseTimePoint(to, now)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		return start, end, nil
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("empty time range")
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "last", "past":
+		d, err := parseHumanDuration(strings.Join(fields[1:], ""))
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		return now.Add(-d), now, nil
+	case "around":
+		return parseAround(fields[1:])
+	case "today", "yesterday":
+		return parseDay(fields, now)
+	}
+
+	if _, err := time.Parse(time.DateOnly, fields[0]); err == nil {
+		return parseDay(fields, now)
+	}
+	if d, err := parseHumanDuration(strings.Join(fields, "")); err == nil {
+		return now.Add(-d), now, nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("unrecognized time range")
+}
+
+// parseTimePoint parses one side of a from..to range: now, a relative offset such
+// as -2h, or an absolute RFC3339 timestamp.
+func parseTimePoint(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "" || strings.EqualFold(s, "now"):
+		return now, nil
+	case strings.HasPrefix(s, "-"), strings.HasPrefix(s, "+"):
+		d, err := parseHumanDuration(s[1:])
+		if err != nil {
+			return time.Time{}, err
+		}
+		if s[0] == '-' {
+			d = -d
+		}
+		return now.Add(d), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", time.DateTime, time.DateOnly} {
+		if tm, err := time.Parse(layout, s); err == nil {
+			return tm, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("can't parse time %q", s)
+}
+
+// parseAround parses "<RFC3339> ±10m". The window defaults to ±15m.
+func parseAround(fields []string) (time.Time, time.Time, error) {
+	if len(fields) == 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("around requires a timestamp")
+	}
+	center, err := time.Parse(time.RFC3339, fields[0])
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("can't parse timestamp %q", fields[0])
+	}
+
+	window := 15 * time.Minute
+	if rest := strings.Join(fields[1:], ""); rest != "" {
+		for _, prefix := range []string{"±", "+/-", "+-"} {
+			rest = strings.TrimPrefix(rest, prefix)
+		}
+		window, err = parseHumanDuration(rest)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	return center.Add(-window), center.Add(window), nil
+}
+
+// parseDay parses "today", "yesterday" or a date, optionally followed by a
+// HH:MM-HH:MM window and an IANA time zone (UTC by default).
+func parseDay(fields []string, now time.Time) (time.Time, time.Time, error) {
+	loc := time.UTC
+	if len(fields) > 1 {
+		last := fields[len(fields)-1]
+		if !isClockWindow(last) {
+			l, err := time.LoadLocation(last)
+			if err != nil {
+				return time.Time{}, time.Time{}, fmt.Errorf("unknown time zone %q", last)
+			}
+			loc = l
+			fields = fields[:len(fields)-1]
+		}
+	}
+
+	nowIn := now.In(loc)
+	today := time.Date(nowIn.Year(), nowIn.Month(), nowIn.Day(), 0, 0, 0, 0, loc)
+
+	var day time.Time
+	switch strings.ToLower(fields[0]) {
+	case "today":
+		day = today
+	case "yesterday":
+		day = today.AddDate(0, 0, -1)
+	default:
+		d, err := time.ParseInLocation(time.DateOnly, fields[0], loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("can't parse date %q", fields[0])
+		}
+		day = d
+	}
+
+	switch len(fields) {
+	case 1:
+		end := day.AddDate(0, 0, 1)
+		if end.After(now) {
+			end = now
+		}
+		return day, end, nil
+	case 2:
+		from, to, ok := strings.Cut(fields[1], "-")
+		if !ok {
+			return time.Time{}, time.Time{}, fmt.Errorf("expected a HH:MM-HH:MM window, got %q", fields[1])
+		}
+		start, err := clockTime(day, from)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end, err := clockTime(day, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if !end.After(start) {
+			// The window crosses midnight, e.g. 23:00-01:00.
+			end = end.AddDate(0, 0, 1)
+		}
+		return start, end, nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unexpected %q", strings.Join(fields[2:], " "))
+	}
+}
+
+// isClockWindow reports whether s is a window such as 14-15 or 09:30-10:00.
+func isClockWindow(s string) bool {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return false
+	}
+	_, err1 := clockTime(time.Time{}, from)
+	_, err2 := clockTime(time.Time{}, to)
+	return err1 == nil && err2 == nil
+}
+
+func clockTime(day time.Time, s string) (time.Time, error) {
+	hh, mm, ok := strings.Cut(s, ":")
+	if !ok {
+		mm = "0"
+	}
+	h, err1 := strconv.Atoi(hh)
+	m, err2 := strconv.Atoi(mm)
+	if err1 != nil || err2 != nil || h < 0 || h > 24 || m < 0 || m > 59 {
+		return time.Time{}, fmt.Errorf("can't parse clock time %q", s)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, day.Location()), nil
+}
+
+var durationUnits = []struct {
+	names []string
+	unit  string
+}{
+	{[]string{"seconds", "second", "secs", "sec"}, "s"},
+	{[]string{"minutes", "minute", "mins", "min"}, "m"},
+	{[]string{"hours", "hour", "hrs", "hr"}, "h"},
+	{[]string{"days", "day"}, "d"},
+	{[]string{"weeks", "week"}, "w"},
+}
+
+// parseHumanDuration parses durations such as 15m, 2hours, 3d or hour.
+// Spaces must already be removed.
+func parseHumanDuration(s string) (time.Duration, error) {
+	s = strings.ToLower(s)
+	if s == "" {
+		return 0, fmt.Errorf("missing duration")
+	}
+	for _, u := range durationUnits {
+		for _, name := range u.names {
+			if strings.HasSuffix(s, name) {
+				s = strings.TrimSuffix(s, name) + u.unit
+				break
+			}
+		}
+	}
+	if s[0] < '0' || s[0] > '9' {
+		s = "1" + s
+	}
+	d, err := parseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be positive")
+	}
+	return d, nil
+}
@@ -2,7 +2,6 @@ package tools
 
 import (
 	"context"
-	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/uptrace/mcp/appconf"
@@ -30,15 +29,23 @@ func (t *QueryTimeseriesTool) Register(server *mcp.Server) {
 			IdempotentHint: true,
 			OpenWorldHint:  boolPtr(true),
 		},
-		Description: uptraceapi.Operations["query_timeseries"].Description,
+		InputSchema:  timeRangeInputSchema[queryTimeseriesInput](),
+		OutputSchema: tableResultSchema(),
+		Description:  uptraceapi.Operations["query_timeseries"].Description,
 	}, t.handler)
 }
 
+type queryTimeseriesInput struct {
+	uptraceapi.QueryTimeseriesRequestOptions
+	timeRangeOptions
+	formatOptions
+}
+
 func (t *QueryTimeseriesTool) handler(
 	ctx context.Context,
 	req *mcp.CallToolRequest,
-	input *uptraceapi.QueryTimeseriesRequestOptions,
-) (*mcp.CallToolResult, *uptraceapi.QueryTimeseriesResponse, error) {
+	input *queryTimeseriesInput,
+) (*mcp.CallToolResult, any, error) {
 	if input.PathParams.ProjectID == 0 {
 		input.PathParams.ProjectID = t.conf.Uptrace.ProjectID
 	}
@@ -46,11 +53,10 @@ func (t *QueryTimeseriesTool) handler(
 	if input.Query == nil {
 		input.Query = &uptraceapi.QueryTimeseriesQuery{}
 	}
-	if input.Query.TimeStart.IsZero() {
-		input.Query.TimeStart = time.Now().Add(-t.conf.Default.TimeDuration)
-	}
-	if input.Query.TimeEnd.IsZero() {
-		input.Query.TimeEnd = time.Now()
+	if err := input.resolveTimeRange(
+		t.conf, &input.Query.TimeStart, &input.Query.TimeEnd,
+	); err != nil {
+		return nil, nil, err
 	}
 	if input.Query.Limit == nil {
 		defaultLimit := uptraceapi.Limit(t.conf.Default.Limit)
@@ -60,10 +66,12 @@ func (t *QueryTimeseriesTool) handler(
 		input.Query.Query = &t.conf.Default.Query
 	}
 
-	resp, err := t.client.QueryTimeseries(ctx, input)
+	resp, err := t.client.QueryTimeseries(ctx, &input.QueryTimeseriesRequestOptions)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return nil, resp, nil
+	tbl := timeseriesTable(resp)
+	tbl.Meta = append(timeRangeMeta(input.Query.TimeStart, input.Query.TimeEnd), tbl.Meta...)
+	return formatResult(t.conf, &input.formatOptions, tbl)
 }
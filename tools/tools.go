@@ -13,6 +13,7 @@ type RegisterParams struct {
 
 var Module = fx.Module("tools",
 	fx.Provide(
+		newSpanAttrSampler,
 		fx.Annotate(NewPublicListSpansTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
 		fx.Annotate(NewListSpansTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
 		fx.Annotate(NewPublicListSpanGroupsTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
@@ -34,6 +35,19 @@ var Module = fx.Module("tools",
 		fx.Annotate(NewGetDashboardYamlTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
 		fx.Annotate(NewUpdateDashboardYamlTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
 		fx.Annotate(NewDeleteDashboardTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
+		fx.Annotate(NewDetectAnomaliesTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
+		fx.Annotate(NewSLOReportTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
+		fx.Annotate(NewCorrelateAttributesTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
+		fx.Annotate(NewSearchLogsTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
+		fx.Annotate(NewRenderChartTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
+		fx.Annotate(NewValidateQueryTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
+		fx.Annotate(NewListSpanAttributesTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
+		fx.Annotate(NewListSpanAttributeValuesTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
+		fx.Annotate(NewMetricCardinalityReportTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
+		fx.Annotate(NewGenerateDashboardTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
+		fx.Annotate(NewLintDashboardYamlTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
+		fx.Annotate(NewPlanMonitorsTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
+		fx.Annotate(NewBacktestMonitorTool, fx.As(new(Tool)), fx.ResultTags(`group:"tools"`)),
 	),
 	fx.Invoke(Register),
 )
@@ -0,0 +1,839 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+
+	"github.com/uptrace/mcp/testutil"
+)
+
+const (
+	testLimit        = 25
+	testTimeDuration = 2 * time.Hour
+)
+
+// newTestSession registers every tool of Module with a server backed by a fake
+// Uptrace and connects a client to it.
+func newTestSession(t *testing.T) (*testutil.Server, *mcp.ClientSession) {
+	t.Helper()
+
+	srv := testutil.NewServer(t)
+	conf := srv.Config()
+	conf.Default.Limit = testLimit
+	conf.Default.TimeDuration = testTimeDuration
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(conf, srv.Client(), server),
+		Module,
+	)
+	app.RequireStart()
+	t.Cleanup(app.RequireStop)
+
+	return srv, testutil.Connect(t, server)
+}
+
+// requestWant is what a tool is expected to send to Uptrace.
+type requestWant struct {
+	// projectID defaults to testutil.ProjectID.
+	projectID string
+	// timeRange is the expected time_end - time_start; zero skips the check.
+	timeRange time.Duration
+	// recent requires time_end to be now, as when the time range is defaulted.
+	recent bool
+	// limit is the expected limit; empty skips the check.
+	limit  string
+	params map[string]string
+	query  map[string]string
+}
+
+func (w *requestWant) check(t *testing.T, req *testutil.Request) {
+	t.Helper()
+
+	projectID := w.projectID
+	if projectID == "" {
+		projectID = "1"
+	}
+	if got := req.PathParams["project_id"]; got != projectID {
+		t.Errorf("project_id = %q, want %q", got, projectID)
+	}
+	for name, want := range w.params {
+		if got := req.PathParams[name]; got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	if w.timeRange != 0 {
+		start := parseQueryTime(t, req, "time_start")
+		end := parseQueryTime(t, req, "time_end")
+		if got := end.Sub(start); got != w.timeRange {
+			t.Errorf("time range = %s, want %s", got, w.timeRange)
+		}
+		if w.recent && time.Since(end).Abs() > time.Minute {
+			t.Errorf("time_end = %s, want now", end)
+		}
+	}
+
+	if w.limit != "" {
+		if got := req.Query.Get("limit"); got != w.limit {
+			t.Errorf("limit = %q, want %q", got, w.limit)
+		}
+	}
+	for name, want := range w.query {
+		if got := req.Query.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func parseQueryTime(t *testing.T, req *testutil.Request, name string) time.Time {
+	t.Helper()
+
+	tm, err := time.Parse(time.RFC3339Nano, req.Query.Get(name))
+	if err != nil {
+		t.Fatalf("%s: %s", name, err)
+	}
+	return tm
+}
+
+func TestParseTimeRange(t *testing.T) {
+	now := time.Date(2026, 3, 10, 16, 30, 0, 0, time.UTC)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	tests := []struct {
+		in         string
+		start, end time.Time
+	}{
+		{"today", time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), now},
+		{"today 14-15", time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC), time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)},
+		{"today 14:30-15:00", time.Date(2026, 3, 10, 14, 30, 0, 0, time.UTC), time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)},
+		{"yesterday 23-01", time.Date(2026, 3, 9, 23, 0, 0, 0, time.UTC), time.Date(2026, 3, 10, 1, 0, 0, 0, time.UTC)},
+		{"today 14-15 Europe/Berlin", time.Date(2026, 3, 10, 14, 0, 0, 0, berlin), time.Date(2026, 3, 10, 15, 0, 0, 0, berlin)},
+		{"2026-03-01 Europe/Berlin", time.Date(2026, 3, 1, 0, 0, 0, 0, berlin), time.Date(2026, 3, 2, 0, 0, 0, 0, berlin)},
+	}
+	for _, tt := range tests {
+		start, end, err := parseTimeRange(tt.in, now)
+		if err != nil {
+			t.Errorf("%q: %s", tt.in, err)
+			continue
+		}
+		if !start.Equal(tt.start) || !end.Equal(tt.end) {
+			t.Errorf("%q = %s - %s, want %s - %s", tt.in, start, end, tt.start, tt.end)
+		}
+	}
+
+	if _, _, err := parseTimeRange("today Mars/Olympus", now); err == nil ||
+		!strings.Contains(err.Error(), "unknown time zone") {
+		t.Errorf("unknown time zone: got %v", err)
+	}
+}
+
+func TestToolDefaults(t *testing.T) {
+	// Aligned to the buckets of spanAttrSampler, which widens other ranges.
+	end := time.Now().Add(-24 * time.Hour).UTC().Truncate(spanSampleBucket)
+	start := end.Add(-30 * time.Minute)
+	timeQuery := map[string]any{
+		"time_start": start.Format(time.RFC3339),
+		"time_end":   end.Format(time.RFC3339),
+	}
+	// The schema requires PathParams.project_id; 0 selects the configured one.
+	defaultProject := map[string]any{"project_id": 0}
+	const dashboardYAML = `schema: v2
+name: Checkout
+grid_rows:
+  - title: Traffic
+    items:
+      - title: Requests per minute
+        metrics:
+          - uptrace_tracing_spans as $spans
+        query:
+          - per_min(count($spans)) as requests
+`
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		// op is the operation whose first request is checked.
+		op   string
+		want requestWant
+	}{
+		{
+			name: "list_spans defaults",
+			tool: "list_spans",
+			args: map[string]any{"PathParams": defaultProject, "Query": map[string]any{}},
+			op:   "list_spans",
+			want: requestWant{timeRange: testTimeDuration, recent: true, limit: "25"},
+		},
+		{
+			name: "list_spans explicit",
+			tool: "list_spans",
+			args: map[string]any{
+				"PathParams": map[string]any{"project_id": 7},
+				"Query":      map[string]any{"limit": 5},
+				"time_range": "last 15m",
+			},
+			op:   "list_spans",
+			want: requestWant{projectID: "7", timeRange: 15 * time.Minute, recent: true, limit: "5"},
+		},
+		{
+			name: "list_spans time_start only",
+			tool: "list_spans",
+			args: map[string]any{
+				"PathParams": defaultProject,
+				"Query":      map[string]any{"time_start": start.Format(time.RFC3339)},
+			},
+			op:   "list_spans",
+			want: requestWant{limit: "25", query: map[string]string{"time_start": start.Format(time.RFC3339)}},
+		},
+		{
+			name: "public_list_spans",
+			tool: "public_list_spans",
+			args: map[string]any{"PathParams": defaultProject, "Query": map[string]any{}},
+			op:   "public_list_spans",
+			want: requestWant{timeRange: testTimeDuration, recent: true, limit: "25"},
+		},
+		{
+			name: "list_span_groups",
+			tool: "list_span_groups",
+			args: map[string]any{"PathParams": defaultProject, "Query": map[string]any{}},
+			op:   "list_span_groups",
+			want: requestWant{timeRange: testTimeDuration, recent: true, limit: "25"},
+		},
+		{
+			name: "public_list_span_groups",
+			tool: "public_list_span_groups",
+			args: map[string]any{"PathParams": defaultProject, "Query": map[string]any{}},
+			op:   "public_list_span_groups",
+			want: requestWant{timeRange: testTimeDuration, recent: true, limit: "25"},
+		},
+		{
+			name: "list_traces",
+			tool: "list_traces",
+			args: map[string]any{"PathParams": defaultProject, "Query": map[string]any{}},
+			op:   "list_traces",
+			want: requestWant{timeRange: testTimeDuration, recent: true, limit: "25"},
+		},
+		{
+			name: "list_trace_groups",
+			tool: "list_trace_groups",
+			args: map[string]any{"PathParams": defaultProject, "Query": map[string]any{}, "time_range": "last 30m"},
+			op:   "list_trace_groups",
+			want: requestWant{timeRange: 30 * time.Minute, recent: true, limit: "25"},
+		},
+		{
+			name: "timeseries",
+			tool: "timeseries",
+			args: map[string]any{"PathParams": defaultProject, "Query": map[string]any{}},
+			op:   "query_timeseries",
+			want: requestWant{timeRange: testTimeDuration, recent: true, limit: "25"},
+		},
+		{
+			name: "quantiles",
+			tool: "quantiles",
+			args: map[string]any{"PathParams": map[string]any{"project_id": 7}, "Query": map[string]any{}},
+			op:   "query_quantiles",
+			want: requestWant{projectID: "7", timeRange: testTimeDuration, recent: true},
+		},
+		{
+			name: "explore_metrics",
+			tool: "explore_metrics",
+			args: map[string]any{"PathParams": defaultProject, "Query": timeQuery},
+			op:   "explore_metrics",
+			want: requestWant{timeRange: 30 * time.Minute},
+		},
+		{
+			name: "list_metric_attributes",
+			tool: "list_metric_attributes",
+			args: map[string]any{"PathParams": defaultProject, "Query": timeQuery},
+			op:   "list_metric_attributes",
+			want: requestWant{timeRange: 30 * time.Minute},
+		},
+		{
+			name: "list_metric_attribute_values",
+			tool: "list_metric_attribute_values",
+			args: map[string]any{
+				"PathParams": map[string]any{"project_id": 0, "attr_key": "service_name"},
+				"Query":      timeQuery,
+			},
+			op:   "list_metric_attribute_values",
+			want: requestWant{timeRange: 30 * time.Minute, params: map[string]string{"attr_key": "service_name"}},
+		},
+		{
+			name: "list_monitors",
+			tool: "list_monitors",
+			args: map[string]any{"PathParams": defaultProject},
+			op:   "list_monitors",
+		},
+		{
+			name: "list_dashboards",
+			tool: "list_dashboards",
+			args: map[string]any{"PathParams": defaultProject},
+			op:   "list_dashboards",
+		},
+		{
+			name: "list_dashboard_tags",
+			tool: "list_dashboard_tags",
+			args: map[string]any{"PathParams": map[string]any{"project_id": 7}},
+			op:   "list_dashboard_tags",
+			want: requestWant{projectID: "7"},
+		},
+		{
+			name: "list_dashboard_templates",
+			tool: "list_dashboard_templates",
+			args: map[string]any{"PathParams": defaultProject},
+			op:   "list_dashboard_templates",
+		},
+		{
+			name: "get_dashboard_template",
+			tool: "get_dashboard_template",
+			args: map[string]any{"PathParams": map[string]any{"project_id": 0, "template_id": "uptrace.postgresql"}},
+			op:   "get_dashboard_template",
+			want: requestWant{params: map[string]string{"template_id": "uptrace.postgresql"}},
+		},
+		{
+			name: "get_dashboard",
+			tool: "get_dashboard",
+			args: map[string]any{"PathParams": map[string]any{"project_id": 0, "dashboard_id": 31}},
+			op:   "get_dashboard",
+			want: requestWant{params: map[string]string{"dashboard_id": "31"}},
+		},
+		{
+			name: "get_dashboard_yaml",
+			tool: "get_dashboard_yaml",
+			args: map[string]any{"dashboard_id": 31},
+			op:   "get_dashboard_yaml",
+			want: requestWant{params: map[string]string{"dashboard_id": "31"}},
+		},
+		{
+			name: "create_dashboard",
+			tool: "create_dashboard",
+			args: map[string]any{"body": dashboardYAML},
+			op:   "create_dashboard_from_yaml",
+		},
+		{
+			name: "update_dashboard_yaml",
+			tool: "update_dashboard_yaml",
+			args: map[string]any{"project_id": 7, "dashboard_id": 31, "body": dashboardYAML},
+			op:   "update_dashboard_from_yaml",
+			want: requestWant{projectID: "7", params: map[string]string{"dashboard_id": "31"}},
+		},
+		{
+			name: "delete_dashboard",
+			tool: "delete_dashboard",
+			args: map[string]any{"dashboard_id": 31},
+			op:   "delete_dashboard",
+			want: requestWant{params: map[string]string{"dashboard_id": "31"}},
+		},
+		{
+			name: "detect_anomalies",
+			tool: "detect_anomalies",
+			args: map[string]any{"query": "per_min(count()) | group by service_name"},
+			op:   "query_timeseries",
+			want: requestWant{timeRange: testTimeDuration, recent: true, limit: "25"},
+		},
+		{
+			name: "detect_anomalies limit",
+			tool: "detect_anomalies",
+			args: map[string]any{"query": "per_min(count())", "limit": 3, "time_range": "last 6h"},
+			op:   "query_timeseries",
+			want: requestWant{timeRange: 6 * time.Hour, recent: true, limit: "3"},
+		},
+		{
+			name: "render_chart",
+			tool: "render_chart",
+			args: map[string]any{"query": "per_min(count()) | group by service_name", "render": "svg"},
+			op:   "query_timeseries",
+			want: requestWant{timeRange: testTimeDuration, recent: true, limit: "25"},
+		},
+		{
+			name: "render_chart quantiles",
+			tool: "render_chart",
+			args: map[string]any{"source": "quantiles", "project_id": 7, "render": "svg"},
+			op:   "query_quantiles",
+			want: requestWant{projectID: "7", timeRange: testTimeDuration, recent: true},
+		},
+		{
+			name: "correlate_attributes",
+			tool: "correlate_attributes",
+			args: map[string]any{},
+			op:   "list_spans",
+			want: requestWant{timeRange: testTimeDuration, recent: true, limit: "1000"},
+		},
+		{
+			name: "correlate_attributes group_id",
+			tool: "correlate_attributes",
+			args: map[string]any{"group_id": `1 | where service_name = "x"`},
+			op:   "list_spans",
+			want: requestWant{query: map[string]string{
+				"query": `where _group_id = "1 | where service_name = \"x\""`,
+			}},
+		},
+		{
+			name: "search_logs",
+			tool: "search_logs",
+			args: map[string]any{},
+			op:   "list_spans",
+			want: requestWant{timeRange: testTimeDuration, recent: true, limit: "100"},
+		},
+		{
+			name: "search_logs patterns",
+			tool: "search_logs",
+			args: map[string]any{"mode": "patterns"},
+			op:   "list_spans",
+			want: requestWant{timeRange: testTimeDuration, recent: true, limit: "1000"},
+		},
+		{
+			name: "list_span_attributes",
+			tool: "list_span_attributes",
+			args: timeQuery,
+			op:   "list_spans",
+			want: requestWant{timeRange: 30 * time.Minute, limit: "1000"},
+		},
+		{
+			name: "list_span_attribute_values",
+			tool: "list_span_attribute_values",
+			args: map[string]any{
+				"attribute":  "service_name",
+				"time_start": start.Format(time.RFC3339),
+				"time_end":   end.Format(time.RFC3339),
+			},
+			op:   "list_span_groups",
+			want: requestWant{timeRange: 30 * time.Minute, limit: "1000"},
+		},
+		{
+			name: "metric_cardinality_report",
+			tool: "metric_cardinality_report",
+			args: map[string]any{},
+			op:   "list_metric_attribute_values",
+			want: requestWant{timeRange: testTimeDuration, recent: true},
+		},
+		{
+			name: "generate_dashboard",
+			tool: "generate_dashboard",
+			args: map[string]any{"service": "checkout"},
+			op:   "list_span_groups",
+			want: requestWant{timeRange: testTimeDuration, recent: true},
+		},
+		{
+			name: "validate_query",
+			tool: "validate_query",
+			args: map[string]any{"query": "where service_name = 'checkout' | group by http_route"},
+			op:   "list_spans",
+			want: requestWant{timeRange: testTimeDuration, recent: true, limit: "1000"},
+		},
+		{
+			name: "slo_report",
+			tool: "slo_report",
+			args: map[string]any{
+				"total_query": "where service_name = 'checkout'",
+				"good_query":  "where _status_code != 'error'",
+				"objective":   99.9,
+			},
+			op:   "list_span_groups",
+			want: requestWant{timeRange: 30 * 24 * time.Hour, recent: true},
+		},
+		{
+			name: "backtest_monitor",
+			tool: "backtest_monitor",
+			args: map[string]any{
+				"metrics":           []string{"uptrace_tracing_spans as $spans"},
+				"query":             "p99($spans)",
+				"max_allowed_value": 500,
+			},
+			op:   "query_timeseries",
+			want: requestWant{timeRange: 14 * 24 * time.Hour, recent: true},
+		},
+		{
+			name: "plan_monitors",
+			tool: "plan_monitors",
+			args: map[string]any{"yaml": "name: Checkout errors\ntype: error\nquery: where service_name = 'checkout'\n"},
+			op:   "list_monitors",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, session := newTestSession(t)
+
+			res := testutil.CallTool(t, session, tt.tool, tt.args)
+			if res.IsError {
+				t.Fatalf("%s: %s", tt.tool, testutil.Text(res))
+			}
+			tt.want.check(t, srv.FirstRequest(tt.op))
+		})
+	}
+}
+
+func TestToolsWithoutUptrace(t *testing.T) {
+	tests := []struct {
+		tool string
+		args map[string]any
+	}{
+		{"lint_dashboard_yaml", map[string]any{"body": "schema: v2\nname: Checkout\n"}},
+		{"validate_query", map[string]any{"query": "group by service_name | count()", "skip_remote": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			srv, session := newTestSession(t)
+
+			res := testutil.CallTool(t, session, tt.tool, tt.args)
+			if res.IsError {
+				t.Fatalf("%s: %s", tt.tool, testutil.Text(res))
+			}
+			if reqs := srv.Requests(""); len(reqs) != 0 {
+				t.Errorf("got %d Uptrace requests, want none", len(reqs))
+			}
+		})
+	}
+}
+
+func TestToolInvalidLimits(t *testing.T) {
+	tests := []struct {
+		tool string
+		args map[string]any
+		want string
+	}{
+		{
+			tool: "detect_anomalies",
+			args: map[string]any{"query": "per_min(count())", "max_findings": -1},
+			want: "max_findings must be at least 1",
+		},
+		{
+			tool: "correlate_attributes",
+			args: map[string]any{"max_results": -1},
+			want: "max_results must be at least 1",
+		},
+		{
+			tool: "correlate_attributes",
+			args: map[string]any{"percentile": 150},
+			want: "percentile must be greater than 0 and at most 100",
+		},
+		{
+			tool: "correlate_attributes",
+			args: map[string]any{"percentile": -5},
+			want: "percentile must be greater than 0 and at most 100",
+		},
+		{
+			tool: "correlate_attributes",
+			args: map[string]any{"sample": -1},
+			want: "sample must be at least 1",
+		},
+		{
+			tool: "list_span_attributes",
+			args: map[string]any{"top_values": -1},
+			want: "top_values must be at least 1",
+		},
+		{
+			tool: "metric_cardinality_report",
+			args: map[string]any{"limit": -1},
+			want: "limit must be at least 1",
+		},
+		{
+			tool: "metric_cardinality_report",
+			args: map[string]any{"max_attributes": -1},
+			want: "max_attributes must be at least 1",
+		},
+		{
+			tool: "list_span_attribute_values",
+			args: map[string]any{"attribute": "http.method", "limit": -1},
+			want: "limit must be at least 1",
+		},
+		{
+			tool: "render_chart",
+			args: map[string]any{"query": "per_min(count())", "max_series": -1},
+			want: "max_series must be at least 1",
+		},
+		{
+			tool: "backtest_monitor",
+			args: map[string]any{
+				"metrics":           []string{"uptrace_tracing_spans as $spans"},
+				"query":             "p99($spans)",
+				"max_allowed_value": 500,
+				"max_alerts":        -1,
+			},
+			want: "max_alerts must be at least 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			_, session := newTestSession(t)
+
+			res := testutil.CallTool(t, session, tt.tool, tt.args)
+			if !res.IsError || !strings.Contains(testutil.Text(res), tt.want) {
+				t.Errorf("got %q, want an error containing %q", testutil.Text(res), tt.want)
+			}
+		})
+	}
+}
+
+func TestSLOObjectiveIsPercentage(t *testing.T) {
+	_, session := newTestSession(t)
+
+	args := map[string]any{
+		"total_query": "where service_name = 'checkout'",
+		"good_query":  "where _status_code != 'error'",
+		"objective":   1,
+	}
+	res := testutil.CallTool(t, session, "slo_report", args)
+	if res.IsError {
+		t.Fatal(testutil.Text(res))
+	}
+	var out struct {
+		Reports []struct {
+			Objective float64 `json:"objective"`
+		} `json:"reports"`
+	}
+	if err := json.Unmarshal([]byte(testutil.Text(res)), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Reports) != 1 || out.Reports[0].Objective != 1 {
+		t.Errorf("got %+v, want one report with objective 1", out.Reports)
+	}
+
+	args["objective"] = 100
+	res = testutil.CallTool(t, session, "slo_report", args)
+	if !res.IsError || !strings.Contains(testutil.Text(res), "objective is a percentage") {
+		t.Errorf("objective 100 is accepted:\n%s", testutil.Text(res))
+	}
+}
+
+func TestToolStructuredOutput(t *testing.T) {
+	_, session := newTestSession(t)
+
+	tools, err := session.ListTools(t.Context(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range tools.Tools {
+		if (tool.Name == "list_spans" || tool.Name == "search_logs") && tool.OutputSchema == nil {
+			t.Errorf("%s has no output schema", tool.Name)
+		}
+	}
+
+	calls := []struct {
+		tool string
+		args map[string]any
+	}{
+		{"list_spans", map[string]any{"PathParams": map[string]any{"project_id": 0}, "Query": map[string]any{}}},
+		{"search_logs", map[string]any{}},
+		{"search_logs", map[string]any{"mode": "patterns"}},
+	}
+	for _, call := range calls {
+		for _, format := range []string{"", "json", "compact", "markdown_table", "csv"} {
+			args := map[string]any{"format": format}
+			for k, v := range call.args {
+				args[k] = v
+			}
+			res := testutil.CallTool(t, session, call.tool, args)
+			if res.IsError {
+				t.Fatalf("%s format %q: %s", call.tool, format, testutil.Text(res))
+			}
+
+			structured := format == "" || format == formatJSON
+			if got := res.StructuredContent != nil; got != structured {
+				t.Errorf("%s format %q: structured content = %t, want %t", call.tool, format, got, structured)
+			}
+			if text := testutil.Text(res); strings.Contains(text, "::str") {
+				t.Errorf("%s format %q: attribute keys keep type suffixes:\n%s", call.tool, format, text)
+			}
+		}
+	}
+}
+
+func TestFormatMaxSize(t *testing.T) {
+	tbl := &table{Columns: []string{"name"}}
+	for i := range 100 {
+		tbl.Rows = append(tbl.Rows, map[string]any{"name": strings.Repeat("x", 20) + strconv.Itoa(i)})
+	}
+
+	const maxSize = 500
+	f := &tableFormatter{columns: tbl.Columns, maxSize: maxSize, maxValueLen: 200}
+	for name, format := range map[string]func(*table) string{
+		"compact":        f.compact,
+		"markdown_table": f.markdownTable,
+		"csv":            f.csv,
+	} {
+		text := format(tbl)
+		if len(text) > maxSize {
+			t.Errorf("%s: got %d bytes, want at most %d", name, len(text), maxSize)
+		}
+		if !strings.Contains(text, "more rows") {
+			t.Errorf("%s: no truncation footer:\n%s", name, text)
+		}
+	}
+
+	res := f.structured(tbl)
+	b, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) > maxSize {
+		t.Errorf("json: got %d bytes, want at most %d", len(b), maxSize)
+	}
+	if want := fmt.Sprintf("truncated, %d more rows", len(tbl.Rows)-len(res.Rows)); res.Truncated != want {
+		t.Errorf("json: truncated = %q, want %q", res.Truncated, want)
+	}
+}
+
+func TestGenerateDashboardStopsAtMaxMetrics(t *testing.T) {
+	srv, session := newTestSession(t)
+
+	metrics := make([]map[string]any, 40)
+	for i := range metrics {
+		metrics[i] = map[string]any{
+			"name":          "metric_" + strconv.Itoa(i),
+			"instrument":    "gauge",
+			"attrKeys":      []string{"service_name"},
+			"numTimeseries": 100 - i,
+		}
+	}
+	srv.SetFixture("explore_metrics", 200, map[string]any{"metrics": metrics})
+
+	res := testutil.CallTool(t, session, "generate_dashboard", map[string]any{
+		"service":     "checkout",
+		"max_metrics": 2,
+		"no_spans":    true,
+	})
+	if res.IsError {
+		t.Fatal(testutil.Text(res))
+	}
+	if n := len(srv.Requests("list_metric_attribute_values")); n > 8 {
+		t.Errorf("checked %d metrics, want at most one batch", n)
+	}
+	if !strings.Contains(testutil.Text(res), "last($metric_0)") {
+		t.Errorf("gauge is not charted with last():\n%s", testutil.Text(res))
+	}
+}
+
+func TestLintRowWidth(t *testing.T) {
+	_, session := newTestSession(t)
+
+	item := "      - title: %s\n        width: 12\n        height: 28\n        y_axis: %d\n        type: chart\n" +
+		"        metrics:\n          - uptrace_tracing_spans as $spans\n        query:\n          - count($spans)\n"
+	lint := func(ys ...int) string {
+		body := "schema: v2\nname: Checkout\ngrid_rows:\n  - title: Spans\n    items:\n"
+		for i, y := range ys {
+			body += fmt.Sprintf(item, "chart"+strconv.Itoa(i), y)
+		}
+		res := testutil.CallTool(t, session, "lint_dashboard_yaml", map[string]any{"body": body})
+		if res.IsError {
+			t.Fatal(testutil.Text(res))
+		}
+		return testutil.Text(res)
+	}
+
+	if text := lint(0, 0, 0); !strings.Contains(text, "items at y_axis 0 are 36 columns wide") {
+		t.Errorf("three 12-column items on one line pass lint:\n%s", text)
+	}
+	if text := lint(0, 0, 28); strings.Contains(text, "columns wide") {
+		t.Errorf("wrapped items fail lint:\n%s", text)
+	}
+}
+
+func TestGenerateDashboardFitsGrid(t *testing.T) {
+	srv, session := newTestSession(t)
+
+	metrics := make([]map[string]any, 3)
+	for i := range metrics {
+		metrics[i] = map[string]any{
+			"name":          "http.metric_" + strconv.Itoa(i),
+			"instrument":    "histogram",
+			"attrKeys":      []string{"service_name"},
+			"numTimeseries": 10,
+		}
+	}
+	srv.SetFixture("explore_metrics", 200, map[string]any{"metrics": metrics})
+
+	res := testutil.CallTool(t, session, "generate_dashboard", map[string]any{"service": "checkout"})
+	if res.IsError {
+		t.Fatal(testutil.Text(res))
+	}
+	var out struct {
+		YAML string `json:"yaml"`
+	}
+	if err := json.Unmarshal([]byte(testutil.Text(res)), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	res = testutil.CallTool(t, session, "lint_dashboard_yaml", map[string]any{"body": out.YAML})
+	if res.IsError {
+		t.Fatal(testutil.Text(res))
+	}
+	if text := testutil.Text(res); strings.Contains(text, "columns wide") {
+		t.Errorf("generated dashboard overflows the grid:\n%s", text)
+	}
+}
+
+func TestDashboardYAMLRoundTrip(t *testing.T) {
+	srv, session := newTestSession(t)
+
+	res := testutil.CallTool(t, session, "get_dashboard_yaml", map[string]any{"dashboard_id": 31})
+	if res.IsError {
+		t.Fatal(testutil.Text(res))
+	}
+	body := testutil.Text(res)
+
+	res = testutil.CallTool(t, session, "lint_dashboard_yaml", map[string]any{"body": body})
+	if res.IsError {
+		t.Fatal(testutil.Text(res))
+	}
+	var lint struct {
+		Valid bool `json:"valid"`
+	}
+	if err := json.Unmarshal([]byte(testutil.Text(res)), &lint); err != nil {
+		t.Fatal(err)
+	}
+	if !lint.Valid {
+		t.Fatalf("exported dashboard does not lint:\n%s", testutil.Text(res))
+	}
+
+	res = testutil.CallTool(t, session, "update_dashboard_yaml", map[string]any{"dashboard_id": 31, "body": body})
+	if res.IsError {
+		t.Fatal(testutil.Text(res))
+	}
+	if got := string(srv.FirstRequest("update_dashboard_from_yaml").Body); got != body {
+		t.Errorf("applied body = %q, want %q", got, body)
+	}
+}
+
+func TestPlanMonitorsReadsNoFiles(t *testing.T) {
+	_, session := newTestSession(t)
+
+	tools, err := session.ListTools(t.Context(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range tools.Tools {
+		if tool.Name != "plan_monitors" {
+			continue
+		}
+		b, err := json.Marshal(tool.InputSchema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var schema struct {
+			Properties map[string]any `json:"properties"`
+		}
+		if err := json.Unmarshal(b, &schema); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := schema.Properties["dir"]; ok {
+			t.Error("plan_monitors accepts a directory on the server host")
+		}
+	}
+}
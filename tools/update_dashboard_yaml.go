@@ -53,6 +53,10 @@ func (t *UpdateDashboardYamlTool) handler(
 		projectID = t.conf.Uptrace.ProjectID
 	}
 
+	if res := dashboardLintResult(input.Body); res != nil {
+		return res, nil, nil
+	}
+
 	opts := &uptraceapi.UpdateDashboardFromYamlRequestOptions{
 		PathParams: &uptraceapi.UpdateDashboardFromYamlPath{
 			ProjectID:   projectID,
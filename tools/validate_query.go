@@ -0,0 +1,361 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/uptrace/mcp/appconf"
+	"github.com/uptrace/mcp/uptraceapi"
+	"github.com/uptrace/mcp/uql"
+)
+
+type ValidateQueryTool struct {
+	client *uptraceapi.Client
+	conf   *appconf.Config
+}
+
+func NewValidateQueryTool(client *uptraceapi.Client, conf *appconf.Config) *ValidateQueryTool {
+	return &ValidateQueryTool{
+		client: client,
+		conf:   conf,
+	}
+}
+
+func (t *ValidateQueryTool) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "validate_query",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Validate query",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+		Description: "Validate a UQL query before running it and explain what it computes. " +
+			"Parses the query locally (where, group by, aggregations, having, attribute type suffixes " +
+			"such as ::str), reports syntax errors with their position, checks that referenced attributes " +
+			"exist in recent spans, logs or metrics, and suggests close matches for unknown names.",
+	}, t.handler)
+}
+
+type validateQueryInput struct {
+	Query      string    `json:"query" jsonschema:"UQL query to validate, e.g. where _status_code = \"error\" | group by service_name | p99(_duration)."`
+	Kind       string    `json:"kind,omitempty" jsonschema:"What the query runs against: spans, logs or metrics. Defaults to spans."`
+	Metrics    []string  `json:"metrics,omitempty" jsonschema:"For metrics queries, the metric aliases used by the query, e.g. system_cpu_utilization as $cpu."`
+	ProjectID  int64     `json:"project_id,omitempty" jsonschema:"Uptrace project ID."`
+	TimeStart  time.Time `json:"time_start,omitempty" jsonschema:"Start of the time range used to discover attributes, as RFC3339 timestamp."`
+	TimeEnd    time.Time `json:"time_end,omitempty" jsonschema:"End of the time range used to discover attributes, as RFC3339 timestamp."`
+	SkipRemote bool      `json:"skip_remote,omitempty" jsonschema:"Only check the syntax; don't call Uptrace to check attribute and metric names."`
+
+	timeRangeOptions
+}
+
+type validateQueryOutput struct {
+	Valid       bool           `json:"valid"`
+	Errors      []queryProblem `json:"errors,omitempty"`
+	Warnings    []queryProblem `json:"warnings,omitempty"`
+	Explanation string         `json:"explanation,omitempty" jsonschema:"Plain-language description of what the query computes."`
+	Attributes  []string       `json:"attributes,omitempty" jsonschema:"Attributes referenced by the query."`
+}
+
+type queryProblem struct {
+	Message  string `json:"message"`
+	Position int    `json:"position,omitempty" jsonschema:"1-based character position in the query."`
+	Near     string `json:"near,omitempty" jsonschema:"Query text starting at the position."`
+}
+
+func (t *ValidateQueryTool) handler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input *validateQueryInput,
+) (*mcp.CallToolResult, *validateQueryOutput, error) {
+	projectID := input.ProjectID
+	if projectID == 0 {
+		projectID = t.conf.Uptrace.ProjectID
+	}
+	if err := input.resolveTimeRange(t.conf, &input.TimeStart, &input.TimeEnd); err != nil {
+		return nil, nil, err
+	}
+	if input.Kind == "" {
+		input.Kind = "spans"
+	}
+
+	var subject string
+	switch input.Kind {
+	case "spans":
+		subject = "spans"
+	case "logs":
+		subject = "log records"
+	case "metrics":
+		subject = "metric data points"
+	default:
+		return nil, nil, fmt.Errorf("unknown kind %q (supported: spans, logs, metrics)", input.Kind)
+	}
+
+	out := new(validateQueryOutput)
+	q, errs := uql.Parse(input.Query)
+	for _, err := range errs {
+		out.Errors = append(out.Errors, newQueryProblem(input.Query, err))
+	}
+	if q != nil {
+		for _, warn := range q.Warnings {
+			out.Warnings = append(out.Warnings, newQueryProblem(input.Query, warn))
+		}
+		seen := make(map[string]bool)
+		for _, attr := range q.Attrs {
+			if !seen[attr.Name] {
+				seen[attr.Name] = true
+				out.Attributes = append(out.Attributes, attr.Name)
+			}
+		}
+	}
+
+	if q != nil && len(errs) == 0 {
+		out.Explanation = uql.Explain(q, subject)
+		if err := t.checkMetrics(ctx, projectID, input, q, out); err != nil {
+			return nil, nil, err
+		}
+		if !input.SkipRemote {
+			if err := t.checkAttrs(ctx, projectID, input, q, out); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	out.Valid = len(out.Errors) == 0
+	return nil, out, nil
+}
+
+// checkMetrics checks that metric aliases such as $cpu are declared and that the
+// declared metrics exist.
+func (t *ValidateQueryTool) checkMetrics(
+	ctx context.Context,
+	projectID int64,
+	input *validateQueryInput,
+	q *uql.Query,
+	out *validateQueryOutput,
+) error {
+	if input.Kind != "metrics" {
+		for _, ref := range q.Metrics {
+			out.Errors = append(out.Errors, newQueryProblem(input.Query, &uql.Error{
+				Pos: ref.Pos,
+				Msg: fmt.Sprintf("metric alias %s can only be used in metrics queries", ref.Name),
+			}))
+		}
+		return nil
+	}
+
+	declared := make(map[string]string)
+	for _, decl := range input.Metrics {
+		name, alias, ok := strings.Cut(decl, " as ")
+		name, alias = strings.TrimSpace(name), strings.TrimSpace(alias)
+		if !ok || name == "" || !strings.HasPrefix(alias, "$") {
+			return fmt.Errorf("invalid metric %q (expected <metric_name> as $<alias>)", decl)
+		}
+		declared[alias] = name
+	}
+
+	reported := make(map[string]bool)
+	for _, ref := range q.Metrics {
+		if _, ok := declared[ref.Name]; ok || reported[ref.Name] {
+			continue
+		}
+		reported[ref.Name] = true
+		problem := newQueryProblem(input.Query, &uql.Error{
+			Pos: ref.Pos,
+			Msg: fmt.Sprintf("metric alias %s is not declared in metrics", ref.Name),
+		})
+		if len(input.Metrics) > 0 {
+			out.Errors = append(out.Errors, problem)
+		} else {
+			out.Warnings = append(out.Warnings, problem)
+		}
+	}
+
+	if input.SkipRemote {
+		return nil
+	}
+	for _, alias := range sortedKeys(declared) {
+		name := declared[alias]
+		resp, err := t.client.ExploreMetrics(ctx, &uptraceapi.ExploreMetricsRequestOptions{
+			PathParams: &uptraceapi.ExploreMetricsPath{ProjectID: projectID},
+			Query: &uptraceapi.ExploreMetricsQuery{
+				TimeStart: input.TimeStart,
+				TimeEnd:   input.TimeEnd,
+				Search:    &name,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		var names []string
+		found := false
+		for _, m := range resp.Metrics {
+			names = append(names, m.Name)
+			if m.Name == name {
+				found = true
+			}
+		}
+		if !found {
+			out.Errors = append(out.Errors, queryProblem{
+				Message: fmt.Sprintf("metric %s (%s) has no data in the time range%s",
+					name, alias, didYouMean(name, names)),
+			})
+		}
+	}
+	return nil
+}
+
+// checkAttrs compares the referenced attributes with the attributes seen in a sample
+// of recent spans or logs, or with the metric attributes. Unknown attributes are
+// reported as warnings since they may simply be absent from the sample.
+func (t *ValidateQueryTool) checkAttrs(
+	ctx context.Context,
+	projectID int64,
+	input *validateQueryInput,
+	q *uql.Query,
+	out *validateQueryOutput,
+) error {
+	var keys []string
+	switch input.Kind {
+	case "metrics":
+		resp, err := t.client.ListMetricAttributes(ctx, &uptraceapi.ListMetricAttributesRequestOptions{
+			PathParams: &uptraceapi.ListMetricAttributesPath{ProjectID: projectID},
+			Query: &uptraceapi.ListMetricAttributesQuery{
+				TimeStart: input.TimeStart,
+				TimeEnd:   input.TimeEnd,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		for _, item := range resp.Items {
+			keys = append(keys, item.Value)
+		}
+	default:
+		var system []string
+		if input.Kind == "logs" {
+			system = []string{"log:all"}
+		}
+		limit := uptraceapi.Limit(1000)
+		resp, err := t.client.ListSpans(ctx, &uptraceapi.ListSpansRequestOptions{
+			PathParams: &uptraceapi.ListSpansPath{ProjectID: projectID},
+			Query: &uptraceapi.ListSpansQuery{
+				TimeStart: input.TimeStart,
+				TimeEnd:   input.TimeEnd,
+				System:    system,
+				Limit:     &limit,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		seen := make(map[string]bool)
+		for _, span := range resp.Spans {
+			for key := range span.Attrs {
+				if !seen[key] {
+					seen[key] = true
+					keys = append(keys, key)
+				}
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		out.Warnings = append(out.Warnings, queryProblem{
+			Message: fmt.Sprintf("no %s attributes found in the time range; attribute names were not checked", input.Kind),
+		})
+		return nil
+	}
+
+	// Attribute types by name, e.g. http_status_code -> int.
+	types := make(map[string]string, len(keys))
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		name, typ, _ := strings.Cut(key, "::")
+		if _, ok := types[name]; !ok {
+			names = append(names, name)
+		}
+		types[name] = typ
+	}
+	sort.Strings(names)
+
+	reported := make(map[string]bool)
+	for _, attr := range q.Attrs {
+		// Built-in columns such as _duration and _status_code aren't attributes.
+		if strings.HasPrefix(attr.Name, "_") || reported[attr.Name] {
+			continue
+		}
+		typ, ok := types[attr.Name]
+		switch {
+		case !ok:
+			reported[attr.Name] = true
+			out.Warnings = append(out.Warnings, newQueryProblem(input.Query, &uql.Error{
+				Pos: attr.Pos,
+				Msg: fmt.Sprintf("attribute %s was not found in recent %s%s",
+					attr.Name, input.Kind, didYouMean(attr.Name, names)),
+			}))
+		case attr.Type != "" && typ != "" && attr.Type != typ:
+			reported[attr.Name] = true
+			out.Warnings = append(out.Warnings, newQueryProblem(input.Query, &uql.Error{
+				Pos: attr.Pos,
+				Msg: fmt.Sprintf("attribute %s has type %s, not %s", attr.Name, typ, attr.Type),
+			}))
+		}
+	}
+	return nil
+}
+
+func newQueryProblem(query string, err *uql.Error) queryProblem {
+	pos := min(max(err.Pos, 0), len(query))
+	near := []rune(query[pos:])
+	if len(near) > 30 {
+		near = append(near[:30], '…')
+	}
+	return queryProblem{
+		Message:  err.Msg,
+		Position: utf8.RuneCountInString(query[:pos]) + 1,
+		Near:     string(near),
+	}
+}
+
+// didYouMean suggests the closest candidate by edit distance, if any is close enough.
+func didYouMean(name string, candidates []string) string {
+	best, bestDist := "", len(name)/3+2
+	for _, c := range candidates {
+		if d := editDistance(name, c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %s?)", best)
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,210 @@
+package uql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// String formats the expression back as UQL.
+func (e *Expr) String() string {
+	switch e.Kind {
+	case ExprAttr:
+		if e.Type != "" {
+			return e.Name + "::" + e.Type
+		}
+		return e.Name
+	case ExprString:
+		return fmt.Sprintf("%q", e.Name)
+	case ExprFunc:
+		args := make([]string, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = arg.String()
+		}
+		return e.Name + "(" + strings.Join(args, ", ") + ")"
+	case ExprBinary:
+		return e.Args[0].String() + " " + e.Name + " " + e.Args[1].String()
+	default:
+		return e.Name
+	}
+}
+
+var comparisonWords = map[string]string{
+	"=":        "equals",
+	"!=":       "does not equal",
+	"<":        "is less than",
+	"<=":       "is at most",
+	">":        "is greater than",
+	">=":       "is at least",
+	"~":        "matches the regexp",
+	"!~":       "does not match the regexp",
+	"like":     "matches the pattern",
+	"contains": "contains",
+	"in":       "is one of",
+}
+
+var arithWords = map[string]string{
+	"+": "plus",
+	"-": "minus",
+	"*": "times",
+	"/": "divided by",
+	"%": "modulo",
+}
+
+// Explain describes in plain words what the query computes. Subject names the rows
+// being queried, e.g. "spans" or "metric data points".
+func Explain(q *Query, subject string) string {
+	var lines []string
+	var groupBy []string
+	var columns []*Column
+	hasAgg := false
+
+	for _, part := range q.Parts {
+		switch part.Kind {
+		case PartWhere:
+			lines = append(lines, fmt.Sprintf("Keeps only %s where %s.", subject, describeCond(part.Cond)))
+		case PartGroupBy:
+			for _, expr := range part.GroupBy {
+				groupBy = append(groupBy, expr.String())
+			}
+		case PartSelect:
+			for _, col := range part.Columns {
+				columns = append(columns, col)
+				if hasAggregate(col.Expr) {
+					hasAgg = true
+				}
+			}
+		}
+	}
+
+	if len(groupBy) > 0 {
+		lines = append(lines, fmt.Sprintf(
+			"Groups the %s by %s, producing one row per unique combination of values.",
+			subject, strings.Join(groupBy, ", ")))
+	}
+
+	switch {
+	case len(columns) == 0 && len(groupBy) > 0:
+		lines = append(lines, "No aggregation is given, so each group gets the default columns.")
+	case len(columns) == 0:
+		lines = append(lines, fmt.Sprintf("Returns the matching %s.", subject))
+	default:
+		switch {
+		case len(groupBy) > 0:
+			lines = append(lines, "For each group, computes:")
+		case hasAgg:
+			lines = append(lines, fmt.Sprintf("Computes over all matching %s:", subject))
+		default:
+			lines = append(lines, fmt.Sprintf("Returns these columns for each matching %s:", singular(subject)))
+		}
+		for _, col := range columns {
+			line := fmt.Sprintf("- %s: %s", col.Expr, describeExpr(col.Expr, subject))
+			if col.Alias != "" {
+				line += fmt.Sprintf(" (named %s)", col.Alias)
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	for _, part := range q.Parts {
+		if part.Kind == PartHaving {
+			what := "groups"
+			if len(groupBy) == 0 {
+				what = "results"
+			}
+			lines = append(lines, fmt.Sprintf("Then keeps only %s where %s.", what, describeCond(part.Cond)))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func describeExpr(e *Expr, subject string) string {
+	switch e.Kind {
+	case ExprAttr:
+		if strings.HasPrefix(e.Name, "$") {
+			return "metric " + e.Name
+		}
+		return "the value of " + e.String()
+	case ExprFunc:
+		fn, ok := lookupFunc(e.Name)
+		if !ok {
+			return "result of the function " + e.String()
+		}
+		if len(e.Args) == 0 {
+			if e.Name == "count" {
+				return "number of " + subject
+			}
+			return e.Name + "()"
+		}
+		return fmt.Sprintf(fn.desc, describeArg(e.Args[0], subject))
+	case ExprBinary:
+		return fmt.Sprintf("%s %s %s",
+			describeArg(e.Args[0], subject), arithWords[e.Name], describeArg(e.Args[1], subject))
+	default:
+		return e.String()
+	}
+}
+
+// describeArg describes a function argument; attributes are named directly.
+func describeArg(e *Expr, subject string) string {
+	switch e.Kind {
+	case ExprAttr, ExprNumber, ExprString:
+		return e.String()
+	case ExprBinary:
+		return "(" + describeExpr(e, subject) + ")"
+	default:
+		return describeExpr(e, subject)
+	}
+}
+
+func describeCond(c *Cond) string {
+	if len(c.Conds) > 0 {
+		parts := make([]string, len(c.Conds))
+		for i, sub := range c.Conds {
+			s := describeCond(sub)
+			if len(sub.Conds) > 1 {
+				s = "(" + s + ")"
+			}
+			parts[i] = s
+		}
+		s := strings.Join(parts, " "+c.Op+" ")
+		if c.Not {
+			return "not (" + s + ")"
+		}
+		return s
+	}
+
+	left := c.Left.String()
+	if c.Op == "exists" {
+		if c.Not {
+			return left + " is not set"
+		}
+		return left + " is set"
+	}
+
+	values := make([]string, len(c.Values))
+	for i, v := range c.Values {
+		values[i] = v.String()
+	}
+	value := strings.Join(values, ", ")
+	if c.Op == "in" {
+		value = "(" + value + ")"
+	}
+
+	words := comparisonWords[c.Op]
+	if c.Not {
+		switch c.Op {
+		case "in":
+			words = "is not one of"
+		case "like":
+			words = "does not match the pattern"
+		case "contains":
+			words = "does not contain"
+		}
+	}
+	return fmt.Sprintf("%s %s %s", left, words, value)
+}
+
+func singular(subject string) string {
+	return strings.TrimSuffix(subject, "s")
+}
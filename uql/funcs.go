@@ -0,0 +1,86 @@
+package uql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+type funcInfo struct {
+	minArgs, maxArgs int
+	agg              bool
+	// wrapsAgg allows aggregates as arguments, e.g. perMin(count()).
+	wrapsAgg bool
+	// desc describes the result; %s is replaced with the description of the argument.
+	desc string
+}
+
+func (fn funcInfo) arity() string {
+	switch {
+	case fn.minArgs == fn.maxArgs && fn.minArgs == 0:
+		return "takes no arguments"
+	case fn.minArgs == fn.maxArgs && fn.minArgs == 1:
+		return "takes 1 argument"
+	case fn.minArgs == fn.maxArgs:
+		return fmt.Sprintf("takes %d arguments", fn.minArgs)
+	default:
+		return fmt.Sprintf("takes %d to %d arguments", fn.minArgs, fn.maxArgs)
+	}
+}
+
+var funcs = map[string]funcInfo{
+	"count":   {minArgs: 0, maxArgs: 1, agg: true, desc: "number of values of %s"},
+	"uniq":    {minArgs: 1, maxArgs: 1, agg: true, desc: "number of unique values of %s"},
+	"avg":     {minArgs: 1, maxArgs: 1, agg: true, desc: "average of %s"},
+	"sum":     {minArgs: 1, maxArgs: 1, agg: true, desc: "sum of %s"},
+	"min":     {minArgs: 1, maxArgs: 1, agg: true, desc: "minimum of %s"},
+	"max":     {minArgs: 1, maxArgs: 1, agg: true, desc: "maximum of %s"},
+	"median":  {minArgs: 1, maxArgs: 1, agg: true, desc: "median of %s"},
+	"any":     {minArgs: 1, maxArgs: 1, agg: true, desc: "an arbitrary value of %s"},
+	"anyLast": {minArgs: 1, maxArgs: 1, agg: true, desc: "the last seen value of %s"},
+	"last":    {minArgs: 1, maxArgs: 1, agg: true, desc: "the last value of %s"},
+	"delta":   {minArgs: 1, maxArgs: 1, agg: true, desc: "change of %s over each interval"},
+	"perMin":  {minArgs: 1, maxArgs: 1, agg: true, wrapsAgg: true, desc: "%s per minute"},
+	"per_min": {minArgs: 1, maxArgs: 1, agg: true, wrapsAgg: true, desc: "%s per minute"},
+	"perSec":  {minArgs: 1, maxArgs: 1, agg: true, wrapsAgg: true, desc: "%s per second"},
+	"per_sec": {minArgs: 1, maxArgs: 1, agg: true, wrapsAgg: true, desc: "%s per second"},
+	"lower":   {minArgs: 1, maxArgs: 1, desc: "%s in lowercase"},
+	"upper":   {minArgs: 1, maxArgs: 1, desc: "%s in uppercase"},
+}
+
+var (
+	percentileRE = regexp.MustCompile(`^p(\d{1,2}(?:\.\d+)?)$`)
+	topRE        = regexp.MustCompile(`^top(\d+)$`)
+)
+
+func lookupFunc(name string) (funcInfo, bool) {
+	if fn, ok := funcs[name]; ok {
+		return fn, true
+	}
+	if m := percentileRE.FindStringSubmatch(name); m != nil {
+		return funcInfo{minArgs: 1, maxArgs: 1, agg: true, desc: ordinal(m[1]) + " percentile of %s"}, true
+	}
+	if m := topRE.FindStringSubmatch(name); m != nil {
+		return funcInfo{minArgs: 1, maxArgs: 1, agg: true, desc: "the " + m[1] + " most frequent values of %s"}, true
+	}
+	return funcInfo{}, false
+}
+
+func ordinal(s string) string {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return s + "th"
+	}
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+		return s + "th"
+	case n%10 == 1:
+		return s + "st"
+	case n%10 == 2:
+		return s + "nd"
+	case n%10 == 3:
+		return s + "rd"
+	default:
+		return s + "th"
+	}
+}
@@ -0,0 +1,223 @@
+// Package uql parses Uptrace Query Language queries such as
+// `where _status_code = "error" | group by service_name | p99(_duration)`
+// so they can be validated and explained without calling the Uptrace API.
+package uql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp    // = != <> < <= > >= ~ !~
+	tokArith // + - * / %
+	tokLParen
+	tokRParen
+	tokComma
+	tokPipe
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return "end of query"
+	case tokIdent:
+		return "identifier"
+	case tokString:
+		return "string"
+	case tokNumber:
+		return "number"
+	case tokOp:
+		return "operator"
+	case tokArith:
+		return "arithmetic operator"
+	case tokLParen:
+		return `"("`
+	case tokRParen:
+		return `")"`
+	case tokComma:
+		return `","`
+	case tokPipe:
+		return `"|"`
+	default:
+		return "token"
+	}
+}
+
+type token struct {
+	kind tokenKind
+	text string // raw text; unquoted for strings
+	pos  int    // byte offset in the query
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokString:
+		return fmt.Sprintf("string %q", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// Error is a syntax or semantic error at a byte offset in the query.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("pos %d: %s", e.Pos, e.Msg)
+}
+
+func lex(query string) ([]token, *Error) {
+	var tokens []token
+	for i := 0; i < len(query); {
+		r, size := utf8.DecodeRuneInString(query[i:])
+		start := i
+
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+			continue
+		case r == '|':
+			tokens = append(tokens, token{tokPipe, "|", start})
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokLParen, "(", start})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokRParen, ")", start})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{tokComma, ",", start})
+			i++
+		case r == '"' || r == '\'' || r == '`':
+			text, end, ok := lexString(query, i)
+			if !ok {
+				return nil, &Error{start, "unterminated string"}
+			}
+			tokens = append(tokens, token{tokString, text, start})
+			i = end
+		case r == '=' || r == '<' || r == '>' || r == '!' || r == '~':
+			op := string(r)
+			if i+1 < len(query) {
+				two := query[i : i+2]
+				switch two {
+				case "!=", "<>", "<=", ">=", "!~", "==":
+					op = two
+				}
+			}
+			if op == "!" {
+				return nil, &Error{start, `unexpected "!" (did you mean "!="?)`}
+			}
+			tokens = append(tokens, token{tokOp, op, start})
+			i += len(op)
+		case r == '+' || r == '-' || r == '*' || r == '/' || r == '%':
+			if r == '-' && i+1 < len(query) && isDigit(query[i+1]) && expectsOperand(tokens) {
+				end := lexNumber(query, i+1)
+				tokens = append(tokens, token{tokNumber, query[i:end], start})
+				i = end
+				continue
+			}
+			tokens = append(tokens, token{tokArith, string(r), start})
+			i++
+		case isDigit(byte(r)) && r < utf8.RuneSelf:
+			end := lexNumber(query, i)
+			tokens = append(tokens, token{tokNumber, query[i:end], start})
+			i = end
+		case isIdentStart(r):
+			end := lexIdent(query, i)
+			tokens = append(tokens, token{tokIdent, query[i:end], start})
+			i = end
+		default:
+			return nil, &Error{start, fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+	tokens = append(tokens, token{tokEOF, "", len(query)})
+	return tokens, nil
+}
+
+func lexString(query string, i int) (string, int, bool) {
+	quote := query[i]
+	var b strings.Builder
+	for j := i + 1; j < len(query); j++ {
+		c := query[j]
+		switch {
+		case c == '\\' && j+1 < len(query):
+			j++
+			b.WriteByte(query[j])
+		case c == quote:
+			return b.String(), j + 1, true
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, false
+}
+
+// lexNumber scans digits, a fraction and an optional unit such as 10ms or 1.5kb.
+func lexNumber(query string, i int) int {
+	for i < len(query) && (isDigit(query[i]) || query[i] == '.') {
+		i++
+	}
+	for i < len(query) {
+		r, size := utf8.DecodeRuneInString(query[i:])
+		if !unicode.IsLetter(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// lexIdent scans attribute names (http.request.method), metric aliases ($cpu) and
+// type suffixes (service_name::str, tags::str[]).
+func lexIdent(query string, i int) int {
+	for i < len(query) {
+		r, size := utf8.DecodeRuneInString(query[i:])
+		if isIdentStart(r) || unicode.IsDigit(r) || r == '.' {
+			i += size
+			continue
+		}
+		if strings.HasPrefix(query[i:], "::") {
+			i += 2
+			continue
+		}
+		if strings.HasPrefix(query[i:], "[]") {
+			i += 2
+			continue
+		}
+		break
+	}
+	return i
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+// expectsOperand reports whether a minus sign at this point starts a negative number.
+func expectsOperand(tokens []token) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+	switch tokens[len(tokens)-1].kind {
+	case tokOp, tokArith, tokLParen, tokComma, tokPipe:
+		return true
+	}
+	return false
+}
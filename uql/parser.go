@@ -0,0 +1,598 @@
+package uql
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// PartKind is the kind of a pipe-separated query part.
+type PartKind int
+
+const (
+	PartSelect PartKind = iota
+	PartWhere
+	PartGroupBy
+	PartHaving
+)
+
+func (k PartKind) String() string {
+	switch k {
+	case PartWhere:
+		return "where"
+	case PartGroupBy:
+		return "group by"
+	case PartHaving:
+		return "having"
+	default:
+		return "select"
+	}
+}
+
+// Query is a parsed UQL query.
+type Query struct {
+	Parts []*Part
+	// Attrs are the attributes referenced anywhere in the query, e.g. service_name or
+	// _duration, in the order of appearance.
+	Attrs []Attr
+	// Metrics are the referenced metric aliases such as $cpu.
+	Metrics []Attr
+	// Warnings describe constructs that parse but probably don't do what was meant.
+	Warnings []*Error
+}
+
+// Part is one pipe-separated part, e.g. where ..., group by ... or an aggregation.
+type Part struct {
+	Kind    PartKind
+	Pos     int
+	Cond    *Cond     // where and having
+	GroupBy []*Expr   // group by
+	Columns []*Column // select
+}
+
+// Column is a selected expression with an optional alias.
+type Column struct {
+	Expr  *Expr
+	Alias string
+}
+
+type ExprKind int
+
+const (
+	ExprAttr ExprKind = iota
+	ExprNumber
+	ExprString
+	ExprFunc
+	ExprBinary
+)
+
+// Expr is a value expression. For ExprBinary, Name is the operator and Args holds
+// both operands.
+type Expr struct {
+	Kind ExprKind
+	Pos  int
+	Name string
+	Type string // attribute type suffix, e.g. str for service_name::str
+	Args []*Expr
+}
+
+// Cond is a boolean condition. Op is "and" or "or" for Conds, and a comparison such
+// as "=", "in", "like", "contains" or "exists" otherwise.
+type Cond struct {
+	Pos    int
+	Op     string
+	Not    bool
+	Left   *Expr
+	Values []*Expr
+	Conds  []*Cond
+}
+
+// Attr is an attribute or metric reference.
+type Attr struct {
+	Name string
+	Type string
+	Pos  int
+}
+
+var attrTypes = map[string]bool{
+	"str": true, "int": true, "float": true, "bool": true,
+	"str[]": true, "int[]": true, "float[]": true, "bool[]": true,
+}
+
+var numberRE = regexp.MustCompile(`^-?\d+(\.\d+)?([a-zA-Zµ]*)$`)
+
+var numberUnits = map[string]bool{
+	"": true, "ns": true, "us": true, "µs": true, "ms": true, "s": true, "m": true, "h": true, "d": true,
+	"b": true, "kb": true, "mb": true, "gb": true, "tb": true, "kib": true, "mib": true, "gib": true, "tib": true,
+}
+
+// Parse parses a UQL query. It returns all syntax errors it can find, recovering at
+// the next pipe after each one.
+func Parse(query string) (*Query, []*Error) {
+	tokens, lexErr := lex(query)
+	if lexErr != nil {
+		return nil, []*Error{lexErr}
+	}
+
+	p := &parser{tokens: tokens, q: new(Query)}
+	for {
+		if p.peek().kind == tokEOF {
+			break
+		}
+		if p.peek().kind == tokPipe {
+			p.errorf(p.peek().pos, "empty query part")
+			p.next()
+			continue
+		}
+
+		part, err := p.parsePart()
+		if err != nil {
+			p.errs = append(p.errs, err)
+			p.skipPart()
+		} else {
+			p.q.Parts = append(p.q.Parts, part)
+		}
+
+		switch tok := p.next(); tok.kind {
+		case tokEOF:
+			return p.finish()
+		case tokPipe:
+			if p.peek().kind == tokEOF {
+				p.errorf(tok.pos, `query ends with "|"`)
+			}
+		default:
+			p.errorf(tok.pos, "unexpected %s, expected \"|\" or end of query", tok)
+			p.skipPart()
+			p.next()
+		}
+	}
+	return p.finish()
+}
+
+// finish drops column aliases, e.g. rpm in having rpm > 100, from the referenced attributes.
+func (p *parser) finish() (*Query, []*Error) {
+	aliases := make(map[string]bool)
+	for _, part := range p.q.Parts {
+		for _, col := range part.Columns {
+			if col.Alias != "" {
+				aliases[col.Alias] = true
+			}
+		}
+	}
+	p.q.Attrs = slices.DeleteFunc(p.q.Attrs, func(attr Attr) bool {
+		return attr.Type == "" && aliases[attr.Name]
+	})
+	return p.q, p.errs
+}
+
+type parser struct {
+	tokens []token
+	i      int
+	q      *Query
+	errs   []*Error
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.i]
+	if tok.kind != tokEOF {
+		p.i++
+	}
+	return tok
+}
+
+func (p *parser) errorf(pos int, format string, args ...any) {
+	p.errs = append(p.errs, &Error{pos, fmt.Sprintf(format, args...)})
+}
+
+func (p *parser) warnf(pos int, format string, args ...any) {
+	p.q.Warnings = append(p.q.Warnings, &Error{pos, fmt.Sprintf(format, args...)})
+}
+
+type parserState struct {
+	i, errs, attrs, metrics, warnings int
+}
+
+func (p *parser) save() parserState {
+	return parserState{p.i, len(p.errs), len(p.q.Attrs), len(p.q.Metrics), len(p.q.Warnings)}
+}
+
+func (p *parser) restore(s parserState) {
+	p.i = s.i
+	p.errs = p.errs[:s.errs]
+	p.q.Attrs = p.q.Attrs[:s.attrs]
+	p.q.Metrics = p.q.Metrics[:s.metrics]
+	p.q.Warnings = p.q.Warnings[:s.warnings]
+}
+
+// skipPart advances to the next pipe or the end of the query.
+func (p *parser) skipPart() {
+	for p.peek().kind != tokPipe && p.peek().kind != tokEOF {
+		p.next()
+	}
+}
+
+func (p *parser) isKeyword(word string) bool {
+	tok := p.peek()
+	return tok.kind == tokIdent && strings.EqualFold(tok.text, word)
+}
+
+func (p *parser) acceptKeyword(word string) bool {
+	if p.isKeyword(word) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, *Error) {
+	tok := p.next()
+	if tok.kind != kind {
+		return tok, &Error{tok.pos, fmt.Sprintf("expected %s, got %s", what, tok)}
+	}
+	return tok, nil
+}
+
+func (p *parser) parsePart() (*Part, *Error) {
+	start := p.peek()
+	switch {
+	case p.acceptKeyword("where"):
+		cond, err := p.parseOr(PartWhere)
+		if err != nil {
+			return nil, err
+		}
+		return &Part{Kind: PartWhere, Pos: start.pos, Cond: cond}, nil
+	case p.acceptKeyword("having"):
+		cond, err := p.parseOr(PartHaving)
+		if err != nil {
+			return nil, err
+		}
+		return &Part{Kind: PartHaving, Pos: start.pos, Cond: cond}, nil
+	case p.acceptKeyword("group"):
+		if !p.acceptKeyword("by") {
+			return nil, &Error{p.peek().pos, fmt.Sprintf(`expected "by" after "group", got %s`, p.peek())}
+		}
+		return p.parseGroupBy(start.pos)
+	case p.isKeyword("order") || p.isKeyword("sort"):
+		return nil, &Error{start.pos, fmt.Sprintf("%q is not supported in UQL; sorting is a separate API parameter", start.text)}
+	}
+	return p.parseSelect(start.pos)
+}
+
+func (p *parser) parseGroupBy(pos int) (*Part, *Error) {
+	part := &Part{Kind: PartGroupBy, Pos: pos}
+	for {
+		expr, err := p.parseExpr(PartGroupBy)
+		if err != nil {
+			return nil, err
+		}
+		if hasAggregate(expr) {
+			return nil, &Error{expr.Pos, "aggregate functions can't be used in group by"}
+		}
+		part.GroupBy = append(part.GroupBy, expr)
+		if p.peek().kind != tokComma {
+			return part, nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseSelect(pos int) (*Part, *Error) {
+	part := &Part{Kind: PartSelect, Pos: pos}
+	for {
+		expr, err := p.parseExpr(PartSelect)
+		if err != nil {
+			return nil, err
+		}
+		col := &Column{Expr: expr}
+		if p.acceptKeyword("as") {
+			tok, err := p.expect(tokIdent, "alias")
+			if err != nil {
+				return nil, err
+			}
+			col.Alias = tok.text
+		}
+		part.Columns = append(part.Columns, col)
+		if p.peek().kind != tokComma {
+			return part, nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseOr(kind PartKind) (*Cond, *Error) {
+	return p.parseLogical(kind, "or", p.parseAnd)
+}
+
+func (p *parser) parseAnd(kind PartKind) (*Cond, *Error) {
+	return p.parseLogical(kind, "and", p.parseNot)
+}
+
+func (p *parser) parseLogical(
+	kind PartKind, op string, operand func(PartKind) (*Cond, *Error),
+) (*Cond, *Error) {
+	pos := p.peek().pos
+	first, err := operand(kind)
+	if err != nil {
+		return nil, err
+	}
+	conds := []*Cond{first}
+	for p.acceptKeyword(op) {
+		cond, err := operand(kind)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) == 1 {
+		return first, nil
+	}
+	return &Cond{Pos: pos, Op: op, Conds: conds}, nil
+}
+
+func (p *parser) parseNot(kind PartKind) (*Cond, *Error) {
+	if p.isKeyword("not") {
+		tok := p.next()
+		cond, err := p.parseNot(kind)
+		if err != nil {
+			return nil, err
+		}
+		return &Cond{Pos: tok.pos, Op: "and", Not: true, Conds: []*Cond{cond}}, nil
+	}
+	return p.parseComparison(kind)
+}
+
+func (p *parser) parseComparison(kind PartKind) (*Cond, *Error) {
+	// A parenthesized group of conditions, as opposed to a parenthesized expression
+	// such as (a + b) > 1, is recognized by trying it first and backtracking.
+	if p.peek().kind == tokLParen {
+		save := p.save()
+		p.next()
+		if cond, err := p.parseOr(kind); err == nil && p.peek().kind == tokRParen {
+			p.next()
+			return cond, nil
+		}
+		p.restore(save)
+	}
+
+	left, err := p.parseExpr(kind)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case PartWhere:
+		if hasAggregate(left) {
+			return nil, &Error{left.Pos, "aggregate functions can't be used in where; use having instead"}
+		}
+	case PartHaving:
+		if !hasAggregate(left) && left.Kind != ExprAttr {
+			p.warnf(left.Pos, "having should compare an aggregate or a column alias")
+		}
+	}
+	cond := &Cond{Pos: left.Pos, Left: left}
+
+	if p.acceptKeyword("not") {
+		cond.Not = true
+	}
+
+	tok := p.peek()
+	switch {
+	case tok.kind == tokOp:
+		if cond.Not {
+			return nil, &Error{tok.pos, fmt.Sprintf(`unexpected "not" before %q`, tok.text)}
+		}
+		p.next()
+		cond.Op = tok.text
+		if cond.Op == "==" {
+			cond.Op = "="
+		}
+		if cond.Op == "<>" {
+			cond.Op = "!="
+		}
+		value, err := p.parseValue(kind)
+		if err != nil {
+			return nil, err
+		}
+		cond.Values = []*Expr{value}
+	case p.isKeyword("in"):
+		p.next()
+		cond.Op = "in"
+		if _, err := p.expect(tokLParen, `"(" after in`); err != nil {
+			return nil, err
+		}
+		for {
+			value, err := p.parseValue(kind)
+			if err != nil {
+				return nil, err
+			}
+			cond.Values = append(cond.Values, value)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+		if _, err := p.expect(tokRParen, `")" or ","`); err != nil {
+			return nil, err
+		}
+	case p.isKeyword("like"), p.isKeyword("contains"):
+		p.next()
+		cond.Op = strings.ToLower(tok.text)
+		value, err := p.parseValue(kind)
+		if err != nil {
+			return nil, err
+		}
+		cond.Values = []*Expr{value}
+	case p.isKeyword("exists"):
+		p.next()
+		cond.Op = "exists"
+	default:
+		return nil, &Error{tok.pos, fmt.Sprintf(
+			"expected a comparison (=, !=, <, >, ~, in, like, contains or exists), got %s", tok)}
+	}
+	return cond, nil
+}
+
+// parseValue parses the right side of a comparison. Unquoted words are accepted as
+// strings, e.g. where _status_code = error.
+func (p *parser) parseValue(kind PartKind) (*Expr, *Error) {
+	tok := p.peek()
+	if tok.kind == tokIdent && p.tokens[p.i+1].kind != tokLParen && !strings.HasPrefix(tok.text, "$") {
+		switch strings.ToLower(tok.text) {
+		case "and", "or", "not", "in", "like", "contains", "exists":
+			return nil, &Error{tok.pos, fmt.Sprintf("expected a value, got %s", tok)}
+		}
+		p.next()
+		return &Expr{Kind: ExprString, Pos: tok.pos, Name: tok.text}, nil
+	}
+	return p.parseExpr(kind)
+}
+
+func (p *parser) parseExpr(kind PartKind) (*Expr, *Error) {
+	return p.parseBinary(kind, "+-", func() (*Expr, *Error) {
+		return p.parseBinary(kind, "*/%", func() (*Expr, *Error) {
+			return p.parseOperand(kind)
+		})
+	})
+}
+
+func (p *parser) parseBinary(kind PartKind, ops string, operand func() (*Expr, *Error)) (*Expr, *Error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokArith && strings.Contains(ops, p.peek().text) {
+		tok := p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &Expr{Kind: ExprBinary, Pos: left.Pos, Name: tok.text, Args: []*Expr{left, right}}
+	}
+	return left, nil
+}
+
+func (p *parser) parseOperand(kind PartKind) (*Expr, *Error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		m := numberRE.FindStringSubmatch(tok.text)
+		if m == nil {
+			return nil, &Error{tok.pos, fmt.Sprintf("invalid number %q", tok.text)}
+		}
+		if !numberUnits[strings.ToLower(m[2])] {
+			return nil, &Error{tok.pos, fmt.Sprintf(
+				"unknown unit %q in %q (supported: ns, us, ms, s, m, h, d, b, kb, mb, gb, tb)", m[2], tok.text)}
+		}
+		return &Expr{Kind: ExprNumber, Pos: tok.pos, Name: tok.text}, nil
+	case tokString:
+		return &Expr{Kind: ExprString, Pos: tok.pos, Name: tok.text}, nil
+	case tokLParen:
+		expr, err := p.parseExpr(kind)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, `")"`); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	case tokIdent:
+		if p.peek().kind == tokLParen {
+			return p.parseFunc(kind, tok)
+		}
+		return p.parseAttr(tok)
+	case tokEOF, tokPipe:
+		return nil, &Error{tok.pos, fmt.Sprintf("unexpected %s, expected an attribute, function or value", tok)}
+	default:
+		return nil, &Error{tok.pos, fmt.Sprintf("unexpected %s", tok)}
+	}
+}
+
+func (p *parser) parseAttr(tok token) (*Expr, *Error) {
+	switch strings.ToLower(tok.text) {
+	case "where", "group", "by", "having", "and", "or", "not", "in", "like", "contains", "exists", "as":
+		return nil, &Error{tok.pos, fmt.Sprintf("unexpected keyword %q", tok.text)}
+	}
+
+	name, typ, hasType := strings.Cut(tok.text, "::")
+	if name == "" || strings.HasSuffix(name, ".") {
+		return nil, &Error{tok.pos, fmt.Sprintf("invalid attribute name %q", tok.text)}
+	}
+	if hasType && !attrTypes[typ] {
+		return nil, &Error{tok.pos + len(name) + 2, fmt.Sprintf(
+			"unknown type suffix %q (supported: str, int, float, bool and arrays such as str[])", typ)}
+	}
+
+	if strings.HasPrefix(name, "$") {
+		// $cpu or $cpu.host_name, an attribute of one metric.
+		alias, attrName, hasAttr := strings.Cut(name, ".")
+		if len(alias) == 1 {
+			return nil, &Error{tok.pos, "missing metric alias after \"$\""}
+		}
+		p.q.Metrics = append(p.q.Metrics, Attr{Name: alias, Pos: tok.pos})
+		if hasAttr {
+			p.q.Attrs = append(p.q.Attrs, Attr{Name: attrName, Type: typ, Pos: tok.pos + len(alias) + 1})
+		}
+	} else {
+		p.q.Attrs = append(p.q.Attrs, Attr{Name: name, Type: typ, Pos: tok.pos})
+	}
+	return &Expr{Kind: ExprAttr, Pos: tok.pos, Name: name, Type: typ}, nil
+}
+
+func (p *parser) parseFunc(kind PartKind, tok token) (*Expr, *Error) {
+	p.next() // (
+	expr := &Expr{Kind: ExprFunc, Pos: tok.pos, Name: tok.text}
+	if p.peek().kind != tokRParen {
+		for {
+			arg, err := p.parseExpr(kind)
+			if err != nil {
+				return nil, err
+			}
+			expr.Args = append(expr.Args, arg)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if _, err := p.expect(tokRParen, `")" or ","`); err != nil {
+		return nil, err
+	}
+
+	fn, ok := lookupFunc(expr.Name)
+	if !ok {
+		p.warnf(tok.pos, "unknown function %q", expr.Name)
+		return expr, nil
+	}
+	if len(expr.Args) < fn.minArgs || len(expr.Args) > fn.maxArgs {
+		return nil, &Error{tok.pos, fmt.Sprintf("%s() %s, got %d", expr.Name, fn.arity(), len(expr.Args))}
+	}
+	if fn.agg {
+		for _, arg := range expr.Args {
+			if arg.Kind == ExprFunc && isAggregate(arg.Name) && !fn.wrapsAgg {
+				return nil, &Error{arg.Pos, fmt.Sprintf("aggregate %s() can't be nested in %s()", arg.Name, expr.Name)}
+			}
+		}
+	}
+	return expr, nil
+}
+
+func hasAggregate(expr *Expr) bool {
+	if expr.Kind == ExprFunc && isAggregate(expr.Name) {
+		return true
+	}
+	for _, arg := range expr.Args {
+		if hasAggregate(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+func isAggregate(name string) bool {
+	fn, ok := lookupFunc(name)
+	return ok && fn.agg
+}